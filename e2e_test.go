@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -179,20 +180,26 @@ func TestE2E(t *testing.T) {
 			t.Fatalf("ch search --json failed: %v\n%s", err, output)
 		}
 
-		var results []map[string]interface{}
+		var results struct {
+			TotalMatched int                      `json:"total_matched"`
+			Results      []map[string]interface{} `json:"results"`
+		}
 		if err := json.Unmarshal([]byte(output), &results); err != nil {
 			t.Fatalf("Failed to parse JSON output: %v\n%s", err, output)
 		}
-		if len(results) == 0 {
+		if len(results.Results) == 0 {
 			t.Error("Expected at least one search result")
 		}
+		if results.TotalMatched != len(results.Results) {
+			t.Errorf("total_matched = %d, want %d", results.TotalMatched, len(results.Results))
+		}
 	})
 
 	// Test: ch search - no matches
 	t.Run("search_no_matches", func(t *testing.T) {
 		output, err := runCh("search", "nonexistent_term_xyz", "-g")
-		if err != nil {
-			t.Fatalf("ch search failed: %v\n%s", err, output)
+		if code := exitCode(err); code != 2 {
+			t.Fatalf("ch search exit code = %d, want 2 (no results)\n%s", code, output)
 		}
 		if !strings.Contains(output, "No matches found") {
 			t.Errorf("Expected 'No matches found' in output, got: %s", output)
@@ -294,8 +301,78 @@ func TestE2E(t *testing.T) {
 	// Test: show nonexistent conversation
 	t.Run("show_nonexistent", func(t *testing.T) {
 		_, err := runCh("show", "nonexistent123")
-		if err == nil {
-			t.Error("Expected error for nonexistent conversation")
+		if code := exitCode(err); code != 3 {
+			t.Errorf("exit code = %d, want 3 (not found)", code)
+		}
+	})
+
+	// Test: ch doctor --json against a fresh home
+	t.Run("doctor_json", func(t *testing.T) {
+		home := filepath.Join(tmpDir, "home")
+		if err := os.MkdirAll(home, 0755); err != nil {
+			t.Fatalf("Failed to create home dir: %v", err)
+		}
+		cmd := exec.Command(binaryPath, "doctor", "--json")
+		cmd.Env = append(os.Environ(), "HOME="+home, "CLAUDE_PROJECTS_DIR="+testProjectsDir, "CH_SYNC_DB=")
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("ch doctor --json failed: %v\n%s", err, output)
+		}
+
+		var checks []map[string]string
+		if err := json.Unmarshal(output, &checks); err != nil {
+			t.Fatalf("Failed to parse JSON output: %v\n%s", err, output)
+		}
+		statuses := make(map[string]string)
+		for _, c := range checks {
+			statuses[c["name"]] = c["status"]
+			if c["status"] == "fail" {
+				t.Errorf("Expected no failing checks, got: %v", c)
+			}
+		}
+		for _, name := range []string{"projects dir", "conversations", "sync db"} {
+			if statuses[name] != "ok" {
+				t.Errorf("Expected %s check to be ok, got %q", name, statuses[name])
+			}
+		}
+		if _, err := os.Stat(filepath.Join(home, ".ch", "sync.db")); !os.IsNotExist(err) {
+			t.Errorf("Expected doctor not to create the sync database (stat error = %v)", err)
+		}
+	})
+
+	// Test: --projects-dir overrides CLAUDE_PROJECTS_DIR
+	t.Run("projects_dir_flag", func(t *testing.T) {
+		archiveProject := filepath.Join(tmpDir, "archive", "-archived-project")
+		if err := os.MkdirAll(archiveProject, 0755); err != nil {
+			t.Fatalf("Failed to create archive project: %v", err)
+		}
+		archived := `{"type":"user","timestamp":"2024-02-01T10:00:00Z","sessionId":"feed0000-0000-0000-0000-000000000000","message":{"role":"user","content":"Archived question"}}
+`
+		if err := os.WriteFile(filepath.Join(archiveProject, "feed0000-0000-0000-0000-000000000000.jsonl"), []byte(archived), 0644); err != nil {
+			t.Fatalf("Failed to write archived conversation: %v", err)
+		}
+
+		output, err := runCh("list", "-g", "--projects-dir", filepath.Join(tmpDir, "archive"))
+		if err != nil {
+			t.Fatalf("ch list --projects-dir failed: %v\n%s", err, output)
+		}
+		if !strings.Contains(output, "feed0000") {
+			t.Errorf("Expected archived conversation in output, got: %s", output)
+		}
+		if strings.Contains(output, "abc12345") {
+			t.Errorf("Expected CLAUDE_PROJECTS_DIR to be overridden, got: %s", output)
 		}
 	})
 }
+
+// exitCode returns the process exit code for an error from exec.Cmd.Run.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dmora/ch/internal/config"
 	"github.com/dmora/ch/internal/sync"
 	"github.com/fatih/color"
 )
@@ -21,6 +22,21 @@ type ConsoleConfig struct {
 	NoColor bool
 }
 
+func init() {
+	Register("console", func(cfg config.SyncConfig) (sync.Backend, error) {
+		var w io.Writer = os.Stdout
+		if cfg.Console.Stderr {
+			w = os.Stderr
+		}
+		return NewConsoleBackend(ConsoleConfig{
+			Writer:  w,
+			Verbose: cfg.Console.Verbose,
+			Format:  cfg.Console.Format,
+			NoColor: color.NoColor,
+		}), nil
+	})
+}
+
 // DefaultConsoleConfig returns default console configuration.
 func DefaultConsoleConfig() ConsoleConfig {
 	return ConsoleConfig{
@@ -151,6 +167,11 @@ func (c *ConsoleBackend) sendText(span *sync.Span) error {
 	return nil
 }
 
+// PrefersBatch returns false so spans are printed as they are synced.
+func (c *ConsoleBackend) PrefersBatch() bool {
+	return false
+}
+
 // SendBatch outputs a batch of spans.
 func (c *ConsoleBackend) SendBatch(ctx context.Context, batch *sync.SpanBatch) error {
 	if c.config.Format == "json" {
@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dmora/ch/internal/config"
+	"github.com/dmora/ch/internal/sync"
+)
+
+// Factory creates a backend from the sync configuration.
+type Factory func(cfg config.SyncConfig) (sync.Backend, error)
+
+// factories holds the registered backends by name. It is only written from
+// init functions, so it needs no locking.
+var factories = make(map[string]Factory)
+
+// Register makes a backend available under name. It is meant to be called
+// from the init function of the file implementing the backend, and panics if
+// name is empty, factory is nil, or name is already registered.
+func Register(name string, factory Factory) {
+	if name == "" || factory == nil {
+		panic("backend: Register called with empty name or nil factory")
+	}
+	if _, dup := factories[name]; dup {
+		panic("backend: Register called twice for " + name)
+	}
+	factories[name] = factory
+}
+
+// Names returns the registered backend names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsRegistered reports whether a backend is registered under name.
+func IsRegistered(name string) bool {
+	_, ok := factories[name]
+	return ok
+}
+
+// New creates the backend registered under name.
+func New(name string, cfg config.SyncConfig) (sync.Backend, error) {
+	factory, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend: %s (registered: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(cfg)
+}
@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dmora/ch/internal/config"
+	"github.com/dmora/ch/internal/sync"
+)
+
+func TestRegistry(t *testing.T) {
+	if !slices.Contains(Names(), "console") {
+		t.Fatalf("Names() = %v, want console registered", Names())
+	}
+	if !IsRegistered("console") || IsRegistered("nope") {
+		t.Error("IsRegistered() mismatch")
+	}
+
+	be, err := New("console", config.SyncConfig{})
+	if err != nil {
+		t.Fatalf("New(console) error = %v", err)
+	}
+	if be.Name() != "console" {
+		t.Errorf("Name() = %s, want console", be.Name())
+	}
+
+	_, err = New("nope", config.SyncConfig{})
+	if err == nil || !strings.Contains(err.Error(), "nope") || !strings.Contains(err.Error(), "console") {
+		t.Errorf("New(nope) error = %v, want unknown backend listing console", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	t.Cleanup(func() { delete(factories, "test") })
+
+	Register("test", func(cfg config.SyncConfig) (sync.Backend, error) {
+		return NewConsoleBackend(ConsoleConfig{Format: cfg.Console.Format}), nil
+	})
+	be, err := New("test", config.SyncConfig{})
+	if err != nil || be == nil {
+		t.Fatalf("New(test) = %v, %v", be, err)
+	}
+	if !slices.Contains(Names(), "test") {
+		t.Errorf("Names() = %v, want test registered", Names())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() should panic on a duplicate name")
+		}
+	}()
+	Register("test", func(config.SyncConfig) (sync.Backend, error) { return nil, nil })
+}
+
+func TestConsoleStderr(t *testing.T) {
+	for _, stderr := range []bool{false, true} {
+		be, err := New("console", config.SyncConfig{Console: config.ConsoleConfig{Stderr: stderr}})
+		if err != nil {
+			t.Fatalf("New(console) error = %v", err)
+		}
+		want := os.Stdout
+		if stderr {
+			want = os.Stderr
+		}
+		if got := be.(*ConsoleBackend).config.Writer; got != want {
+			t.Errorf("Stderr=%v: writer = %v, want %v", stderr, got, want)
+		}
+	}
+}
@@ -15,7 +15,9 @@ var agentsCmd = &cobra.Command{
 	Short: "List agents spawned by a conversation",
 	Long: `List all agent/subagent conversations spawned by a main conversation.
 
-The id should be a main conversation ID (not an agent ID).`,
+The id should be a main conversation ID (not an agent ID).
+Use --tree to show which agent spawned which, or --merged to read every
+agent's messages as one transcript ordered by time.`,
 	Args:    cobra.ExactArgs(1),
 	Aliases: []string{"agent", "ag"},
 	RunE:    runAgents,
@@ -24,16 +26,27 @@ The id should be a main conversation ID (not an agent ID).`,
 var (
 	agentsJSON   bool
 	agentsFilter string
+	agentsTree   bool
+	agentsMerged bool
 )
 
 func init() {
 	agentsCmd.Flags().BoolVar(&agentsJSON, "json", false, "Output as JSON")
 	agentsCmd.Flags().StringVarP(&agentsFilter, "filter", "f", "", "Filter by agent type (exact match)")
+	agentsCmd.Flags().BoolVar(&agentsTree, "tree", false, "Show the agent hierarchy as a tree")
+	agentsCmd.Flags().BoolVar(&agentsMerged, "merged", false, "Show all agents' messages as one transcript ordered by time")
 }
 
 func runAgents(cmd *cobra.Command, args []string) error {
 	id := args[0]
 
+	if agentsTree && agentsFilter != "" {
+		return fmt.Errorf("--tree and --filter cannot be used together")
+	}
+	if agentsTree && agentsMerged {
+		return fmt.Errorf("--tree and --merged cannot be used together")
+	}
+
 	// Find the conversation file
 	path, err := findConversationFile(id)
 	if err != nil {
@@ -59,8 +72,17 @@ func runAgents(cmd *cobra.Command, args []string) error {
 	projectDir := filepath.Dir(path)
 	scanner := history.NewScanner(history.ScannerOptions{
 		ProjectsDir: cfg.ProjectsDir,
+		Workers:     cfg.Workers,
 	})
 
+	if agentsTree {
+		roots, err := scanner.BuildAgentTree(projectDir, sessionID)
+		if err != nil {
+			return fmt.Errorf("building agent tree: %w", err)
+		}
+		return display.RenderAgentTree(os.Stdout, roots, sessionID, agentsJSON, jsonCompact, jsonTime)
+	}
+
 	var agents []*history.ConversationMeta
 	if agentsFilter != "" {
 		// Filter by agent type
@@ -75,6 +97,30 @@ func runAgents(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if agentsMerged {
+		return renderMergedAgents(agents, sessionID)
+	}
+
 	// Render with filter context
-	return display.RenderAgentList(os.Stdout, agents, sessionID, agentsJSON, agentsFilter)
+	return display.RenderAgentList(os.Stdout, agents, sessionID, agentsJSON, jsonCompact, jsonTime, agentsFilter)
+}
+
+// renderMergedAgents loads each agent and renders their entries interleaved
+// by timestamp.
+func renderMergedAgents(agents []*history.ConversationMeta, sessionID string) error {
+	convs := make([]*history.Conversation, 0, len(agents))
+	for _, a := range agents {
+		conv, err := history.LoadConversation(a.Path)
+		if err != nil {
+			return fmt.Errorf("loading agent %s: %w", a.ID, err)
+		}
+		convs = append(convs, conv)
+	}
+
+	opts := display.DefaultConversationDisplayOptions()
+	opts.JSON = agentsJSON
+	opts.Compact = jsonCompact
+	opts.TimeFormat = timeFmt
+	opts.TimeLayout = jsonTime
+	return display.NewConversationDisplay(opts).RenderMerged(sessionID, history.MergeConversations(convs))
 }
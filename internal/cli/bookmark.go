@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/syncdb"
+)
+
+// updatesBookmark reports whether this show moves the conversation's read
+// bookmark: only formatted views on a terminal do, so scripts reading
+// --json, --raw, --metadata, or piped output leave it alone.
+func updatesBookmark() bool {
+	return !showJSON && !showRaw && !showMetadata && showOutput == "" && display.IsTTY()
+}
+
+// openBookmarkDB opens the sync database that stores bookmarks. Unless
+// create is set, a database that doesn't exist yet is left alone rather than
+// created, so a plain show never leaves a sync.db behind; only --new, which
+// asks for bookmarks, creates it.
+func openBookmarkDB(create bool) (*syncdb.DB, error) {
+	if !create {
+		if _, err := os.Stat(cfg.Sync.DBPath); err != nil {
+			return nil, err
+		}
+	}
+	return syncdb.Open(cfg.Sync.DBPath)
+}
+
+// loadBookmark returns a conversation's bookmark, or nil if it has none or
+// the database can't be read.
+func loadBookmark(id string) *syncdb.Bookmark {
+	if id == "" {
+		return nil
+	}
+	db, err := openBookmarkDB(showNew)
+	if err != nil {
+		return nil
+	}
+	defer db.Close()
+
+	bookmark, err := db.GetBookmark(id)
+	if err != nil {
+		return nil
+	}
+	return bookmark
+}
+
+// bookmarkPagination starts p after the conversation's bookmark, keeping
+// its --limit. The bookmarked UUID is preferred; if the message is gone (e.g.
+// after compaction) its index is used. Without a bookmark, p is unchanged
+// and the whole conversation is new.
+func bookmarkPagination(conv *history.Conversation, p display.PaginationOptions) display.PaginationOptions {
+	bookmark := loadBookmark(conv.Meta.ID)
+	if bookmark == nil {
+		return p
+	}
+	if bookmark.MessageUUID != "" && history.FindEntryByUUID(conv.Entries, bookmark.MessageUUID) >= 0 {
+		p.AfterUUID = bookmark.MessageUUID
+	} else {
+		p.AfterIndex = bookmark.MessageIndex
+	}
+	return p
+}
+
+// saveBookmark records the last message shown as read. The bookmark only
+// moves forward: viewing an earlier page keeps a later bookmark, unless that
+// bookmark's message no longer exists. Failures are ignored, since the
+// bookmark is a convenience and must never fail a show.
+func saveBookmark(conv *history.Conversation, disp *display.ConversationDisplay) {
+	last, index := disp.LastShown()
+	if last == nil || conv.Meta.ID == "" {
+		return
+	}
+
+	db, err := openBookmarkDB(showNew)
+	if err != nil {
+		return
+	}
+	defer db.Close()
+
+	if old, err := db.GetBookmark(conv.Meta.ID); err == nil && old != nil && old.MessageIndex >= index &&
+		(old.MessageUUID == "" || history.FindEntryByUUID(conv.Entries, old.MessageUUID) >= 0) {
+		return
+	}
+	db.SetBookmark(conv.Meta.ID, last.UUID, index)
+}
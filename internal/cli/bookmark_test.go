@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dmora/ch/internal/config"
+	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/syncdb"
+)
+
+const bookmarkConversation = `{"type":"user","uuid":"u1","sessionId":"conv-1","message":{"role":"user","content":"first"}}
+{"type":"assistant","uuid":"a1","sessionId":"conv-1","message":{"role":"assistant","content":[{"type":"text","text":"second"}]}}
+{"type":"user","uuid":"u2","sessionId":"conv-1","message":{"role":"user","content":"third"}}
+`
+
+// useBookmarkDB points the sync database at a temp path and resets --new.
+func useBookmarkDB(t *testing.T) string {
+	t.Helper()
+	oldCfg, oldNew := cfg, showNew
+	t.Cleanup(func() { cfg, showNew = oldCfg, oldNew })
+
+	dbPath := filepath.Join(t.TempDir(), "sync.db")
+	cfg = &config.Config{Sync: config.SyncConfig{DBPath: dbPath}}
+	showNew = false
+	return dbPath
+}
+
+// renderForBookmark renders conv with p and returns the display, whose
+// LastShown saveBookmark records.
+func renderForBookmark(t *testing.T, conv *history.Conversation, p display.PaginationOptions) *display.ConversationDisplay {
+	t.Helper()
+	opts := display.DefaultConversationDisplayOptions()
+	opts.Writer = io.Discard
+	opts.Pagination = p
+	disp := display.NewConversationDisplay(opts)
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	return disp
+}
+
+func loadBookmarkConversation(t *testing.T) *history.Conversation {
+	t.Helper()
+	conv, err := history.LoadConversationFromReader(strings.NewReader(bookmarkConversation), "conv-1")
+	if err != nil {
+		t.Fatalf("LoadConversationFromReader() error = %v", err)
+	}
+	return conv
+}
+
+func TestSaveBookmark_NoDatabase(t *testing.T) {
+	dbPath := useBookmarkDB(t)
+	conv := loadBookmarkConversation(t)
+
+	saveBookmark(conv, renderForBookmark(t, conv, display.PaginationOptions{}))
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("saveBookmark created the database without --new (stat error = %v)", err)
+	}
+
+	showNew = true
+	saveBookmark(conv, renderForBookmark(t, conv, display.PaginationOptions{}))
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Errorf("saveBookmark with --new did not create the database: %v", err)
+	}
+}
+
+func TestSaveBookmark_MovesForward(t *testing.T) {
+	useBookmarkDB(t)
+	showNew = true
+	conv := loadBookmarkConversation(t)
+
+	saveBookmark(conv, renderForBookmark(t, conv, display.PaginationOptions{}))
+	if b := loadBookmark(conv.Meta.ID); b == nil || b.MessageUUID != "u2" || b.MessageIndex != 3 {
+		t.Fatalf("bookmark after full view = %+v, want u2 at 3", b)
+	}
+
+	// Viewing an earlier page keeps the later bookmark
+	saveBookmark(conv, renderForBookmark(t, conv, display.PaginationOptions{First: 1}))
+	if b := loadBookmark(conv.Meta.ID); b == nil || b.MessageUUID != "u2" {
+		t.Errorf("bookmark after earlier page = %+v, want u2 kept", b)
+	}
+}
+
+func TestBookmarkPagination(t *testing.T) {
+	dbPath := useBookmarkDB(t)
+	conv := loadBookmarkConversation(t)
+
+	// Without a database, the whole conversation is new and none is created
+	if p := bookmarkPagination(conv, display.PaginationOptions{Limit: 5}); p.AfterUUID != "" || p.AfterIndex != 0 || p.Limit != 5 {
+		t.Errorf("bookmarkPagination() without a bookmark = %+v, want unchanged", p)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("bookmarkPagination created the database without --new (stat error = %v)", err)
+	}
+
+	db, err := syncdb.Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	if err := db.SetBookmark(conv.Meta.ID, "a1", 2); err != nil {
+		t.Fatalf("SetBookmark() error = %v", err)
+	}
+	if p := bookmarkPagination(conv, display.PaginationOptions{Limit: 5}); p.AfterUUID != "a1" || p.Limit != 5 {
+		t.Errorf("bookmarkPagination() = %+v, want after a1 keeping the limit", p)
+	}
+
+	// A bookmarked message that is gone falls back to its index
+	if err := db.SetBookmark(conv.Meta.ID, "compacted", 2); err != nil {
+		t.Fatalf("SetBookmark() error = %v", err)
+	}
+	if p := bookmarkPagination(conv, display.PaginationOptions{}); p.AfterUUID != "" || p.AfterIndex != 2 {
+		t.Errorf("bookmarkPagination() = %+v, want after index 2", p)
+	}
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/history"
+)
+
+// showCountJSON is the --count --json form of one conversation's counts.
+type showCountJSON struct {
+	ID           string `json:"id"`
+	IsAgent      bool   `json:"is_agent"`
+	Path         string `json:"path"`
+	FileSize     int64  `json:"file_size"`
+	Entries      int    `json:"entries"`
+	Messages     int    `json:"messages"`
+	User         int    `json:"user"`
+	Assistant    int    `json:"assistant"`
+	System       int    `json:"system"`
+	Summary      int    `json:"summary"`
+	ToolCalls    int    `json:"tool_calls"`
+	ToolResults  int    `json:"tool_results"`
+	Other        int    `json:"other"`
+	SkippedLines int    `json:"skipped_lines,omitempty"`
+}
+
+// runShowCount prints entry counts for each conversation instead of
+// rendering it. Files are streamed, never loaded whole, so it is cheap
+// to run on a conversation before deciding how to page through it.
+func runShowCount(args []string) (err error) {
+	paths, err := showCountPaths(args)
+	if err != nil {
+		return err
+	}
+
+	results := make([]showCountJSON, 0, len(paths))
+	for _, path := range paths {
+		counts, err := history.CountEntries(path)
+		if err != nil {
+			return fmt.Errorf("counting entries: %w", err)
+		}
+		results = append(results, newShowCountJSON(path, counts))
+	}
+
+	out, closeOutput, err := openOutput(showOutput)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := closeOutput(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if showJSON {
+		encoder := display.NewJSONEncoder(out, jsonCompact)
+		if len(results) == 1 {
+			return encoder.Encode(results[0])
+		}
+		return encoder.Encode(results)
+	}
+
+	for i, r := range results {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		printShowCount(out, r)
+	}
+	return nil
+}
+
+// showCountPaths resolves the conversation files named by args or --file.
+func showCountPaths(args []string) ([]string, error) {
+	if showFile != "" {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("cannot use --file with a conversation id")
+		}
+		if _, err := os.Stat(showFile); err != nil {
+			return nil, errNotFound("conversation file not found: %s", showFile)
+		}
+		return []string{showFile}, nil
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("requires a conversation id or --file")
+	}
+
+	paths := make([]string, 0, len(args))
+	for _, id := range args {
+		if id == "-" {
+			return nil, fmt.Errorf("--count cannot read stdin; use an id or --file")
+		}
+		path, err := findConversationFile(id)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// newShowCountJSON builds the output for one conversation file.
+func newShowCountJSON(path string, c *history.EntryCounts) showCountJSON {
+	name := filepath.Base(path)
+	isAgent := history.IsAgentFile(name)
+	id := history.ExtractSessionID(name)
+	if isAgent {
+		id = history.ExtractAgentID(name)
+	}
+
+	return showCountJSON{
+		ID:           id,
+		IsAgent:      isAgent,
+		Path:         path,
+		FileSize:     c.FileSize,
+		Entries:      c.Entries,
+		Messages:     c.Messages(),
+		User:         c.User,
+		Assistant:    c.Assistant,
+		System:       c.System,
+		Summary:      c.Summary,
+		ToolCalls:    c.ToolCalls,
+		ToolResults:  c.ToolResults,
+		Other:        c.Other,
+		SkippedLines: c.SkippedLines,
+	}
+}
+
+// printShowCount prints one conversation's counts as text.
+func printShowCount(w io.Writer, r showCountJSON) {
+	id := r.ID
+	if r.IsAgent {
+		id = "agent-" + id
+	}
+	fmt.Fprintf(w, "%s %s\n", display.Title("Counts"), display.ID(id))
+	fmt.Fprintf(w, "  Size:         %s\n", display.FormatBytes(r.FileSize))
+	fmt.Fprintf(w, "  Entries:      %d\n", r.Entries)
+	fmt.Fprintf(w, "  Messages:     %d %s\n", r.Messages,
+		display.Dim(fmt.Sprintf("(user %d, assistant %d, system %d)", r.User, r.Assistant, r.System)))
+	fmt.Fprintf(w, "  Summaries:    %d\n", r.Summary)
+	fmt.Fprintf(w, "  Tool calls:   %d\n", r.ToolCalls)
+	fmt.Fprintf(w, "  Tool results: %d\n", r.ToolResults)
+	fmt.Fprintf(w, "  Other:        %d\n", r.Other)
+	if r.SkippedLines > 0 {
+		fmt.Fprintf(w, "  Skipped:      %d malformed lines\n", r.SkippedLines)
+	}
+}
@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Find conversations stored in more than one file",
+	Long: `Find conversations whose session is stored in more than one JSONL file,
+e.g. after restoring a backup into a different project directory.
+
+By default duplicates are only reported. With --delete, every copy except the
+largest (newest on ties) is removed after confirmation.
+
+Examples:
+  ch dedupe                  # Report duplicates and reclaimable space
+  ch dedupe --json           # Report as JSON
+  ch dedupe --delete         # Remove duplicates after confirming
+  ch dedupe --delete --yes   # Remove duplicates without prompting`,
+	Args: cobra.NoArgs,
+	RunE: runDedupe,
+}
+
+var (
+	dedupeDelete bool
+	dedupeYes    bool
+	dedupeJSON   bool
+)
+
+func init() {
+	dedupeCmd.Flags().BoolVar(&dedupeDelete, "delete", false, "Remove all but the largest copy of each duplicated conversation")
+	dedupeCmd.Flags().BoolVarP(&dedupeYes, "yes", "y", false, "Don't prompt for confirmation with --delete")
+	dedupeCmd.Flags().BoolVar(&dedupeJSON, "json", false, "Output as JSON")
+}
+
+func runDedupe(cmd *cobra.Command, args []string) error {
+	if dedupeDelete && dedupeJSON {
+		return fmt.Errorf("--delete and --json are mutually exclusive")
+	}
+	if dedupeDelete && !dedupeYes && !tui.IsInteractive() {
+		return fmt.Errorf("--delete requires --yes when not run in an interactive terminal")
+	}
+
+	scanner := history.NewScanner(history.ScannerOptions{
+		ProjectsDir:   cfg.ProjectsDir,
+		IncludeAgents: true,
+		Workers:       cfg.Workers,
+	})
+	metas, err := scanner.ScanAll(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("scanning conversations: %w", err)
+	}
+
+	groups := history.FindDuplicates(metas)
+	if dedupeJSON {
+		return printJSON(newDedupeReport(groups))
+	}
+
+	printDuplicates(groups)
+	if len(groups) == 0 {
+		return errEmpty()
+	}
+	if !dedupeDelete {
+		return nil
+	}
+
+	return removeDuplicates(os.Stdin, os.Stdout, groups, dedupeYes)
+}
+
+// removeDuplicates deletes the duplicate copies once the user confirms on in,
+// or right away with yes.
+func removeDuplicates(in io.Reader, out io.Writer, groups []*history.DuplicateGroup, yes bool) error {
+	if !yes && !confirm(in, fmt.Sprintf("Delete %d duplicate files?", countDuplicates(groups))) {
+		fmt.Fprintln(out, display.Dim("Aborted; nothing deleted."))
+		return nil
+	}
+	return deleteDuplicates(out, groups)
+}
+
+// printDuplicates lists each duplicated conversation with the copy to keep
+// and the copies that would be removed.
+func printDuplicates(groups []*history.DuplicateGroup) {
+	if len(groups) == 0 {
+		fmt.Println(display.Dim("No duplicate conversations found"))
+		return
+	}
+
+	var reclaimable int64
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s  %s\n", display.ID(g.Key), display.Dim(fmt.Sprintf("(%d copies)", len(g.Duplicates)+1)))
+		fmt.Printf("  %s  %8s  %s\n", display.Success("keep"), display.FormatBytes(g.Keep.FileSize), g.Keep.Path)
+		for _, m := range g.Duplicates {
+			fmt.Printf("  %s  %8s  %s\n", display.Warning("dupe"), display.FormatBytes(m.FileSize), m.Path)
+		}
+		reclaimable += g.ReclaimableBytes()
+	}
+
+	fmt.Printf("\n%s\n", display.Dim(fmt.Sprintf("%d duplicate files in %d conversations, %s reclaimable",
+		countDuplicates(groups), len(groups), display.FormatBytes(reclaimable))))
+}
+
+// countDuplicates returns the number of files that --delete would remove.
+func countDuplicates(groups []*history.DuplicateGroup) int {
+	n := 0
+	for _, g := range groups {
+		n += len(g.Duplicates)
+	}
+	return n
+}
+
+// confirm asks a yes/no question, reading the answer from in and defaulting
+// to no.
+func confirm(in io.Reader, question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// deleteDuplicates removes the duplicate copies and reports the space freed
+// to out. Only conversation files inside the projects directory are ever
+// removed.
+func deleteDuplicates(out io.Writer, groups []*history.DuplicateGroup) error {
+	deleted, failed := 0, 0
+	var freed int64
+	for _, g := range groups {
+		for _, m := range g.Duplicates {
+			if err := checkDeletable(m.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "%s skipping %s: %v\n", display.Warning("Warning:"), m.Path, err)
+				failed++
+				continue
+			}
+			if err := os.Remove(m.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "%s %v\n", display.Warning("Warning:"), err)
+				failed++
+				continue
+			}
+			deleted++
+			freed += m.FileSize
+		}
+	}
+
+	fmt.Fprintf(out, "Deleted %s files, freed %s\n", display.Number(fmt.Sprintf("%d", deleted)), display.FormatBytes(freed))
+	if failed > 0 {
+		return fmt.Errorf("%d files could not be deleted", failed)
+	}
+	return nil
+}
+
+// checkDeletable guards against removing anything but a conversation file
+// within the configured projects directory.
+func checkDeletable(path string) error {
+	if !history.IsConversationFile(filepath.Base(path)) {
+		return fmt.Errorf("not a conversation file")
+	}
+	rel, err := filepath.Rel(cfg.ProjectsDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("outside %s", cfg.ProjectsDir)
+	}
+	return nil
+}
+
+// dedupeGroup is the JSON form of a duplicate group.
+type dedupeGroup struct {
+	Key              string       `json:"key"`
+	Keep             dedupeFile   `json:"keep"`
+	Duplicates       []dedupeFile `json:"duplicates"`
+	ReclaimableBytes int64        `json:"reclaimable_bytes"`
+}
+
+// dedupeFile is one copy of a duplicated conversation.
+type dedupeFile struct {
+	Path    string `json:"path"`
+	Project string `json:"project"`
+	Size    int64  `json:"size"`
+}
+
+// dedupeReport is the JSON output of dedupe.
+type dedupeReport struct {
+	Groups           []dedupeGroup `json:"groups"`
+	DuplicateFiles   int           `json:"duplicate_files"`
+	ReclaimableBytes int64         `json:"reclaimable_bytes"`
+}
+
+// newDedupeReport builds the JSON report for the duplicate groups.
+func newDedupeReport(groups []*history.DuplicateGroup) *dedupeReport {
+	toFile := func(m *history.ConversationMeta) dedupeFile {
+		return dedupeFile{Path: m.Path, Project: m.ProjectPath, Size: m.FileSize}
+	}
+
+	report := &dedupeReport{Groups: []dedupeGroup{}, DuplicateFiles: countDuplicates(groups)}
+	for _, g := range groups {
+		group := dedupeGroup{Key: g.Key, Keep: toFile(g.Keep), ReclaimableBytes: g.ReclaimableBytes()}
+		for _, m := range g.Duplicates {
+			group.Duplicates = append(group.Duplicates, toFile(m))
+		}
+		report.Groups = append(report.Groups, group)
+		report.ReclaimableBytes += group.ReclaimableBytes
+	}
+	return report
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dmora/ch/internal/config"
+	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/history"
+)
+
+// dedupeFixture writes a conversation and two copies of it under a temp
+// projects directory, which cfg points at, and returns the duplicate group.
+func dedupeFixture(t *testing.T) *history.DuplicateGroup {
+	t.Helper()
+	oldCfg := cfg
+	t.Cleanup(func() { cfg = oldCfg })
+	display.SetColorEnabled(false)
+	t.Cleanup(func() { display.SetColorEnabled(true) })
+
+	projectsDir := t.TempDir()
+	cfg = &config.Config{ProjectsDir: projectsDir}
+
+	write := func(project string, size int) *history.ConversationMeta {
+		dir := filepath.Join(projectsDir, project)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "conv-1.jsonl")
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return &history.ConversationMeta{ID: "conv-1", Path: path, FileSize: int64(size)}
+	}
+	return &history.DuplicateGroup{
+		Key:        "conv-1",
+		Keep:       write("-keep", 500),
+		Duplicates: []*history.ConversationMeta{write("-copy-a", 300), write("-copy-b", 200)},
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestDeleteDuplicates(t *testing.T) {
+	g := dedupeFixture(t)
+
+	var out strings.Builder
+	if err := deleteDuplicates(&out, []*history.DuplicateGroup{g}); err != nil {
+		t.Fatalf("deleteDuplicates() error = %v", err)
+	}
+	if !exists(g.Keep.Path) {
+		t.Errorf("kept copy %s was removed", g.Keep.Path)
+	}
+	for _, m := range g.Duplicates {
+		if exists(m.Path) {
+			t.Errorf("duplicate %s was not removed", m.Path)
+		}
+	}
+	if want := "Deleted 2 files, freed 500 B"; !strings.Contains(out.String(), want) {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestDeleteDuplicates_Refused(t *testing.T) {
+	g := dedupeFixture(t)
+
+	// A copy outside the projects directory and a file that isn't a
+	// conversation are both left alone
+	outside := filepath.Join(t.TempDir(), "conv-1.jsonl")
+	if err := os.WriteFile(outside, []byte("xx"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	notConversation := filepath.Join(cfg.ProjectsDir, "-copy-a", "notes.txt")
+	if err := os.WriteFile(notConversation, []byte("xxx"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g.Duplicates = append(g.Duplicates,
+		&history.ConversationMeta{Path: outside, FileSize: 2},
+		&history.ConversationMeta{Path: notConversation, FileSize: 3},
+	)
+
+	var out strings.Builder
+	err := deleteDuplicates(&out, []*history.DuplicateGroup{g})
+	if err == nil || !strings.Contains(err.Error(), "2 files could not be deleted") {
+		t.Errorf("deleteDuplicates() error = %v, want 2 files not deleted", err)
+	}
+	if !exists(outside) || !exists(notConversation) {
+		t.Error("refused files were removed")
+	}
+	// Only the files actually removed count as freed
+	if want := "Deleted 2 files, freed 500 B"; !strings.Contains(out.String(), want) {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestCheckDeletable(t *testing.T) {
+	oldCfg := cfg
+	t.Cleanup(func() { cfg = oldCfg })
+	cfg = &config.Config{ProjectsDir: "/home/u/.claude/projects"}
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"/home/u/.claude/projects/-p/conv.jsonl", true},
+		{"/home/u/.claude/projects/-p/conv.jsonl.gz", true},
+		{"/home/u/.claude/projects/-p/notes.txt", false},
+		{"/home/u/.claude/other/conv.jsonl", false},
+		{"/home/u/.claude/projects/../conv.jsonl", false},
+		{"/home/u/.claude/projects-backup/-p/conv.jsonl", false},
+	}
+	for _, tt := range tests {
+		if err := checkDeletable(tt.path); (err == nil) != tt.ok {
+			t.Errorf("checkDeletable(%q) error = %v, want ok %v", tt.path, err, tt.ok)
+		}
+	}
+}
+
+func TestRemoveDuplicates_Confirm(t *testing.T) {
+	for _, answer := range []string{"", "n\n", "no\n", "maybe\n"} {
+		g := dedupeFixture(t)
+
+		var out strings.Builder
+		if err := removeDuplicates(strings.NewReader(answer), &out, []*history.DuplicateGroup{g}, false); err != nil {
+			t.Fatalf("removeDuplicates(%q) error = %v", answer, err)
+		}
+		for _, m := range g.Duplicates {
+			if !exists(m.Path) {
+				t.Errorf("answer %q: %s was removed", answer, m.Path)
+			}
+		}
+		if !strings.Contains(out.String(), "Aborted; nothing deleted.") {
+			t.Errorf("answer %q: output = %q, want aborted", answer, out.String())
+		}
+	}
+
+	g := dedupeFixture(t)
+	var out strings.Builder
+	if err := removeDuplicates(strings.NewReader("Y\n"), &out, []*history.DuplicateGroup{g}, false); err != nil {
+		t.Fatalf("removeDuplicates(Y) error = %v", err)
+	}
+	if exists(g.Duplicates[0].Path) || !exists(g.Keep.Path) {
+		t.Errorf("confirmed delete: output = %q", out.String())
+	}
+
+	// --yes skips the prompt
+	g = dedupeFixture(t)
+	if err := removeDuplicates(strings.NewReader(""), &out, []*history.DuplicateGroup{g}, true); err != nil {
+		t.Fatalf("removeDuplicates(yes) error = %v", err)
+	}
+	if exists(g.Duplicates[0].Path) {
+		t.Error("--yes did not delete the duplicates")
+	}
+}
@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/dmora/ch/internal/config"
+	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/syncdb"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose setup problems",
+	Long: `Check that ch can find and read Claude Code history.
+
+Checks the projects directory, the conversations found in it, the claude
+binary used by resume, the sync database, and the config file. Each check
+prints OK, WARN, or FAIL with a hint on how to fix it. Exits 1 if any check
+fails.
+
+Examples:
+  ch doctor                  # Print a checklist
+  ch doctor --json           # Machine-readable report for bug reports`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+var doctorJSON bool
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Output as JSON")
+}
+
+// Doctor check statuses.
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorCheck is the outcome of a single diagnostic check.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	checks := runDoctorChecks(config.ConfigPath(), cfg)
+	if err := renderDoctor(os.Stdout, checks, doctorJSON); err != nil {
+		return err
+	}
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			return &ExitError{Code: ExitCodeError}
+		}
+	}
+	return nil
+}
+
+// runDoctorChecks runs every check against the config file at configPath and
+// the loaded settings in c.
+func runDoctorChecks(configPath string, c *config.Config) []doctorCheck {
+	return []doctorCheck{
+		checkConfigFile(configPath),
+		checkProjectsDir(c.ProjectsDir),
+		checkConversations(c.ProjectsDir),
+		checkClaudeBin(c.ClaudeBin),
+		checkSyncDB(c.Sync.DBPath),
+	}
+}
+
+// checkConfigFile verifies the config file, if present, parses.
+func checkConfigFile(path string) doctorCheck {
+	c := doctorCheck{Name: "config"}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		c.Status = doctorOK
+		c.Detail = fmt.Sprintf("no config file at %s, using defaults", path)
+		return c
+	}
+	if _, err := config.LoadFromFile(path); err != nil {
+		c.Status = doctorFail
+		c.Detail = err.Error()
+		c.Hint = fmt.Sprintf("fix or remove %s; ch silently falls back to defaults while it is invalid", path)
+		return c
+	}
+	c.Status = doctorOK
+	c.Detail = path
+	return c
+}
+
+// checkProjectsDir verifies the projects directory exists and is readable.
+func checkProjectsDir(dir string) doctorCheck {
+	c := doctorCheck{Name: "projects dir"}
+
+	info, err := os.Stat(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		c.Status = doctorFail
+		c.Detail = fmt.Sprintf("%s does not exist", dir)
+		c.Hint = "run Claude Code at least once, or set CLAUDE_PROJECTS_DIR to your history location"
+		return c
+	case err != nil:
+		c.Status = doctorFail
+		c.Detail = err.Error()
+		return c
+	case !info.IsDir():
+		c.Status = doctorFail
+		c.Detail = fmt.Sprintf("%s is not a directory", dir)
+		c.Hint = "set CLAUDE_PROJECTS_DIR or projects_dir in the config to your history directory"
+		return c
+	}
+
+	if _, err := os.ReadDir(dir); err != nil {
+		c.Status = doctorFail
+		c.Detail = err.Error()
+		c.Hint = fmt.Sprintf("check the permissions of %s", dir)
+		return c
+	}
+	c.Status = doctorOK
+	c.Detail = dir
+	return c
+}
+
+// checkConversations counts the projects and conversations found.
+func checkConversations(dir string) doctorCheck {
+	c := doctorCheck{Name: "conversations"}
+
+	projects, err := history.ListProjects(dir)
+	if err != nil {
+		c.Status = doctorFail
+		c.Detail = err.Error()
+		return c
+	}
+
+	conversations, agents := 0, 0
+	for _, p := range projects {
+		conversations += p.ConversationCount
+		agents += p.AgentCount
+	}
+	c.Detail = fmt.Sprintf("%d projects, %d conversations, %d agents", len(projects), conversations, agents)
+	if conversations == 0 {
+		c.Status = doctorWarn
+		c.Hint = "no conversations yet; start a Claude Code session, or check CLAUDE_PROJECTS_DIR"
+		return c
+	}
+	c.Status = doctorOK
+	return c
+}
+
+// checkClaudeBin verifies the claude binary used by resume is on PATH.
+func checkClaudeBin(bin string) doctorCheck {
+	c := doctorCheck{Name: "claude binary"}
+
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		c.Status = doctorWarn
+		c.Detail = fmt.Sprintf("%s not found on PATH", bin)
+		c.Hint = "install Claude Code, or set CLAUDE_BIN; only needed for ch resume"
+		return c
+	}
+	c.Status = doctorOK
+	c.Detail = path
+	return c
+}
+
+// checkSyncDB verifies the sync database can be opened and written, or, if
+// it doesn't exist yet, that its directory is writable. It never creates or
+// modifies the database.
+func checkSyncDB(path string) doctorCheck {
+	c := doctorCheck{Name: "sync db"}
+
+	if _, err := os.Stat(path); err == nil {
+		db, err := syncdb.Open(path)
+		if err != nil {
+			c.Status = doctorFail
+			c.Detail = err.Error()
+			c.Hint = fmt.Sprintf("check the permissions of %s, or set CH_SYNC_DB", path)
+			return c
+		}
+		db.Close()
+
+		// SQLite opens a read-only file fine and only fails on the first
+		// write, and it needs the directory for its journal
+		if err := checkWritable(path); err != nil {
+			c.Status = doctorFail
+			c.Detail = fmt.Sprintf("%s is not writable: %v", path, err)
+			c.Hint = fmt.Sprintf("check the permissions of %s and its directory, or set CH_SYNC_DB", path)
+			return c
+		}
+		c.Status = doctorOK
+		c.Detail = path
+		return c
+	}
+
+	// Find the nearest existing directory; sync creates the rest
+	dir := filepath.Dir(path)
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	if err := probeDir(dir); err != nil {
+		c.Status = doctorFail
+		c.Detail = fmt.Sprintf("%s is not writable", dir)
+		c.Hint = "set CH_SYNC_DB to a writable location"
+		return c
+	}
+
+	c.Status = doctorOK
+	c.Detail = fmt.Sprintf("%s (created on first sync)", path)
+	return c
+}
+
+// checkWritable reports an error unless the file at path can be opened for
+// writing and its directory accepts new files. The file is not modified.
+func checkWritable(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return probeDir(filepath.Dir(path))
+}
+
+// probeDir checks that dir is writable by creating and removing a temp file.
+func probeDir(dir string) error {
+	probe, err := os.CreateTemp(dir, ".ch-doctor-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// renderDoctor prints the checklist, or the checks as JSON.
+func renderDoctor(w io.Writer, checks []doctorCheck, asJSON bool) error {
+	if asJSON {
+		encoder := display.NewJSONEncoder(w, jsonCompact)
+		return encoder.Encode(checks)
+	}
+
+	for _, c := range checks {
+		var label string
+		switch c.Status {
+		case doctorOK:
+			label = display.Success("OK  ")
+		case doctorWarn:
+			label = display.Warning("WARN")
+		default:
+			label = display.Error("FAIL")
+		}
+		fmt.Fprintf(w, "%s  %-14s %s\n", label, c.Name, c.Detail)
+		if c.Hint != "" {
+			fmt.Fprintf(w, "      %-14s %s\n", "", display.Dim("hint: "+c.Hint))
+		}
+	}
+	return nil
+}
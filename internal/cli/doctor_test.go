@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dmora/ch/internal/config"
+	"github.com/dmora/ch/internal/syncdb"
+)
+
+// doctorHome points HOME at a temp dir with a projects dir holding one
+// conversation, clears the env overrides, and returns the loaded config.
+func doctorHome(t *testing.T) (string, *config.Config) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_PROJECTS_DIR", "")
+	t.Setenv("CH_SYNC_DB", "")
+
+	c := config.Load()
+	project := filepath.Join(c.ProjectsDir, "-test-project")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "conv-1.jsonl"), []byte(bookmarkConversation), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return home, c
+}
+
+// doctorStatus returns the status of the named check.
+func doctorStatus(t *testing.T, checks []doctorCheck, name string) doctorCheck {
+	t.Helper()
+	for _, c := range checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no %q check in %+v", name, checks)
+	return doctorCheck{}
+}
+
+func TestRunDoctorChecks(t *testing.T) {
+	home, c := doctorHome(t)
+	c.ClaudeBin = "ch-doctor-missing-binary"
+
+	checks := runDoctorChecks(config.ConfigPath(), c)
+	want := map[string]string{
+		"config":        doctorOK,
+		"projects dir":  doctorOK,
+		"conversations": doctorOK,
+		"claude binary": doctorWarn,
+		"sync db":       doctorOK,
+	}
+	for name, status := range want {
+		if got := doctorStatus(t, checks, name); got.Status != status {
+			t.Errorf("%s = %+v, want %s", name, got, status)
+		}
+	}
+	if got := doctorStatus(t, checks, "sync db"); !strings.Contains(got.Detail, "created on first sync") {
+		t.Errorf("sync db detail = %q, want it to note the database is created on first sync", got.Detail)
+	}
+
+	// Doctor never creates the database or its directory
+	if _, err := os.Stat(filepath.Join(home, ".ch")); !os.IsNotExist(err) {
+		t.Errorf("doctor created %s (stat error = %v)", filepath.Join(home, ".ch"), err)
+	}
+}
+
+func TestCheckProjectsDir(t *testing.T) {
+	dir := t.TempDir()
+	if got := checkProjectsDir(dir); got.Status != doctorOK {
+		t.Errorf("checkProjectsDir(existing) = %+v, want ok", got)
+	}
+	if got := checkProjectsDir(filepath.Join(dir, "missing")); got.Status != doctorFail || got.Hint == "" {
+		t.Errorf("checkProjectsDir(missing) = %+v, want fail with a hint", got)
+	}
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := checkProjectsDir(file); got.Status != doctorFail {
+		t.Errorf("checkProjectsDir(file) = %+v, want fail", got)
+	}
+}
+
+func TestCheckConversations_Empty(t *testing.T) {
+	if got := checkConversations(t.TempDir()); got.Status != doctorWarn {
+		t.Errorf("checkConversations(empty) = %+v, want warn", got)
+	}
+}
+
+func TestCheckConfigFile_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("workers: [not a number\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := checkConfigFile(path); got.Status != doctorFail {
+		t.Errorf("checkConfigFile(invalid) = %+v, want fail", got)
+	}
+}
+
+func TestCheckSyncDB(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sync.db")
+
+	db, err := syncdb.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	db.Close()
+	if got := checkSyncDB(path); got.Status != doctorOK || got.Detail != path {
+		t.Errorf("checkSyncDB(existing) = %+v, want ok", got)
+	}
+
+	// A file where the directory should be can't be written, even as root
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := checkSyncDB(filepath.Join(file, "sub", "sync.db")); got.Status != doctorFail {
+		t.Errorf("checkSyncDB(under a file) = %+v, want fail", got)
+	}
+}
+
+func TestCheckSyncDB_ReadOnly(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write read-only files")
+	}
+	path := filepath.Join(t.TempDir(), "sync.db")
+	db, err := syncdb.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	db.Close()
+	if err := os.Chmod(path, 0444); err != nil {
+		t.Fatal(err)
+	}
+
+	got := checkSyncDB(path)
+	if got.Status != doctorFail || !strings.Contains(got.Detail, "not writable") {
+		t.Errorf("checkSyncDB(read-only) = %+v, want fail as not writable", got)
+	}
+}
+
+func TestRenderDoctor_JSON(t *testing.T) {
+	checks := []doctorCheck{
+		{Name: "projects dir", Status: doctorFail, Detail: "missing", Hint: "set it"},
+		{Name: "sync db", Status: doctorOK, Detail: "sync.db"},
+	}
+	var out strings.Builder
+	if err := renderDoctor(&out, checks, true); err != nil {
+		t.Fatalf("renderDoctor() error = %v", err)
+	}
+
+	var got []map[string]string
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(got) != 2 || got[0]["status"] != doctorFail || got[0]["hint"] != "set it" {
+		t.Errorf("renderDoctor() JSON = %v", got)
+	}
+	if _, ok := got[1]["hint"]; ok {
+		t.Errorf("empty hint should be omitted, got %v", got[1])
+	}
+}
@@ -0,0 +1,39 @@
+package cli
+
+import "fmt"
+
+// Exit codes returned by ch. Scripts can rely on these values.
+const (
+	ExitCodeOK       = 0 // Success with results
+	ExitCodeError    = 1 // Runtime error
+	ExitCodeEmpty    = 2 // Success, but nothing matched
+	ExitCodeNotFound = 3 // The requested conversation does not exist
+)
+
+// ExitError is an error that carries a process exit code.
+// Err may be nil when there is nothing to report (e.g. empty results).
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// errEmpty signals a successful command that produced no results.
+func errEmpty() error {
+	return &ExitError{Code: ExitCodeEmpty}
+}
+
+// errNotFound wraps a not-found error with ExitCodeNotFound.
+func errNotFound(format string, args ...interface{}) error {
+	return &ExitError{Code: ExitCodeNotFound, Err: fmt.Errorf(format, args...)}
+}
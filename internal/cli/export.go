@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/parallel"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [id]",
+	Short: "Export conversations to Markdown or JSON",
+	Long: `Export a conversation, or every conversation in a project, to Markdown or JSON.
+
+With an ID, the conversation is written to stdout (or --output).
+With --all, each conversation in the project is written to its own file in --out.
+
+Examples:
+  ch export abc123                         # Export one conversation as Markdown
+  ch export abc123 --format json --output a.json
+  ch export abc123 --after-uuid <uuid>     # Only entries after that message
+  ch export --all --out ./archive          # Export the current project
+  ch export --all -p myproj --out ./archive --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExport,
+}
+
+var (
+	exportAll      bool
+	exportProject  string
+	exportOutDir   string
+	exportFormat   string
+	exportOutput   string
+	exportThinking bool
+	exportTools    bool
+	exportAfter    string
+)
+
+// Export formats.
+const (
+	exportFormatMarkdown = "md"
+	exportFormatJSON     = "json"
+)
+
+func init() {
+	exportCmd.Flags().BoolVar(&exportAll, "all", false, "Export every conversation in the project")
+	exportCmd.Flags().StringVarP(&exportProject, "project", "p", "", "Project to export with --all (default: current dir's project)")
+	exportCmd.Flags().StringVar(&exportOutDir, "out", "", "Output directory for --all")
+	exportCmd.Flags().StringVar(&exportFormat, "format", exportFormatMarkdown, "Output format: md or json")
+	exportCmd.Flags().StringVar(&exportOutput, "output", "", "Write a single export to a file instead of stdout")
+	exportCmd.Flags().BoolVar(&exportThinking, "thinking", true, "Include thinking blocks")
+	exportCmd.Flags().BoolVar(&exportTools, "tools", true, "Include tool calls")
+	exportCmd.Flags().StringVar(&exportAfter, "after-uuid", "", "Only export entries after the message with this UUID (stable cursor, like show --after)")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	if exportFormat != exportFormatMarkdown && exportFormat != exportFormatJSON {
+		return fmt.Errorf("invalid format: %s (must be md or json)", exportFormat)
+	}
+
+	if exportAll {
+		if len(args) > 0 {
+			return fmt.Errorf("cannot combine a conversation ID with --all")
+		}
+		if exportAfter != "" {
+			return fmt.Errorf("--after-uuid requires a single conversation ID, not --all")
+		}
+		if exportOutDir == "" {
+			return fmt.Errorf("--out is required with --all")
+		}
+		return runExportAll(cmd.Context())
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("requires a conversation ID or --all")
+	}
+	return runExportOne(args[0])
+}
+
+// runExportOne exports a single conversation to stdout or --output.
+func runExportOne(id string) (err error) {
+	path, err := findConversationFile(id)
+	if err != nil {
+		return err
+	}
+
+	conv, err := history.LoadConversation(path)
+	if err != nil {
+		return fmt.Errorf("loading conversation: %w", err)
+	}
+	if exportAfter != "" {
+		if err := conv.TrimThroughUUID(exportAfter); err != nil {
+			return &ExitError{Code: ExitCodeNotFound, Err: err}
+		}
+	}
+
+	out, closeOutput, err := openOutput(exportOutput)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := closeOutput(); err == nil {
+			err = cerr
+		}
+	}()
+
+	return newExportDisplay(out).Render(conv)
+}
+
+// exportResult is the outcome of exporting one conversation file.
+type exportResult struct {
+	source string
+	err    error
+}
+
+// runExportAll exports every conversation in a project to individual files.
+func runExportAll(ctx context.Context) error {
+	projectPath, err := resolveExportProject()
+	if err != nil || projectPath == "" {
+		return err
+	}
+
+	if err := os.MkdirAll(exportOutDir, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	scanner := history.NewScanner(history.ScannerOptions{
+		ProjectsDir:   cfg.ProjectsDir,
+		ProjectPath:   projectPath,
+		IncludeAgents: true,
+		Workers:       cfg.Workers,
+	})
+	metas, err := scanner.ScanAll(ctx)
+	if err != nil {
+		return fmt.Errorf("scanning conversations: %w", err)
+	}
+
+	names := exportFileNames(metas)
+	paths := make([]string, len(metas))
+	for i, m := range metas {
+		paths[i] = m.Path
+	}
+
+	// Color codes must never end up in exported files
+	defer display.SetColorEnabled(display.IsColorEnabled())
+	display.SetColorEnabled(false)
+
+	results := parallel.ProcessFiles(paths, cfg.Workers, func(path string) (exportResult, bool) {
+		target := filepath.Join(exportOutDir, names[path])
+		return exportResult{source: path, err: exportToFile(path, target)}, true
+	})
+
+	written := 0
+	var skipped []exportResult
+	for _, r := range results {
+		if r.err != nil {
+			skipped = append(skipped, r)
+			fmt.Fprintf(os.Stderr, "%s skipping %s: %v\n", display.Warning("Warning:"), r.source, r.err)
+			continue
+		}
+		written++
+	}
+
+	fmt.Printf("Exported %s conversations to %s\n", display.Number(fmt.Sprintf("%d", written)), exportOutDir)
+	if len(skipped) > 0 {
+		fmt.Printf("%s\n", display.Warning(fmt.Sprintf("Skipped %d files that could not be exported", len(skipped))))
+	}
+	return nil
+}
+
+// resolveExportProject returns the project path to export, or "" if the
+// project name was ambiguous (the matches have already been printed).
+func resolveExportProject() (string, error) {
+	if exportProject == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("getting current directory: %w", err)
+		}
+		return cwd, nil
+	}
+
+	resolvedPath, ambiguous, err := history.ResolveProjectPath(cfg.ProjectsDir, exportProject)
+	if err != nil {
+		return "", fmt.Errorf("resolving project: %w", err)
+	}
+	if len(ambiguous) > 0 {
+		printAmbiguousProjects(exportProject, ambiguous)
+		return "", nil
+	}
+	return resolvedPath, nil
+}
+
+// exportFileNames picks an output file name for each conversation, keyed by
+// source path. Short IDs are used unless two conversations would collide.
+func exportFileNames(metas []*history.ConversationMeta) map[string]string {
+	baseName := func(m *history.ConversationMeta, id string) string {
+		if m.IsAgent {
+			return "agent-" + id
+		}
+		return id
+	}
+
+	counts := make(map[string]int)
+	for _, m := range metas {
+		counts[baseName(m, history.ShortID(m.ID))]++
+	}
+
+	names := make(map[string]string, len(metas))
+	for _, m := range metas {
+		name := baseName(m, history.ShortID(m.ID))
+		if counts[name] > 1 {
+			name = baseName(m, m.ID)
+		}
+		names[m.Path] = name + "." + exportFormat
+	}
+	return names
+}
+
+// exportToFile loads a conversation and writes it to target.
+func exportToFile(source, target string) error {
+	conv, err := history.LoadConversation(source)
+	if err != nil {
+		return fmt.Errorf("loading conversation: %w", err)
+	}
+
+	file, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+
+	if err := newExportDisplay(file).Render(conv); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// newExportDisplay creates a conversation display for the export format.
+func newExportDisplay(w io.Writer) *display.ConversationDisplay {
+	return display.NewConversationDisplay(display.ConversationDisplayOptions{
+		Writer:       w,
+		ShowThinking: exportThinking,
+		ShowTools:    exportTools,
+		JSON:         exportFormat == exportFormatJSON,
+		Compact:      jsonCompact,
+		Markdown:     exportFormat == exportFormatMarkdown,
+		TimeFormat:   timeFmt,
+		TimeLayout:   jsonTime,
+	})
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/searchindex"
+	"github.com/dmora/ch/internal/syncdb"
+	"github.com/spf13/cobra"
+)
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Manage the search index",
+	Long: `Manage the full-text search index stored in the ch database.
+
+Once built, 'ch search' uses the index to skip conversations that can't
+contain the query, and only reads the rest. Conversations added or changed
+since the last build are always searched directly, so results never go stale;
+rebuilding just keeps searches fast. Builds are incremental: only files whose
+size or modification time changed are reindexed.
+
+Examples:
+  ch index build     # Build or update the index
+  ch index status    # Show index size and how many files are out of date`,
+}
+
+var indexBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build or update the search index",
+	Args:  cobra.NoArgs,
+	RunE:  runIndexBuild,
+}
+
+var indexStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show search index status",
+	Args:  cobra.NoArgs,
+	RunE:  runIndexStatus,
+}
+
+func init() {
+	indexCmd.AddCommand(indexBuildCmd)
+	indexCmd.AddCommand(indexStatusCmd)
+}
+
+func runIndexBuild(cmd *cobra.Command, args []string) error {
+	return indexBuild(cmd.Context(), os.Stdout)
+}
+
+// indexBuild builds or updates the index and reports the result to out.
+func indexBuild(ctx context.Context, out io.Writer) error {
+	db, err := syncdb.Open(cfg.Sync.DBPath)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	result, err := searchindex.New(db).Build(ctx, searchindex.BuildOptions{
+		ProjectsDir: cfg.ProjectsDir,
+		Workers:     cfg.Workers,
+	})
+	if err := warnIfCanceled(err); err != nil {
+		return fmt.Errorf("building index: %w", err)
+	}
+
+	fmt.Fprintf(out, "%s\n", display.Dim("Index Build"))
+	fmt.Fprintf(out, "  Files scanned: %d\n", result.FilesScanned)
+	fmt.Fprintf(out, "  Files indexed: %d\n", result.FilesIndexed)
+	fmt.Fprintf(out, "  Files removed: %d\n", result.FilesRemoved)
+	fmt.Fprintf(out, "  Duration:      %s\n", result.Duration.Round(time.Millisecond))
+
+	if len(result.Errors) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%s\n", display.Dim("Errors:"))
+		for _, e := range result.Errors {
+			fmt.Fprintf(os.Stderr, "  %s\n", e)
+		}
+	}
+	return nil
+}
+
+func runIndexStatus(cmd *cobra.Command, args []string) error {
+	return indexStatus(os.Stdout)
+}
+
+// indexStatus reports the size and freshness of the index to out.
+func indexStatus(out io.Writer) error {
+	db, err := syncdb.Open(cfg.Sync.DBPath)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	status, err := searchindex.New(db).Status(cfg.ProjectsDir)
+	if err != nil {
+		return fmt.Errorf("getting index status: %w", err)
+	}
+
+	fmt.Fprintf(out, "%s\n", display.Dim("Search Index"))
+	fmt.Fprintf(out, "  Database:         %s\n", cfg.Sync.DBPath)
+	fmt.Fprintf(out, "  Indexed files:    %d\n", status.Files)
+	fmt.Fprintf(out, "  Indexed messages: %d\n", status.Messages)
+	if status.LastIndexedAt > 0 {
+		fmt.Fprintf(out, "  Last updated:     %s\n", time.Unix(status.LastIndexedAt, 0).Format("2006-01-02 15:04:05"))
+	}
+	fmt.Fprintf(out, "  Out of date:      %d\n", status.Stale)
+
+	if status.Files == 0 {
+		fmt.Fprintf(out, "\n%s\n", display.Dim("The index is empty. Run 'ch index build' to create it."))
+	} else if status.Stale > 0 {
+		fmt.Fprintf(out, "\n%s\n", display.Dim("Run 'ch index build' to index new and changed conversations."))
+	}
+	return nil
+}
+
+// openSearchIndex returns the search index if one has been built. The
+// database is never created here, so searching works without one. The
+// returned close function must be called when the search is done.
+func openSearchIndex() (history.SearchIndex, func(), error) {
+	if _, err := os.Stat(cfg.Sync.DBPath); err != nil {
+		return nil, func() {}, nil
+	}
+	db, err := syncdb.Open(cfg.Sync.DBPath)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	stats, err := db.IndexStats()
+	if err != nil || stats.Files == 0 {
+		db.Close()
+		return nil, func() {}, err
+	}
+	return searchindex.New(db), func() { db.Close() }, nil
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dmora/ch/internal/config"
+	"github.com/dmora/ch/internal/display"
+)
+
+// useIndexConfig points cfg at a temp projects directory holding one
+// conversation and a sync database path that doesn't exist yet.
+func useIndexConfig(t *testing.T) string {
+	t.Helper()
+	oldCfg := cfg
+	t.Cleanup(func() { cfg = oldCfg })
+	display.SetColorEnabled(false)
+	t.Cleanup(func() { display.SetColorEnabled(true) })
+
+	dir := t.TempDir()
+	project := filepath.Join(dir, "projects", "-test-project")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "conv-1.jsonl"), []byte(bookmarkConversation), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dbPath := filepath.Join(dir, "sync.db")
+	cfg = &config.Config{
+		ProjectsDir: filepath.Join(dir, "projects"),
+		Workers:     2,
+		Sync:        config.SyncConfig{DBPath: dbPath},
+	}
+	return dbPath
+}
+
+func TestOpenSearchIndex_NoDatabase(t *testing.T) {
+	dbPath := useIndexConfig(t)
+
+	index, closeIndex, err := openSearchIndex()
+	defer closeIndex()
+	if index != nil || err != nil {
+		t.Errorf("openSearchIndex() = %v, %v; want no index", index, err)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("openSearchIndex created the database (stat error = %v)", err)
+	}
+}
+
+func TestIndexBuildAndStatus(t *testing.T) {
+	useIndexConfig(t)
+
+	var out strings.Builder
+	if err := indexStatus(&out); err != nil {
+		t.Fatalf("indexStatus() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "The index is empty") || !strings.Contains(out.String(), "Out of date:      1") {
+		t.Errorf("status before build = %q, want empty with 1 out of date", out.String())
+	}
+
+	// An empty index isn't used for searching
+	index, closeIndex, err := openSearchIndex()
+	closeIndex()
+	if index != nil || err != nil {
+		t.Errorf("openSearchIndex() with an empty index = %v, %v; want no index", index, err)
+	}
+
+	out.Reset()
+	if err := indexBuild(context.Background(), &out); err != nil {
+		t.Fatalf("indexBuild() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Files indexed: 1") {
+		t.Errorf("build output = %q, want 1 file indexed", out.String())
+	}
+
+	out.Reset()
+	if err := indexStatus(&out); err != nil {
+		t.Fatalf("indexStatus() error = %v", err)
+	}
+	for _, want := range []string{"Indexed files:    1", "Indexed messages: 3", "Out of date:      0"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("status after build = %q, want %q", out.String(), want)
+		}
+	}
+
+	index, closeIndex, err = openSearchIndex()
+	defer closeIndex()
+	if index == nil || err != nil {
+		t.Fatalf("openSearchIndex() after build = %v, %v; want the index", index, err)
+	}
+	path := filepath.Join(cfg.ProjectsDir, "-test-project", "conv-1.jsonl")
+	if got, err := index.Candidates("second", []string{path}); err != nil || len(got) != 1 {
+		t.Errorf("Candidates(second) = %v, %v; want the conversation", got, err)
+	}
+	if got, err := index.Candidates("missing", []string{path}); err != nil || len(got) != 0 {
+		t.Errorf("Candidates(missing) = %v, %v; want none", got, err)
+	}
+}
@@ -27,22 +27,118 @@ var (
 	listLimit   int
 	listGlobal  bool
 	listJSON    bool
+	listCSV     bool
+	listIDOnly  bool
+	listTag     string
+	listModel   string
+	listBranch  string
+	listCWD     bool
+	listDurn    bool
+	listFullID  bool
+	listPerProj int
+	listMinMsgs int
+	listMaxMsgs int
+	listPreview int
+	listMaxSize byteSize
+	listAgentTy string
+	listOnlyAg  bool
+	listOnlyMn  bool
 )
 
 func init() {
 	listCmd.Flags().BoolVarP(&listAgents, "agents", "a", true, "Include agent/subagent conversations (default: true)")
+	listCmd.Flags().BoolVar(&listOnlyAg, "only-agents", false, "List only agent/subagent conversations")
+	listCmd.Flags().BoolVar(&listOnlyMn, "only-main", false, "List only main conversations, with their agent counts")
 	listCmd.Flags().StringVarP(&listProject, "project", "p", "", "Filter by project path")
 	listCmd.Flags().IntVarP(&listLimit, "limit", "n", 50, "Limit number of results")
 	listCmd.Flags().BoolVarP(&listGlobal, "global", "g", false, "List from all projects")
 	listCmd.Flags().BoolVar(&listJSON, "json", false, "Output as JSON")
+	listCmd.Flags().BoolVar(&listCSV, "csv", false, "Output as CSV (id, project, timestamp, messages, size, model, is_agent, preview)")
+	listCmd.Flags().BoolVar(&listIDOnly, "id-only", false, "Print only conversation IDs, one per line (full IDs with --full-id)")
+	listCmd.Flags().StringVar(&listTag, "tag", "", "Only show conversations with this tag")
+	listCmd.Flags().StringVar(&listAgentTy, "agent-type", "", "Only show agents of this subagent type (e.g. Explore)")
+	listCmd.Flags().BoolVar(&listCWD, "cwd", false, "Show the working directory recorded in each conversation")
+	listCmd.Flags().StringVar(&listModel, "model", "", "Only show conversations using a matching model (e.g. opus)")
+	listCmd.Flags().StringVar(&listBranch, "branch", "", "Only show conversations started on this git branch")
+	listCmd.Flags().BoolVar(&listFullID, "full-id", false, "Show complete conversation IDs instead of the 8-character short form")
+	listCmd.Flags().BoolVar(&listDurn, "duration", false, "Show how long each session lasted (first to last message)")
+	listCmd.Flags().IntVar(&listPerProj, "per-project", 0, "Keep at most N newest conversations per project (applied before --limit)")
+	listCmd.Flags().IntVar(&listPreview, "preview-len", 0, "Preview length in characters (default: fit the terminal, else 60 in the table; 100 in JSON)")
+	listCmd.Flags().IntVar(&listMinMsgs, "min-messages", 0, "Only show conversations with at least N messages")
+	listCmd.Flags().Var(&listMaxSize, "max-size", "Skip files larger than this size (e.g. 200M; default: no limit)")
+	listCmd.Flags().IntVar(&listMaxMsgs, "max-messages", 0, "Only show conversations with at most N messages")
 }
 
+// minPreviewLen is the smallest accepted --preview-len; shorter previews
+// would be mostly ellipsis.
+const minPreviewLen = 10
+
 func runList(cmd *cobra.Command, args []string) error {
+	if listPerProj < 0 {
+		return fmt.Errorf("--per-project cannot be negative")
+	}
+	if listJSON && listCSV {
+		return fmt.Errorf("--json and --csv are mutually exclusive")
+	}
+	if listIDOnly && (listJSON || listCSV) {
+		return fmt.Errorf("--id-only cannot be combined with --json or --csv")
+	}
+	if listPreview != 0 && listPreview < minPreviewLen {
+		return fmt.Errorf("--preview-len must be at least %d", minPreviewLen)
+	}
+	if listMinMsgs < 0 || listMaxMsgs < 0 {
+		return fmt.Errorf("--min-messages and --max-messages cannot be negative")
+	}
+	if listMaxMsgs > 0 && listMinMsgs > listMaxMsgs {
+		return fmt.Errorf("--min-messages (%d) cannot exceed --max-messages (%d)", listMinMsgs, listMaxMsgs)
+	}
+	if listOnlyAg && listOnlyMn {
+		return fmt.Errorf("--only-agents and --only-main are mutually exclusive")
+	}
+	if listOnlyAg && !listAgents {
+		return fmt.Errorf("--only-agents requires agents to be included (drop --agents=false)")
+	}
+	if listAgentTy != "" && listOnlyMn {
+		return fmt.Errorf("--agent-type cannot be combined with --only-main")
+	}
+	if listAgentTy != "" && !listAgents {
+		return fmt.Errorf("--agent-type requires agents to be included (drop --agents=false)")
+	}
+	includeAgents := listAgents && !listOnlyMn
+
+	// Without --preview-len, the table's preview fills a terminal's width,
+	// so scan previews long enough to fill it
+	scanPreview, width := listPreview, 0
+	if listPreview == 0 && !listJSON && !listCSV && !listIDOnly && display.IsTTY() {
+		width = display.TerminalWidth()
+		scanPreview = max(width, history.DefaultPreviewLen)
+	}
+
 	opts := history.ScannerOptions{
-		ProjectsDir:   cfg.ProjectsDir,
-		IncludeAgents: listAgents,
-		Limit:         listLimit,
-		SortByTime:    true,
+		ProjectsDir:     cfg.ProjectsDir,
+		IncludeAgents:   includeAgents,
+		OnlyAgents:      listOnlyAg,
+		Limit:           listLimit,
+		SortByTime:      true,
+		Workers:         cfg.Workers,
+		Model:           listModel,
+		Branch:          listBranch,
+		AgentType:       listAgentTy,
+		LimitPerProject: listPerProj,
+		MinMessages:     listMinMsgs,
+		MaxMessages:     listMaxMsgs,
+		PreviewLen:      scanPreview,
+		MaxFileSize:     int64(listMaxSize),
+	}
+
+	tags, err := loadTags()
+	if err != nil {
+		return err
+	}
+
+	// Tag filtering happens after the scan, so the limit is applied afterwards
+	if listTag != "" {
+		opts.Limit = 0
 	}
 
 	// Determine project filter
@@ -57,14 +153,24 @@ func runList(cmd *cobra.Command, args []string) error {
 		opts.ProjectPath = cwd
 	}
 
+	var stopProgress func()
+	opts.Progress, stopProgress = startProgress("Scanning")
 	scanner := history.NewScanner(opts)
-	conversations, err := scanner.ScanAll()
-	if err != nil {
+	conversations, err := scanner.ScanAll(cmd.Context())
+	stopProgress()
+	if err := warnIfCanceled(err); err != nil {
 		return fmt.Errorf("scanning conversations: %w", err)
 	}
 
+	if listTag != "" {
+		conversations = filterByTag(conversations, tags, listTag)
+		if listLimit > 0 && len(conversations) > listLimit {
+			conversations = conversations[:listLimit]
+		}
+	}
+
 	// If not showing agents, count them for each main conversation
-	if !listAgents {
+	if !includeAgents {
 		for _, c := range conversations {
 			if !c.IsAgent {
 				projectDir := filepath.Dir(c.Path)
@@ -76,7 +182,7 @@ func runList(cmd *cobra.Command, args []string) error {
 	// Count projects for global view
 	projectCount := 0
 	if listGlobal || listProject == "" {
-		projects, _ := history.ListProjects(cfg.ProjectsDir)
+		projects, _ := history.ListProjectsWithWorkers(cfg.ProjectsDir, cfg.Workers)
 		projectCount = len(projects)
 	}
 
@@ -89,12 +195,44 @@ func runList(cmd *cobra.Command, args []string) error {
 	// Render table
 	table := display.NewConversationTable(display.TableOptions{
 		Writer:       os.Stdout,
-		ShowAgent:    listAgents,
+		ShowAgent:    includeAgents,
+		ShowCWD:      listCWD,
+		ShowDuration: listDurn,
+		ShowFullID:   listFullID,
+		TimeFormat:   timeFmt,
+		TimeLayout:   jsonTime,
+		PreviewLen:   listPreview,
+		Width:        width,
 		JSON:         listJSON,
+		Compact:      jsonCompact,
+		CSV:          listCSV,
+		IDOnly:       listIDOnly,
 		ProjectPath:  displayProject,
 		IsGlobal:     listGlobal,
 		ProjectCount: projectCount,
+		Tags:         tags,
 	})
 
-	return table.Render(conversations)
+	if err := table.Render(conversations); err != nil {
+		return err
+	}
+	warnSkippedLarge(scanner.SkippedFiles(), listMaxSize)
+	if len(conversations) == 0 {
+		return errEmpty()
+	}
+	return nil
+}
+
+// filterByTag keeps only conversations labeled with tag.
+func filterByTag(conversations []*history.ConversationMeta, tags map[string][]string, tag string) []*history.ConversationMeta {
+	var filtered []*history.ConversationMeta
+	for _, c := range conversations {
+		for _, t := range tags[c.ID] {
+			if t == tag {
+				filtered = append(filtered, c)
+				break
+			}
+		}
+	}
+	return filtered
 }
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var openCmd = &cobra.Command{
+	Use:   "open <id>",
+	Short: "Open a conversation's JSONL file",
+	Long: `Reveal a conversation's JSONL file in the OS file manager.
+
+The id can be:
+  - A full session UUID
+  - A short ID (first 8 characters)
+  - An agent ID (agent-xxxxx)
+
+Use --path to print the file path, or --edit to open it in $EDITOR.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runOpen,
+}
+
+var (
+	openPath bool
+	openEdit bool
+)
+
+func init() {
+	openCmd.Flags().BoolVar(&openPath, "path", false, "Print the file path instead of opening it")
+	openCmd.Flags().BoolVar(&openEdit, "edit", false, "Open the file in $EDITOR")
+}
+
+func runOpen(cmd *cobra.Command, args []string) error {
+	if openPath && openEdit {
+		return fmt.Errorf("--path and --edit are mutually exclusive")
+	}
+
+	path, err := findConversationFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	if openPath {
+		fmt.Println(path)
+		return nil
+	}
+
+	var launch *exec.Cmd
+	if openEdit {
+		launch, err = editorCommand(path)
+		if err != nil {
+			return err
+		}
+		launch.Stdin = os.Stdin
+		launch.Stdout = os.Stdout
+	} else {
+		launch = revealCommand(runtime.GOOS, path)
+	}
+	launch.Stderr = os.Stderr
+
+	if err := launch.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", launch.Args[0], err)
+	}
+	return nil
+}
+
+// editorCommand builds a command that opens path in $VISUAL or $EDITOR.
+// The variable may include arguments, e.g. "code --wait".
+func editorCommand(path string) (*exec.Cmd, error) {
+	fields := strings.Fields(os.Getenv("VISUAL"))
+	if len(fields) == 0 {
+		fields = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("$EDITOR is not set; use --path to print the file path")
+	}
+	return exec.Command(fields[0], append(fields[1:], path)...), nil
+}
+
+// revealCommand builds a command that shows path in the file manager of the
+// platform goos (a runtime.GOOS value).
+func revealCommand(goos, path string) *exec.Cmd {
+	switch goos {
+	case "darwin":
+		return exec.Command("open", "-R", path)
+	case "windows":
+		return exec.Command("explorer", "/select,", path)
+	default:
+		// xdg-open cannot select a file, so open its directory
+		return exec.Command("xdg-open", filepath.Dir(path))
+	}
+}
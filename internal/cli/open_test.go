@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEditorCommand(t *testing.T) {
+	const path = "/tmp/conv.jsonl"
+	tests := []struct {
+		name   string
+		visual string
+		editor string
+		want   []string
+	}{
+		{name: "editor", editor: "vim", want: []string{"vim", path}},
+		{name: "visual wins over editor", visual: "nvim", editor: "vim", want: []string{"nvim", path}},
+		{name: "editor with arguments", editor: "code -w", want: []string{"code", "-w", path}},
+		{name: "visual with arguments", visual: "  subl  --wait ", editor: "vim", want: []string{"subl", "--wait", path}},
+		{name: "blank visual falls back", visual: " ", editor: "vim", want: []string{"vim", path}},
+		{name: "neither set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tt.visual)
+			t.Setenv("EDITOR", tt.editor)
+
+			cmd, err := editorCommand(path)
+			if tt.want == nil {
+				if err == nil {
+					t.Errorf("editorCommand() = %v, want an error", cmd.Args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("editorCommand() error = %v", err)
+			}
+			if !slices.Equal(cmd.Args, tt.want) {
+				t.Errorf("editorCommand() = %q, want %q", cmd.Args, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevealCommand(t *testing.T) {
+	const path = "/home/u/.claude/projects/-p/conv.jsonl"
+	tests := []struct {
+		goos string
+		want []string
+	}{
+		{"darwin", []string{"open", "-R", path}},
+		{"windows", []string{"explorer", "/select,", path}},
+		{"linux", []string{"xdg-open", "/home/u/.claude/projects/-p"}},
+		{"freebsd", []string{"xdg-open", "/home/u/.claude/projects/-p"}},
+	}
+	for _, tt := range tests {
+		if got := revealCommand(tt.goos, path).Args; !slices.Equal(got, tt.want) {
+			t.Errorf("revealCommand(%s) = %q, want %q", tt.goos, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/dmora/ch/internal/display"
+)
+
+// openOutput returns the writer for command output. With an empty path it
+// returns stdout; otherwise it creates the file and forces color off while
+// writing to it. The returned close function restores color and closes the file.
+func openOutput(path string) (io.Writer, func() error, error) {
+	if path == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+
+	dir := filepath.Dir(path)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, nil, fmt.Errorf("output directory does not exist: %s", dir)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating output file: %w", err)
+	}
+
+	colorWasEnabled := display.IsColorEnabled()
+	display.SetColorEnabled(false)
+
+	closeFn := func() error {
+		display.SetColorEnabled(colorWasEnabled)
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("writing output file: %w", err)
+		}
+		return nil
+	}
+	return file, closeFn, nil
+}
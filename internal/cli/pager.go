@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/dmora/ch/internal/display"
+)
+
+// defaultPager is used when $PAGER is unset; -R passes color escapes through.
+const defaultPager = "less -R"
+
+// pagerCommand builds the pager command from $PAGER, falling back to
+// defaultPager. It returns nil when paging is disabled with PAGER=cat or an
+// empty $PAGER, matching git.
+func pagerCommand() *exec.Cmd {
+	pager, ok := os.LookupEnv("PAGER")
+	if !ok {
+		pager = defaultPager
+	}
+	fields := strings.Fields(pager)
+	if len(fields) == 0 || fields[0] == "cat" {
+		return nil
+	}
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Like git, keep less from clearing the screen on exit unless configured
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	return cmd
+}
+
+// renderPaged runs render into a buffer and shows the result through the
+// pager when it is taller than the terminal. Shorter output, or output the
+// pager can't be started for, is written to out. Without enabled, render
+// writes to out directly.
+func renderPaged(out io.Writer, enabled bool, render func(io.Writer) error) error {
+	if !enabled {
+		return render(out)
+	}
+
+	var buf bytes.Buffer
+	err := render(&buf)
+
+	height := display.TerminalHeight()
+	if height > 0 && bytes.Count(buf.Bytes(), []byte("\n")) >= height {
+		if cmd := pagerCommand(); cmd != nil {
+			cmd.Stdin = &buf
+			if cmd.Start() == nil {
+				// The pager's exit status (e.g. quitting early) isn't an error
+				_ = cmd.Wait()
+				return err
+			}
+		}
+	}
+	if _, werr := out.Write(buf.Bytes()); err == nil {
+		err = werr
+	}
+	return err
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dmora/ch/internal/display"
+)
+
+// unsetenv unsets key for the duration of the test.
+func unsetenv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+func TestPagerCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		pager *string // nil leaves $PAGER unset
+		want  []string
+	}{
+		{name: "unset", want: []string{"less", "-R"}},
+		{name: "custom", pager: ptr("most -s"), want: []string{"most", "-s"}},
+		{name: "cat", pager: ptr("cat")},
+		{name: "cat with args", pager: ptr("cat -v")},
+		{name: "empty", pager: ptr("")},
+		{name: "blank", pager: ptr("  ")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.pager == nil {
+				unsetenv(t, "PAGER")
+			} else {
+				t.Setenv("PAGER", *tt.pager)
+			}
+
+			cmd := pagerCommand()
+			if tt.want == nil {
+				if cmd != nil {
+					t.Errorf("pagerCommand() = %v, want nil", cmd.Args)
+				}
+				return
+			}
+			if cmd == nil || !slices.Equal(cmd.Args, tt.want) {
+				t.Fatalf("pagerCommand() = %v, want %v", cmd, tt.want)
+			}
+		})
+	}
+}
+
+func TestPagerCommand_LESS(t *testing.T) {
+	t.Setenv("PAGER", "less")
+
+	unsetenv(t, "LESS")
+	if cmd := pagerCommand(); !slices.Contains(cmd.Env, "LESS=FRX") {
+		t.Errorf("pagerCommand() env without $LESS = %v, want LESS=FRX", cmd.Env)
+	}
+
+	t.Setenv("LESS", "-i")
+	if cmd := pagerCommand(); cmd.Env != nil {
+		t.Errorf("pagerCommand() env with $LESS set = %v, want inherited", cmd.Env)
+	}
+}
+
+// fakePager installs a $PAGER that copies its input to the returned file.
+func fakePager(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake pager is a shell script")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "pager")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat > \"$1\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	paged := filepath.Join(dir, "paged")
+	t.Setenv("PAGER", script+" "+paged)
+	return paged
+}
+
+// setTerminalHeight fakes a terminal of the given height through $LINES.
+func setTerminalHeight(t *testing.T, lines string) {
+	t.Helper()
+	unsetenv(t, "LINES")
+	if display.TerminalHeight() != 0 {
+		t.Skip("stdout is a terminal")
+	}
+	t.Setenv("LINES", lines)
+}
+
+func renderLines(n int) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, strings.Repeat("line\n", n))
+		return err
+	}
+}
+
+func TestRenderPaged(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		lines     int
+		wantPaged bool
+	}{
+		{name: "disabled", enabled: false, lines: 10},
+		{name: "shorter than terminal", enabled: true, lines: 4},
+		{name: "as tall as terminal", enabled: true, lines: 5, wantPaged: true},
+		{name: "taller than terminal", enabled: true, lines: 10, wantPaged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paged := fakePager(t)
+			setTerminalHeight(t, "5")
+
+			var out strings.Builder
+			if err := renderPaged(&out, tt.enabled, renderLines(tt.lines)); err != nil {
+				t.Fatalf("renderPaged() error = %v", err)
+			}
+
+			want := strings.Repeat("line\n", tt.lines)
+			got, _ := os.ReadFile(paged)
+			if tt.wantPaged {
+				if string(got) != want || out.Len() != 0 {
+					t.Errorf("paged = %q, out = %q; want all output paged", got, out.String())
+				}
+				return
+			}
+			if out.String() != want || got != nil {
+				t.Errorf("out = %q, paged = %q; want all output written directly", out.String(), got)
+			}
+		})
+	}
+}
+
+func TestRenderPaged_StartFails(t *testing.T) {
+	t.Setenv("PAGER", filepath.Join(t.TempDir(), "missing-pager"))
+	setTerminalHeight(t, "2")
+
+	var out strings.Builder
+	if err := renderPaged(&out, true, renderLines(5)); err != nil {
+		t.Fatalf("renderPaged() error = %v", err)
+	}
+	if out.String() != strings.Repeat("line\n", 5) {
+		t.Errorf("out = %q, want output written directly when the pager can't start", out.String())
+	}
+}
+
+func TestRenderPaged_RenderError(t *testing.T) {
+	paged := fakePager(t)
+	setTerminalHeight(t, "2")
+
+	renderErr := errors.New("render failed")
+	var out strings.Builder
+	err := renderPaged(&out, true, func(w io.Writer) error {
+		io.WriteString(w, "partial\n")
+		return renderErr
+	})
+	if !errors.Is(err, renderErr) {
+		t.Errorf("renderPaged() error = %v, want %v", err, renderErr)
+	}
+	if _, statErr := os.Stat(paged); !os.IsNotExist(statErr) || out.String() != "partial\n" {
+		t.Errorf("out = %q; want partial output written directly", out.String())
+	}
+}
+
+func ptr(s string) *string { return &s }
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var pickCmd = &cobra.Command{
+	Use:   "pick",
+	Short: "Interactively pick a conversation to show or resume",
+	Long: `Browse conversations with a fuzzy filter and pick one by number.
+
+Type text to filter by ID, project, model, or preview; enter a number to
+select; an empty line clears the filter; q quits.
+
+The selected conversation is shown, or resumed with --resume.
+Requires an interactive terminal.`,
+	Args: cobra.NoArgs,
+	RunE: runPick,
+}
+
+var (
+	pickResume  bool
+	pickGlobal  bool
+	pickProject string
+	pickAgents  bool
+	pickLimit   int
+)
+
+func init() {
+	pickCmd.Flags().BoolVar(&pickResume, "resume", false, "Resume the selected conversation instead of showing it")
+	pickCmd.Flags().BoolVarP(&pickGlobal, "global", "g", false, "Pick from all projects")
+	pickCmd.Flags().StringVarP(&pickProject, "project", "p", "", "Filter by project path")
+	pickCmd.Flags().BoolVarP(&pickAgents, "agents", "a", false, "Include agent conversations")
+	pickCmd.Flags().IntVarP(&pickLimit, "limit", "n", 200, "Maximum conversations to load")
+}
+
+func runPick(cmd *cobra.Command, args []string) error {
+	if !tui.IsInteractive() {
+		return fmt.Errorf("ch pick requires an interactive terminal; use 'ch list' instead")
+	}
+	if pickResume && pickAgents {
+		return fmt.Errorf("--resume cannot be used with --agents; agent conversations cannot be resumed")
+	}
+
+	opts := history.ScannerOptions{
+		ProjectsDir:   cfg.ProjectsDir,
+		IncludeAgents: pickAgents,
+		Limit:         pickLimit,
+		SortByTime:    true,
+		Workers:       cfg.Workers,
+	}
+	if pickProject != "" {
+		opts.ProjectPath = pickProject
+	} else if !pickGlobal {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting current directory: %w", err)
+		}
+		opts.ProjectPath = cwd
+	}
+
+	conversations, err := history.NewScanner(opts).ScanAll(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("scanning conversations: %w", err)
+	}
+	if len(conversations) == 0 {
+		fmt.Fprintln(os.Stderr, "No conversations found")
+		return errEmpty()
+	}
+
+	selected, err := tui.NewPicker(os.Stdin, os.Stderr, conversations).Run()
+	if errors.Is(err, tui.ErrAborted) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if pickResume {
+		return runResume(cmd, []string{selected.ID})
+	}
+	id := selected.ID
+	if selected.IsAgent {
+		id = "agent-" + id
+	}
+	return runShow(cmd, []string{id})
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/parallel"
+)
+
+const (
+	// progressDelay keeps quick operations from flashing a progress line.
+	progressDelay = 250 * time.Millisecond
+	// progressInterval throttles redraws of the progress line.
+	progressInterval = 100 * time.Millisecond
+)
+
+// progressLine draws a single self-overwriting "label 120/1000 files" line.
+type progressLine struct {
+	w     io.Writer
+	label string
+	start time.Time
+	last  time.Time
+	shown bool
+}
+
+// startProgress returns a ProgressFunc that draws the progress of label on
+// stderr, and a function that erases the line, to call before printing
+// results. Progress is off with --quiet or when stdout or stderr is not a
+// terminal, in which case the ProgressFunc is nil.
+func startProgress(label string) (parallel.ProgressFunc, func()) {
+	if quiet || !display.IsTTY() || !display.IsStderrTTY() {
+		return nil, func() {}
+	}
+	p := &progressLine{w: os.Stderr, label: label, start: time.Now()}
+	return p.update, p.clear
+}
+
+func (p *progressLine) update(done, total int) {
+	now := time.Now()
+	if now.Sub(p.start) < progressDelay || (done < total && now.Sub(p.last) < progressInterval) {
+		return
+	}
+	p.last = now
+	p.shown = true
+	fmt.Fprintf(p.w, "\r\033[K%s", display.Dim(fmt.Sprintf("%s %d/%d files", p.label, done, total)))
+}
+
+func (p *progressLine) clear() {
+	if p.shown {
+		fmt.Fprint(p.w, "\r\033[K")
+		p.shown = false
+	}
+}
@@ -23,7 +23,7 @@ func init() {
 }
 
 func runProjects(cmd *cobra.Command, args []string) error {
-	projects, err := history.ListProjects(cfg.ProjectsDir)
+	projects, err := history.ListProjectsWithWorkers(cfg.ProjectsDir, cfg.Workers)
 	if err != nil {
 		return err
 	}
@@ -37,6 +37,7 @@ func runProjects(cmd *cobra.Command, args []string) error {
 	table := display.NewProjectTable(display.TableOptions{
 		Writer:         os.Stdout,
 		JSON:           projectsJSON,
+		Compact:        jsonCompact,
 		CurrentProject: currentProject,
 	})
 
@@ -2,10 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 
+	"github.com/dmora/ch/internal/display"
 	"github.com/dmora/ch/internal/history"
 	"github.com/spf13/cobra"
 )
@@ -17,30 +19,43 @@ var resumeCmd = &cobra.Command{
 
 The id can be:
   - A full session UUID
-  - A short ID (first 8 characters)`,
+  - A short ID (first 8 characters)
+  - An agent ID, which resumes the conversation that spawned the agent`,
 	Args:    cobra.ExactArgs(1),
 	Aliases: []string{"r", "continue"},
 	RunE:    runResume,
 }
 
-func runResume(cmd *cobra.Command, args []string) error {
-	id := args[0]
+var (
+	resumeJSON  bool
+	resumePrint bool
+)
+
+func init() {
+	resumeCmd.Flags().BoolVar(&resumeJSON, "json", false, "Print the resolved session as JSON instead of launching claude")
+	resumeCmd.Flags().BoolVar(&resumePrint, "print", false, "Print the claude command instead of launching it")
+}
 
+// resumeTarget is a conversation resolved for resuming.
+type resumeTarget struct {
+	SessionID   string   `json:"session_id"`
+	ShortID     string   `json:"short_id"`
+	ProjectPath string   `json:"project_path"`
+	Command     []string `json:"command"`
+}
+
+// resolveResume resolves id to the session and command used to resume it.
+func resolveResume(id string) (*resumeTarget, error) {
 	// Find the conversation to get the full session ID
 	path, err := findConversationFile(id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Load conversation to get the session ID
 	conv, err := history.LoadConversation(path)
 	if err != nil {
-		return fmt.Errorf("loading conversation: %w", err)
-	}
-
-	// Can't resume agent conversations directly
-	if conv.Meta.IsAgent {
-		return fmt.Errorf("cannot resume agent conversations directly; resume the parent conversation instead")
+		return nil, fmt.Errorf("loading conversation: %w", err)
 	}
 
 	sessionID := conv.Meta.SessionID
@@ -48,21 +63,47 @@ func runResume(cmd *cobra.Command, args []string) error {
 		sessionID = conv.Meta.ID
 	}
 
+	// Agents can't be resumed on their own; resume the session that spawned them
+	if conv.Meta.IsAgent {
+		if conv.Meta.ParentSessionID == "" {
+			return nil, fmt.Errorf("cannot resume agent %s: its parent conversation is unknown", id)
+		}
+		sessionID = conv.Meta.ParentSessionID
+	}
+
+	return &resumeTarget{
+		SessionID:   sessionID,
+		ShortID:     history.ShortID(sessionID),
+		ProjectPath: conv.Meta.ProjectPath,
+		Command:     []string{cfg.ClaudeBin, "--resume", sessionID},
+	}, nil
+}
+
+func runResume(cmd *cobra.Command, args []string) error {
+	target, err := resolveResume(args[0])
+	if err != nil {
+		return err
+	}
+
+	if resumeJSON || resumePrint {
+		return printResume(os.Stdout, target)
+	}
+
 	// Change to the project directory
-	if conv.Meta.ProjectPath != "" {
-		if err := os.Chdir(conv.Meta.ProjectPath); err != nil {
+	if target.ProjectPath != "" {
+		if err := os.Chdir(target.ProjectPath); err != nil {
 			// Not fatal - try to resume anyway
 			fmt.Fprintf(os.Stderr, "Warning: could not change to project directory: %v\n", err)
 		}
 	}
 
 	// Execute claude with --resume
-	claudeCmd := exec.Command(cfg.ClaudeBin, "--resume", sessionID)
+	claudeCmd := exec.Command(target.Command[0], target.Command[1:]...)
 	claudeCmd.Stdin = os.Stdin
 	claudeCmd.Stdout = os.Stdout
 	claudeCmd.Stderr = os.Stderr
 
-	fmt.Printf("Resuming conversation %s...\n", history.ShortID(sessionID))
+	fmt.Printf("Resuming conversation %s...\n", target.ShortID)
 
 	if err := claudeCmd.Run(); err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
@@ -74,44 +115,26 @@ func runResume(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// findSessionID finds the full session ID for a given short or full ID.
-func findSessionID(id string) (string, error) {
-	// Remove agent- prefix if present
-	id = strings.TrimPrefix(id, "agent-")
-
-	// Search in all projects
-	projects, err := history.ListProjects(cfg.ProjectsDir)
-	if err != nil {
-		return "", fmt.Errorf("listing projects: %w", err)
+// printResume writes target as JSON with --json, otherwise as a command
+// line that can be pasted into a shell.
+func printResume(out io.Writer, target *resumeTarget) error {
+	if resumeJSON {
+		encoder := display.NewJSONEncoder(out, jsonCompact)
+		return encoder.Encode(target)
 	}
-
-	for _, project := range projects {
-		entries, err := os.ReadDir(project.Dir)
-		if err != nil {
-			continue
-		}
-
-		for _, entry := range entries {
-			if entry.IsDir() {
-				continue
-			}
-
-			name := entry.Name()
-			if !strings.HasSuffix(name, ".jsonl") {
-				continue
-			}
-
-			// Skip agent files
-			if strings.HasPrefix(name, "agent-") {
-				continue
-			}
-
-			baseName := strings.TrimSuffix(name, ".jsonl")
-			if baseName == id || strings.HasPrefix(baseName, id) {
-				return baseName, nil
-			}
-		}
+	quoted := make([]string, len(target.Command))
+	for i, arg := range target.Command {
+		quoted[i] = shellQuote(arg)
 	}
+	_, err := fmt.Fprintln(out, strings.Join(quoted, " "))
+	return err
+}
 
-	return "", fmt.Errorf("conversation not found: %s", id)
+// shellQuote quotes s for a POSIX shell. Words made only of characters the
+// shell treats literally are left as they are.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding/json"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"claude", "claude"},
+		{"/usr/local/bin/claude", "/usr/local/bin/claude"},
+		{"--resume", "--resume"},
+		{"", "''"},
+		{"/opt/my tools/claude", "'/opt/my tools/claude'"},
+		{"it's", `'it'\''s'`},
+		{"$HOME", "'$HOME'"},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// useResumeFlags resets --json and --print after the test.
+func useResumeFlags(t *testing.T) {
+	t.Helper()
+	oldJSON, oldPrint := resumeJSON, resumePrint
+	t.Cleanup(func() { resumeJSON, resumePrint = oldJSON, oldPrint })
+}
+
+func TestPrintResume(t *testing.T) {
+	useShowProjects(t)
+	useResumeFlags(t)
+	cfg.ClaudeBin = "/opt/my tools/claude"
+
+	target, err := resolveResume("conv-1")
+	if err != nil {
+		t.Fatalf("resolveResume() error = %v", err)
+	}
+
+	t.Run("print", func(t *testing.T) {
+		resumeJSON, resumePrint = false, true
+		var out strings.Builder
+		if err := printResume(&out, target); err != nil {
+			t.Fatalf("printResume() error = %v", err)
+		}
+		if want := "'/opt/my tools/claude' --resume conv-1\n"; out.String() != want {
+			t.Errorf("printResume() = %q, want %q", out.String(), want)
+		}
+
+		// A shell splits the printed line back into the same arguments
+		if runtime.GOOS == "windows" {
+			t.Skip("needs a POSIX shell")
+		}
+		split, err := exec.Command("sh", "-c", `printf '%s\n' `+out.String()).Output()
+		if err != nil {
+			t.Skipf("no shell: %v", err)
+		}
+		if got := strings.Split(strings.TrimSuffix(string(split), "\n"), "\n"); !slices.Equal(got, target.Command) {
+			t.Errorf("shell split the line into %q, want %q", got, target.Command)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		resumeJSON, resumePrint = true, false
+		var out strings.Builder
+		if err := printResume(&out, target); err != nil {
+			t.Fatalf("printResume() error = %v", err)
+		}
+		var got resumeTarget
+		if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+		}
+		if got.SessionID != "conv-1" || got.ShortID == "" || got.ProjectPath != "/test/project" ||
+			!slices.Equal(got.Command, []string{"/opt/my tools/claude", "--resume", "conv-1"}) {
+			t.Errorf("printResume() JSON = %+v", got)
+		}
+	})
+}
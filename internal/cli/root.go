@@ -2,8 +2,15 @@
 package cli
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
 	"github.com/dmora/ch/internal/config"
 	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/history"
 	"github.com/spf13/cobra"
 )
 
@@ -13,11 +20,40 @@ var (
 
 	// cfg is the global configuration.
 	cfg *config.Config
+
+	// Global flags
+	colorMode   string
+	noColor     bool
+	workers     int
+	timeFmt     string
+	projectsDir string
+	jsonCompact bool
+	jsonTime    string
+	quiet       bool
 )
 
-// Execute runs the root command.
+// Execute runs the root command. The command context is canceled on SIGINT
+// so long scans and searches can stop cleanly. The handler is released after
+// the first signal, so a second Ctrl-C kills a command that is not watching
+// the context.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return rootCmd.ExecuteContext(ctx)
+}
+
+// warnIfCanceled reports an interrupted scan on stderr so partial results can
+// still be rendered. Errors other than history.ErrCanceled are returned unchanged.
+func warnIfCanceled(err error) error {
+	if !errors.Is(err, history.ErrCanceled) {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%s Interrupted; showing partial results.\n\n", display.Warning("Warning:"))
+	return nil
 }
 
 var rootCmd = &cobra.Command{
@@ -34,17 +70,54 @@ Examples:
   ch agents abc123           # List agents spawned by a conversation
   ch projects                # List all projects
   ch stats                   # Show usage statistics`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Arguments parsed fine; runtime errors shouldn't print usage
+		cmd.SilenceUsage = true
+
 		// Load configuration
 		cfg = config.Load()
 
-		// Set up colors
-		display.DisableColorIfNotTTY()
+		// --projects-dir overrides config and CLAUDE_PROJECTS_DIR
+		if projectsDir != "" {
+			cfg.ProjectsDir = projectsDir
+		}
+
+		// --workers overrides config, including sync.workers, and CH_WORKERS
+		if cmd.Flags().Changed("workers") {
+			if workers <= 0 {
+				return fmt.Errorf("--workers must be positive")
+			}
+			cfg.SetWorkers(workers)
+		}
+
+		if !display.ValidJSONTimeLayout(jsonTime) {
+			return fmt.Errorf("invalid --json-time %q (must be rfc3339, unix, unixmilli, or a Go layout like 2006-01-02)", jsonTime)
+		}
+
+		// Set up colors (--no-color wins over --color)
+		mode := colorMode
+		if noColor {
+			mode = display.ColorNever
+		}
+		return display.ApplyColorMode(mode)
 	},
 	Version: Version,
+
+	// main prints errors and maps them to exit codes
+	SilenceErrors: true,
 }
 
 func init() {
+	// Global flags
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", display.ColorAuto, "Color output: auto, always, or never")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable color output (same as --color=never)")
+	rootCmd.PersistentFlags().StringVar(&timeFmt, "time", "", "Time display: relative, absolute, or a Go layout (default: relative in lists, absolute in headers)")
+	rootCmd.PersistentFlags().StringVar(&projectsDir, "projects-dir", "", "Claude projects directory to read (default: ~/.claude/projects, or CLAUDE_PROJECTS_DIR)")
+	rootCmd.PersistentFlags().IntVar(&workers, "workers", 0, "Number of parallel workers, sync included (default: number of CPUs, or CH_WORKERS)")
+	rootCmd.PersistentFlags().BoolVar(&jsonCompact, "compact", false, "Emit --json output on a single line instead of indented")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Don't show progress on stderr during long scans, searches, and syncs")
+	rootCmd.PersistentFlags().StringVar(&jsonTime, "json-time", display.TimeLayoutRFC3339, "Timestamp layout in --json output: rfc3339, unix, unixmilli, or a Go layout")
+
 	// Add subcommands
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(showCmd)
@@ -54,4 +127,11 @@ func init() {
 	rootCmd.AddCommand(projectsCmd)
 	rootCmd.AddCommand(statsCmd)
 	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(tagCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(pickCmd)
+	rootCmd.AddCommand(openCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(dedupeCmd)
+	rootCmd.AddCommand(indexCmd)
 }
@@ -1,8 +1,11 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/dmora/ch/internal/display"
 	"github.com/dmora/ch/internal/history"
@@ -15,7 +18,9 @@ var searchCmd = &cobra.Command{
 	Long: `Search for text across all conversations.
 
 Searches through all message content (user and assistant messages).
-By default, searches in the current directory's project.`,
+By default, searches in the current directory's project. If a search index
+has been built with 'ch index build', conversations that can't contain the
+query are skipped without being read.`,
 	Args:    cobra.MinimumNArgs(1),
 	Aliases: []string{"grep", "find"},
 	RunE:    runSearch,
@@ -28,7 +33,16 @@ var (
 	searchCaseSensitive bool
 	searchJSON          bool
 	searchAgents        bool
+	searchAgentType     string
 	searchShowIndices   bool
+	searchCount         bool
+	searchSort          string
+	searchFilesOnly     bool
+	searchStat          bool
+	searchGroup         bool
+	searchMaxSize       byteSize
+	searchNoIndex       bool
+	searchPreviews      int
 )
 
 func init() {
@@ -38,10 +52,35 @@ func init() {
 	searchCmd.Flags().BoolVarP(&searchCaseSensitive, "case-sensitive", "c", false, "Case-sensitive search")
 	searchCmd.Flags().BoolVar(&searchJSON, "json", false, "Output as JSON")
 	searchCmd.Flags().BoolVarP(&searchAgents, "agents", "a", true, "Include agent conversations (default: true)")
+	searchCmd.Flags().StringVar(&searchAgentType, "agent-type", "", "Only search agents of this subagent type (e.g. Explore)")
 	searchCmd.Flags().BoolVar(&searchShowIndices, "show-indices", false, "Show message indices in output")
+	searchCmd.Flags().StringVar(&searchSort, "sort", history.SearchSortMatches, "Sort results by: matches or time")
+	searchCmd.Flags().BoolVar(&searchCount, "count", false, "Only print match counts per conversation (id, count, project)")
+	searchCmd.Flags().IntVar(&searchPreviews, "previews", history.DefaultMaxPreviews, "Number of match previews to show per conversation (0 = none)")
+	searchCmd.Flags().Var(&searchMaxSize, "max-size", "Skip files larger than this size (e.g. 200M; default: no limit)")
+	searchCmd.Flags().BoolVar(&searchStat, "stat", false, "Print a summary line (files scanned, matches, elapsed time) after the results")
+	searchCmd.Flags().BoolVar(&searchGroup, "group", false, "Group results by project, busiest project first")
+	searchCmd.Flags().BoolVar(&searchNoIndex, "no-index", false, "Scan every file even if a search index has been built")
+	searchCmd.Flags().BoolVarP(&searchFilesOnly, "files-only", "l", false, "Only print paths of matching conversation files")
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
+	if searchSort != history.SearchSortMatches && searchSort != history.SearchSortTime {
+		return fmt.Errorf("invalid --sort %q: must be %s or %s", searchSort, history.SearchSortMatches, history.SearchSortTime)
+	}
+	if searchPreviews < 0 {
+		return fmt.Errorf("--previews must not be negative")
+	}
+	if searchAgentType != "" && !searchAgents {
+		return fmt.Errorf("--agent-type requires agents to be included (drop --agents=false)")
+	}
+	if searchFilesOnly && (searchJSON || searchCount || searchStat) {
+		return fmt.Errorf("--files-only cannot be combined with --json, --count, or --stat")
+	}
+	if searchStat && (searchJSON || searchCount) {
+		return fmt.Errorf("--stat cannot be combined with --json or --count")
+	}
+
 	query := args[0]
 	if len(args) > 1 {
 		// Join multiple args with space
@@ -55,6 +94,12 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		IncludeAgents: searchAgents,
 		Limit:         searchLimit,
 		CaseSensitive: searchCaseSensitive,
+		Workers:       cfg.Workers,
+		CountOnly:     searchCount,
+		MaxPreviews:   searchPreviews,
+		SortBy:        searchSort,
+		MaxFileSize:   int64(searchMaxSize),
+		AgentType:     searchAgentType,
 	}
 
 	// Determine project filter
@@ -65,12 +110,7 @@ func runSearch(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("resolving project: %w", err)
 		}
 		if len(ambiguous) > 0 {
-			// Multiple matches - show them to user
-			fmt.Fprintf(os.Stdout, "%s\n\n", display.Dim(fmt.Sprintf("Multiple projects match '%s':", searchProject)))
-			for i, p := range ambiguous {
-				fmt.Fprintf(os.Stdout, "  %d. %s\n", i+1, p.Path)
-			}
-			fmt.Fprintf(os.Stdout, "\n%s\n", display.Dim("Please use a more specific project path or name."))
+			printAmbiguousProjects(searchProject, ambiguous)
 			return nil
 		}
 		opts.ProjectPath = resolvedPath
@@ -85,8 +125,21 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		opts.ProjectPath = cwd
 	}
 
+	if !searchNoIndex {
+		index, closeIndex, err := openSearchIndex()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s search index unavailable: %v\n", display.Warning("Warning:"), err)
+		}
+		defer closeIndex()
+		opts.Index = index
+	}
+
+	if searchFilesOnly {
+		return runSearchFilesOnly(cmd.Context(), os.Stdout, query, opts)
+	}
+
 	// Show search context
-	if !searchJSON {
+	if !searchJSON && !searchCount {
 		scope := "current project"
 		if searchGlobal {
 			scope = "all projects"
@@ -96,18 +149,63 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stdout, "%s \"%s\" %s\n\n", display.Dim("Searching for"), display.Match(query), display.Dim("in "+scope+"..."))
 	}
 
-	results, err := history.Search(query, opts)
-	if err != nil {
+	var stopProgress func()
+	opts.Progress, stopProgress = startProgress("Searching")
+	start := time.Now()
+	results, summary, err := history.SearchWithSummary(cmd.Context(), query, opts)
+	stopProgress()
+	if err := warnIfCanceled(err); err != nil {
 		return fmt.Errorf("searching: %w", err)
 	}
 
 	// Render results
 	table := display.NewSearchResultTable(display.TableOptions{
-		Writer:      os.Stdout,
-		JSON:        searchJSON,
-		ShowIndices: searchShowIndices,
-		Query:       query,
+		Writer:         os.Stdout,
+		JSON:           searchJSON,
+		Compact:        jsonCompact,
+		ShowIndices:    searchShowIndices,
+		CountOnly:      searchCount,
+		Query:          query,
+		TotalMatched:   summary.TotalMatched,
+		ShowStat:       searchStat,
+		FilesScanned:   summary.FilesScanned,
+		TotalMatches:   summary.TotalMatches,
+		Elapsed:        time.Since(start),
+		GroupByProject: searchGroup,
 	})
 
-	return table.Render(results)
+	if err := table.Render(results); err != nil {
+		return err
+	}
+	warnSkippedLarge(summary.FilesSkipped, searchMaxSize)
+	if len(results) == 0 {
+		return errEmpty()
+	}
+	return nil
+}
+
+// runSearchFilesOnly prints the path of each matching conversation file, one
+// per line, using the cheaper QuickSearch instead of a full search.
+func runSearchFilesOnly(ctx context.Context, out io.Writer, query string, opts history.SearchOptions) error {
+	metas, err := history.QuickSearch(ctx, query, opts)
+	if err := warnIfCanceled(err); err != nil {
+		return fmt.Errorf("searching: %w", err)
+	}
+
+	for _, meta := range metas {
+		fmt.Fprintln(out, meta.Path)
+	}
+	if len(metas) == 0 {
+		return errEmpty()
+	}
+	return nil
+}
+
+// printAmbiguousProjects lists the projects matching an ambiguous project query.
+func printAmbiguousProjects(query string, matches []*history.Project) {
+	fmt.Fprintf(os.Stdout, "%s\n\n", display.Dim(fmt.Sprintf("Multiple projects match '%s':", query)))
+	for i, p := range matches {
+		fmt.Fprintf(os.Stdout, "  %d. %s\n", i+1, p.Path)
+	}
+	fmt.Fprintf(os.Stdout, "\n%s\n", display.Dim("Please use a more specific project path or name."))
 }
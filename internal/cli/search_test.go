@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dmora/ch/internal/history"
+	"github.com/spf13/cobra"
+)
+
+// searchProjects writes conversations mentioning docker and kubernetes
+// under a temp projects dir and returns search options for all of them.
+func searchProjects(t *testing.T) (history.SearchOptions, map[string]string) {
+	t.Helper()
+	projectsDir := t.TempDir()
+	project := filepath.Join(projectsDir, "-test-project")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	paths := make(map[string]string)
+	for id, text := range map[string]string{"conv-1": "deploy with docker", "conv-2": "scale kubernetes", "conv-3": "Docker compose"} {
+		content := `{"type":"user","uuid":"u1","sessionId":"` + id + `","message":{"role":"user","content":"` + text + `"}}` + "\n"
+		paths[id] = filepath.Join(project, id+".jsonl")
+		if err := os.WriteFile(paths[id], []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return history.SearchOptions{ProjectsDir: projectsDir, IncludeAgents: true, Limit: 20}, paths
+}
+
+func TestRunSearchFilesOnly(t *testing.T) {
+	opts, paths := searchProjects(t)
+
+	var out strings.Builder
+	if err := runSearchFilesOnly(context.Background(), &out, "docker", opts); err != nil {
+		t.Fatalf("runSearchFilesOnly() error = %v", err)
+	}
+	if strings.Count(out.String(), "\n") != 2 || !strings.Contains(out.String(), paths["conv-1"]+"\n") || !strings.Contains(out.String(), paths["conv-3"]+"\n") {
+		t.Errorf("runSearchFilesOnly() = %q, want the paths of conv-1 and conv-3", out.String())
+	}
+
+	out.Reset()
+	err := runSearchFilesOnly(context.Background(), &out, "terraform", opts)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != ExitCodeEmpty || out.Len() != 0 {
+		t.Errorf("runSearchFilesOnly(no match) = %q, %v; want no output and the empty exit code", out.String(), err)
+	}
+}
+
+func TestRunSearch_StatConflicts(t *testing.T) {
+	oldJSON, oldCount, oldStat := searchJSON, searchCount, searchStat
+	t.Cleanup(func() { searchJSON, searchCount, searchStat = oldJSON, oldCount, oldStat })
+
+	for _, flag := range []*bool{&searchJSON, &searchCount} {
+		searchJSON, searchCount, searchStat = false, false, true
+		*flag = true
+		err := runSearch(&cobra.Command{}, []string{"docker"})
+		if err == nil || !strings.Contains(err.Error(), "--stat cannot be combined") {
+			t.Errorf("runSearch() error = %v, want --stat conflict", err)
+		}
+	}
+}
+
+func TestRunSearch_FilesOnlyConflicts(t *testing.T) {
+	oldJSON, oldCount, oldStat, oldFilesOnly := searchJSON, searchCount, searchStat, searchFilesOnly
+	t.Cleanup(func() {
+		searchJSON, searchCount, searchStat, searchFilesOnly = oldJSON, oldCount, oldStat, oldFilesOnly
+	})
+
+	for _, flag := range []*bool{&searchJSON, &searchCount, &searchStat} {
+		searchJSON, searchCount, searchStat, searchFilesOnly = false, false, false, true
+		*flag = true
+		err := runSearch(&cobra.Command{}, []string{"docker"})
+		if err == nil || !strings.Contains(err.Error(), "--files-only cannot be combined") {
+			t.Errorf("runSearch() error = %v, want --files-only conflict", err)
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -9,19 +14,26 @@ import (
 
 	"github.com/dmora/ch/internal/display"
 	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/jsonl"
 	"github.com/spf13/cobra"
 )
 
 var showCmd = &cobra.Command{
-	Use:   "show <id>",
+	Use:   "show [id... | -]",
 	Short: "Show a specific conversation",
 	Long: `Show the contents of a specific conversation.
 
 The id can be:
   - A full session UUID (e.g., 9dbf1107-d255-4d17-a544-aadb594fc786)
   - A short ID (e.g., 9dbf1107)
-  - An agent ID (e.g., agent-d0e14239 or just d0e14239)`,
-	Args:    cobra.ExactArgs(1),
+  - An agent ID (e.g., agent-d0e14239 or just d0e14239)
+  - "-" to read a conversation from stdin
+
+Multiple ids show each conversation in turn, separated by a rule
+(a JSON array with --json). Pagination flags apply to each conversation.
+
+Use --file to show a JSONL file at an explicit path instead of looking it up by ID.`,
+	Args:    cobra.ArbitraryArgs,
 	Aliases: []string{"s", "view"},
 	RunE:    runShow,
 }
@@ -31,6 +43,7 @@ var (
 	showTools      bool
 	showJSON       bool
 	showRaw        bool
+	showPretty     bool
 	showPrompt     bool
 	showResult     bool
 	showFirst      int
@@ -42,6 +55,30 @@ var (
 	showFitTokens  int
 	showAfterIndex int
 	showLimit      int
+	showAfterUUID  string
+	showBeforeUUID string
+	showNew        bool
+	showCollapse   bool
+	showOutput     string
+	showFullTools  bool
+	showToolLimit  int
+	showTool       string
+	showFile       string
+	showVerbose    bool
+	showErrorsOnly bool
+	showASCII      bool
+	showSepWidth   int
+	showBrief      bool
+	showMetadata   bool
+	showQueue      bool
+	showTokens     bool
+	showReverse    bool
+	showNoSystem   bool
+	showNoHeader   bool
+	showNoFooter   bool
+	showNoPager    bool
+	showRawBytes   bool
+	showCount      bool
 )
 
 func init() {
@@ -49,6 +86,14 @@ func init() {
 	showCmd.Flags().BoolVar(&showTools, "tools", true, "Include tool calls (default: true)")
 	showCmd.Flags().BoolVar(&showJSON, "json", false, "Output as JSON")
 	showCmd.Flags().BoolVar(&showRaw, "raw", false, "Output raw JSONL")
+	showCmd.Flags().BoolVar(&showPretty, "pretty", false, "With --raw, indent each JSON line for reading")
+	showCmd.Flags().BoolVar(&showNoSystem, "no-system", false, "Hide system messages (excluded from indices and counts)")
+	showCmd.Flags().BoolVar(&showNoHeader, "no-header", false, "Omit the metadata header (default when output is not a terminal)")
+	showCmd.Flags().BoolVar(&showNoFooter, "no-footer", false, "Omit the resume/agents footer (default when output is not a terminal)")
+	showCmd.Flags().BoolVar(&showMetadata, "metadata", false, "Show entry metadata (type, UUIDs, timestamp, sidechain) without message bodies")
+	showCmd.Flags().BoolVar(&showTokens, "tokens", false, "Show recorded token usage (input incl. cache, output) next to assistant messages")
+	showCmd.Flags().BoolVar(&showCount, "count", false, "Only print entry counts by type and the file size, without rendering the conversation")
+	showCmd.Flags().BoolVar(&showQueue, "show-queue", false, "With --metadata, include queue-operation entries (operation and queued prompt)")
 	showCmd.Flags().BoolVar(&showPrompt, "prompt", false, "Show only the prompt that spawned this agent (agents only)")
 	showCmd.Flags().BoolVar(&showResult, "result", false, "Show only the final result from this agent (agents only)")
 
@@ -57,13 +102,30 @@ func init() {
 	showCmd.Flags().IntVar(&showLast, "last", 0, "Show last N messages")
 	showCmd.Flags().StringVar(&showRange, "range", "", "Show messages in range X-Y (1-based)")
 	showCmd.Flags().BoolVar(&showSummary, "summary", false, "Show only summary entries")
+	showCmd.Flags().BoolVar(&showBrief, "brief", false, "Show only the first user message and the final assistant message")
 
 	// Agent UX flags
 	showCmd.Flags().BoolVar(&showNumbered, "numbered", false, "Show message indices [N] prefix")
 	showCmd.Flags().StringVar(&showRole, "role", "", "Filter by role: user, assistant, or system")
 	showCmd.Flags().IntVar(&showFitTokens, "fit-tokens", 0, "Auto-select messages to fit token budget")
 	showCmd.Flags().IntVar(&showAfterIndex, "after-index", 0, "Start after message N (cursor pagination)")
-	showCmd.Flags().IntVar(&showLimit, "limit", 0, "Max messages to show (with --after-index)")
+	showCmd.Flags().IntVar(&showLimit, "limit", 0, "Show at most N messages: the first N alone, or a page with --after-index, --after, or --before")
+	showCmd.Flags().StringVar(&showAfterUUID, "after", "", "Show messages after the message with this UUID (stable cursor)")
+	showCmd.Flags().StringVar(&showBeforeUUID, "before", "", "Show messages before the message with this UUID (stable cursor)")
+	showCmd.Flags().BoolVar(&showNew, "new", false, "Show only messages after the last one you viewed on a terminal (bookmark)")
+	showCmd.Flags().BoolVar(&showReverse, "reverse", false, "Show messages newest first (indices keep their original numbers)")
+	showCmd.Flags().BoolVar(&showCollapse, "collapse", false, "Merge partial streaming chunks of the same assistant message")
+	showCmd.Flags().StringVar(&showOutput, "output", "", "Write output to a file (color disabled)")
+	showCmd.Flags().BoolVar(&showNoPager, "no-pager", false, "Don't page output taller than the terminal through $PAGER")
+	showCmd.Flags().BoolVar(&showFullTools, "full-tools", false, "Show tool inputs and results without truncation")
+	showCmd.Flags().IntVar(&showToolLimit, "tool-limit", 0, "Truncate tool inputs and results to N characters")
+	showCmd.Flags().StringVar(&showTool, "tool", "", "Only show messages that call this tool (e.g. Bash)")
+	showCmd.Flags().BoolVar(&showErrorsOnly, "errors-only", false, "Only show messages with a failed tool result")
+	showCmd.Flags().StringVar(&showFile, "file", "", "Show a conversation from a JSONL file path")
+	showCmd.Flags().BoolVar(&showASCII, "ascii", false, "Draw separators with ASCII characters (default: on when the locale is not UTF-8)")
+	showCmd.Flags().IntVar(&showSepWidth, "separator-width", display.DefaultSeparatorWidth, "Width of separator lines in characters")
+	showCmd.Flags().BoolVar(&showRawBytes, "raw-bytes", false, "Print control characters and escape sequences in message content as-is instead of marking them (marking is on for terminals)")
+	showCmd.Flags().BoolVarP(&showVerbose, "verbose", "v", false, "Show the raw JSON of unrecognized content blocks")
 }
 
 // FileSizeWarningThreshold is the size (5MB) above which we warn about large files.
@@ -78,10 +140,17 @@ func validatePaginationFlags() error {
 		{"--first/--last", showFirst > 0 || showLast > 0},
 		{"--range", showRange != ""},
 		{"--fit-tokens", showFitTokens > 0},
-		{"--after-index/--limit", showAfterIndex > 0 || showLimit > 0},
+		{"--after-index", showAfterIndex > 0},
+		{"--limit", showLimit > 0 && showAfterIndex == 0 && !hasUUIDCursor() && !showNew},
+		{"--after/--before", hasUUIDCursor()},
+		{"--new", showNew},
 		{"--summary", showSummary},
+		{"--brief", showBrief},
+		{"--metadata", showMetadata},
 		{"--prompt", showPrompt},
 		{"--result", showResult},
+		{"--tool", showTool != ""},
+		{"--count", showCount},
 	}
 
 	setCount := 0
@@ -97,6 +166,33 @@ func validatePaginationFlags() error {
 		return fmt.Errorf("flags %s are mutually exclusive", strings.Join(setNames, ", "))
 	}
 
+	if showAfterUUID != "" && showBeforeUUID != "" {
+		return fmt.Errorf("flags --after and --before are mutually exclusive")
+	}
+
+	if showRaw && showMetadata {
+		return fmt.Errorf("flags --raw and --metadata are mutually exclusive")
+	}
+	if showCount && showRaw {
+		return fmt.Errorf("flags --count and --raw are mutually exclusive")
+	}
+	if showQueue && !showMetadata {
+		return fmt.Errorf("--show-queue requires --metadata")
+	}
+	if showPretty && !showRaw {
+		return fmt.Errorf("--pretty requires --raw")
+	}
+
+	if showFullTools && showToolLimit > 0 {
+		return fmt.Errorf("flags --full-tools and --tool-limit are mutually exclusive")
+	}
+	if showToolLimit < 0 {
+		return fmt.Errorf("--tool-limit must be positive")
+	}
+	if showSepWidth < 1 {
+		return fmt.Errorf("--separator-width must be at least 1")
+	}
+
 	// Validate role filter
 	if showRole != "" {
 		validRoles := map[string]bool{"user": true, "assistant": true, "system": true}
@@ -108,6 +204,11 @@ func validatePaginationFlags() error {
 	return nil
 }
 
+// hasUUIDCursor returns true if --after or --before is set.
+func hasUUIDCursor() bool {
+	return showAfterUUID != "" || showBeforeUUID != ""
+}
+
 // parseRange parses a range string "X-Y" into start and end indices (1-based).
 func parseRange(rangeStr string) (start, end int, err error) {
 	parts := strings.Split(rangeStr, "-")
@@ -138,68 +239,151 @@ func checkFileSizeWarning(path string) {
 		return
 	}
 
-	hasPagination := showFirst > 0 || showLast > 0 || showRange != "" || showSummary || showPrompt || showResult ||
-		showFitTokens > 0 || showAfterIndex > 0 || showLimit > 0
+	hasPagination := showFirst > 0 || showLast > 0 || showRange != "" || showSummary || showBrief || showPrompt || showResult ||
+		showFitTokens > 0 || showAfterIndex > 0 || showLimit > 0 || hasUUIDCursor() || showNew
 
 	if info.Size() > FileSizeWarningThreshold && !hasPagination && !showJSON && !showRaw {
-		fmt.Fprintf(os.Stderr, "%s Large file (%s). Consider using --first, --last, --range, --fit-tokens, or --after-index for better performance.\n\n",
+		fmt.Fprintf(os.Stderr, "%s Large file (%s). Consider using --first, --last, --range, --fit-tokens, or --after-index for better performance (--count shows its size by type).\n\n",
 			display.Warning("Warning:"),
 			display.FormatBytes(info.Size()))
 	}
 }
 
 // showSummaries displays only summary entries from a conversation.
-func showSummaries(conv *history.Conversation) error {
+func showSummaries(w io.Writer, conv *history.Conversation) error {
 	summaries := conv.GetSummaries()
 
 	if len(summaries) == 0 {
-		fmt.Println(display.Dim("No summary entries found in this conversation"))
+		fmt.Fprintln(w, display.Dim("No summary entries found in this conversation"))
 		return nil
 	}
 
-	fmt.Printf("\n%s %s\n", display.Title("Summaries"), display.ID(conv.Meta.ID))
-	fmt.Printf("%s %d\n", display.Dim("Found:"), len(summaries))
-	fmt.Println(strings.Repeat("─", 60))
+	fmt.Fprintf(w, "\n%s %s\n", display.Title("Summaries"), display.ID(conv.Meta.ID))
+	fmt.Fprintf(w, "%s %d\n", display.Dim("Found:"), len(summaries))
+	fmt.Fprintln(w, display.Separator(showSepWidth, showASCII))
 
 	for i, entry := range summaries {
 		if i > 0 {
-			fmt.Println()
+			fmt.Fprintln(w)
 		}
-		fmt.Printf("\n%s %d\n", display.Section("Summary"), i+1)
+		fmt.Fprintf(w, "\n%s %d\n", display.Section("Summary"), i+1)
 		if entry.Timestamp != "" {
-			fmt.Printf("%s %s\n", display.Dim("Time:"), display.Timestamp(entry.Timestamp))
+			fmt.Fprintf(w, "%s %s\n", display.Dim("Time:"), display.Timestamp(entry.Timestamp))
 		}
 		if entry.Summary != "" {
-			fmt.Println(entry.Summary)
+			fmt.Fprintln(w, entry.Summary)
 		}
 	}
 
 	return nil
 }
 
-func runShow(cmd *cobra.Command, args []string) error {
-	id := args[0]
-
+func runShow(cmd *cobra.Command, args []string) (err error) {
 	if err := validatePaginationFlags(); err != nil {
 		return err
 	}
+	if showCount {
+		return runShowCount(args)
+	}
+	applyChromeDefaults(cmd)
+	if len(args) > 1 {
+		return runShowMany(cmd.Context(), args)
+	}
+
+	conv, path, err := loadShowConversation(cmd.Context(), args)
+	if err != nil {
+		return err
+	}
 
-	path, err := findConversationFile(id)
+	out, closeOutput, err := openOutput(showOutput)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if cerr := closeOutput(); err == nil {
+			err = cerr
+		}
+	}()
 
-	checkFileSizeWarning(path)
+	return renderPaged(out, usePager(), func(w io.Writer) error {
+		return renderShow(w, conv, path)
+	})
+}
+
+// runShowMany shows several conversations in sequence. Text output separates
+// them with a rule; JSON output is an array of the per-conversation objects.
+func runShowMany(ctx context.Context, ids []string) (err error) {
+	if showFile != "" {
+		return fmt.Errorf("cannot use --file with a conversation id")
+	}
+	for _, id := range ids {
+		if id == "-" {
+			return fmt.Errorf("cannot read stdin (\"-\") together with other ids")
+		}
+	}
 
-	conv, err := history.LoadConversation(path)
+	out, closeOutput, err := openOutput(showOutput)
 	if err != nil {
-		return fmt.Errorf("loading conversation: %w", err)
+		return err
 	}
+	defer func() {
+		if cerr := closeOutput(); err == nil {
+			err = cerr
+		}
+	}()
+
+	return renderPaged(out, usePager(), func(w io.Writer) error {
+		return renderShowMany(ctx, w, ids)
+	})
+}
+
+// renderShowMany renders each conversation in turn, or collects them into a
+// JSON array.
+func renderShowMany(ctx context.Context, out io.Writer, ids []string) error {
+	jsonArray := showJSON && !showRaw && !showSummary && !showPrompt && !showResult
+	var objects []json.RawMessage
 
-	if err := handleSpecialModes(conv, path); err != nil {
+	for i, id := range ids {
+		conv, path, err := loadShowConversation(ctx, []string{id})
+		if err != nil {
+			return err
+		}
+
+		if jsonArray {
+			var buf bytes.Buffer
+			if err := renderShow(&buf, conv, path); err != nil {
+				return err
+			}
+			// Keep one element per id even when a conversation renders nothing
+			object := json.RawMessage(bytes.TrimSpace(buf.Bytes()))
+			if len(object) == 0 {
+				object = json.RawMessage("null")
+			}
+			objects = append(objects, object)
+			continue
+		}
+
+		if i > 0 && !showRaw {
+			fmt.Fprintf(out, "\n%s\n", display.Dim(display.DoubleSeparator(showSepWidth, showASCII)))
+		}
+		if err := renderShow(out, conv, path); err != nil {
+			return err
+		}
+	}
+
+	if jsonArray {
+		encoder := display.NewJSONEncoder(out, jsonCompact)
+		return encoder.Encode(objects)
+	}
+	return nil
+}
+
+// renderShow renders a loaded conversation according to the show flags.
+func renderShow(out io.Writer, conv *history.Conversation, path string) error {
+	if err := handleSpecialModes(out, conv, path); err != nil {
 		return err
 	}
-	if showPrompt || showResult || showSummary {
+	if showPrompt || showResult || showSummary || showBrief {
 		return nil // Special mode handled
 	}
 
@@ -207,41 +391,164 @@ func runShow(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if showNew {
+		paginationOpts = bookmarkPagination(conv, paginationOpts)
+	}
 
 	agentCount := countAgentsIfMain(conv, path)
+	toolResultMaxLen, toolInputMaxLen := toolTruncationLimits()
 
 	disp := display.NewConversationDisplay(display.ConversationDisplayOptions{
-		Writer:        os.Stdout,
-		ShowThinking:  showThinking,
-		ShowTools:     showTools,
-		ShowNumbering: showNumbered,
-		RoleFilter:    showRole,
-		JSON:          showJSON,
-		Raw:           showRaw,
-		AgentCount:    agentCount,
-		Pagination:    paginationOpts,
+		Writer:            out,
+		ShowThinking:      showThinking,
+		ShowTools:         showTools,
+		HideSystem:        showNoSystem,
+		HideHeader:        showNoHeader,
+		HideFooter:        showNoFooter,
+		ShowNumbering:     showNumbered,
+		RoleFilter:        showRole,
+		JSON:              showJSON,
+		Compact:           jsonCompact,
+		Raw:               showRaw,
+		Pretty:            showPretty,
+		Metadata:          showMetadata,
+		ShowQueue:         showQueue,
+		ShowTokens:        showTokens,
+		AgentCount:        agentCount,
+		Pagination:        paginationOpts,
+		CollapseStreaming: showCollapse,
+		SanitizeOutput:    sanitizeShowOutput(),
+		ToolResultMaxLen:  toolResultMaxLen,
+		ToolInputMaxLen:   toolInputMaxLen,
+		ToolFilter:        showTool,
+		ErrorsOnly:        showErrorsOnly,
+		TimeFormat:        timeFmt,
+		TimeLayout:        jsonTime,
+		Reverse:           showReverse,
+		Verbose:           showVerbose,
+		ASCII:             showASCII,
+		SeparatorWidth:    showSepWidth,
 	})
+	if err := disp.Render(conv); err != nil {
+		return err
+	}
+	if path != "" && updatesBookmark() {
+		saveBookmark(conv, disp)
+	}
+	return nil
+}
 
-	return disp.Render(conv)
+// usePager reports whether show output goes through the pager: only for
+// formatted output to a terminal, never for --json, --raw, or --output.
+func usePager() bool {
+	return !showNoPager && !showJSON && !showRaw && showOutput == "" && display.IsTTY()
+}
+
+// applyChromeDefaults hides the header and footer when output is not a
+// terminal, so piped output contains only messages, and falls back to ASCII
+// separators when the locale isn't UTF-8. Explicit --no-header, --no-footer,
+// and --ascii values are kept.
+func applyChromeDefaults(cmd *cobra.Command) {
+	notTTY := showOutput != "" || !display.IsTTY()
+	if !cmd.Flags().Changed("no-header") {
+		showNoHeader = notTTY
+	}
+	if !cmd.Flags().Changed("no-footer") {
+		showNoFooter = notTTY
+	}
+	if !cmd.Flags().Changed("ascii") {
+		showASCII = display.PrefersASCII()
+	}
+}
+
+// loadShowConversation loads the conversation named by args or --file.
+// The returned path is empty when the conversation was read from stdin.
+func loadShowConversation(ctx context.Context, args []string) (*history.Conversation, string, error) {
+	if showFile != "" && len(args) > 0 {
+		return nil, "", fmt.Errorf("cannot use --file with a conversation id")
+	}
+	if showFile == "" && len(args) == 0 {
+		return nil, "", fmt.Errorf("requires a conversation id, \"-\" for stdin, or --file")
+	}
+
+	if showFile == "" && args[0] == "-" {
+		conv, err := loadStdinConversation(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("reading conversation from stdin: %w", err)
+		}
+		return conv, "", nil
+	}
+
+	path := showFile
+	var meta *history.ConversationMeta
+	if path == "" {
+		var err error
+		if meta, err = resolveConversation(args[0]); err != nil {
+			return nil, "", err
+		}
+		path = meta.Path
+	} else if _, err := os.Stat(path); err != nil {
+		return nil, "", errNotFound("conversation file not found: %s", path)
+	}
+
+	checkFileSizeWarning(path)
+
+	var conv *history.Conversation
+	var err error
+	if meta != nil {
+		// Resolving the ID already scanned the metadata; only the entries are left
+		conv, err = history.LoadConversationWithMeta(meta)
+	} else {
+		conv, err = history.LoadConversation(path)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("loading conversation: %w", err)
+	}
+	return conv, path, nil
+}
+
+// loadStdinConversation reads a conversation from stdin. The read itself
+// cannot be interrupted, so it runs in the background and Ctrl-C returns
+// as soon as the command context is canceled.
+func loadStdinConversation(ctx context.Context) (*history.Conversation, error) {
+	type loaded struct {
+		conv *history.Conversation
+		err  error
+	}
+	done := make(chan loaded, 1)
+	go func() {
+		conv, err := history.LoadConversationFromReader(os.Stdin, "stdin")
+		done <- loaded{conv, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.conv, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 // handleSpecialModes handles --prompt, --result, and --summary flags.
 // Returns nil if handled, error if failed, or continues if not applicable.
-func handleSpecialModes(conv *history.Conversation, path string) error {
+func handleSpecialModes(w io.Writer, conv *history.Conversation, path string) error {
 	if showPrompt {
 		if !conv.Meta.IsAgent {
 			return fmt.Errorf("--prompt flag only works for agent conversations")
 		}
-		return showAgentPrompt(conv, path)
+		return showAgentPrompt(w, conv, path)
 	}
 	if showResult {
 		if !conv.Meta.IsAgent {
 			return fmt.Errorf("--result flag only works for agent conversations")
 		}
-		return showAgentResult(conv)
+		return showAgentResult(w, conv)
 	}
 	if showSummary {
-		return showSummaries(conv)
+		return showSummaries(w, conv)
+	}
+	if showBrief {
+		return showBriefView(w, conv)
 	}
 	return nil
 }
@@ -250,7 +557,11 @@ func handleSpecialModes(conv *history.Conversation, path string) error {
 func buildPaginationOpts() (display.PaginationOptions, error) {
 	var opts display.PaginationOptions
 
-	if showAfterIndex > 0 || showLimit > 0 {
+	if hasUUIDCursor() {
+		opts.AfterUUID = showAfterUUID
+		opts.BeforeUUID = showBeforeUUID
+		opts.Limit = showLimit
+	} else if showAfterIndex > 0 || showLimit > 0 {
 		opts.AfterIndex = showAfterIndex
 		opts.Limit = showLimit
 	} else if showFitTokens > 0 {
@@ -269,18 +580,117 @@ func buildPaginationOpts() (display.PaginationOptions, error) {
 	return opts, nil
 }
 
+// toolTruncationLimits returns the tool result and input limits from flags.
+func toolTruncationLimits() (resultMax, inputMax int) {
+	switch {
+	case showFullTools:
+		return 0, 0
+	case showToolLimit > 0:
+		return showToolLimit, showToolLimit
+	default:
+		return display.DefaultToolResultMaxLen, display.DefaultToolInputMaxLen
+	}
+}
+
 // countAgentsIfMain returns agent count for main conversations, 0 for agents.
 func countAgentsIfMain(conv *history.Conversation, path string) int {
-	if conv.Meta.IsAgent {
+	if conv.Meta.IsAgent || path == "" {
 		return 0
 	}
 	projectDir := filepath.Dir(path)
-	scanner := history.NewScanner(history.ScannerOptions{ProjectsDir: cfg.ProjectsDir})
+	scanner := history.NewScanner(history.ScannerOptions{ProjectsDir: cfg.ProjectsDir, Workers: cfg.Workers})
 	return scanner.CountAgents(projectDir, conv.Meta.SessionID)
 }
 
+// briefMessage is a single message in the --brief view.
+type briefMessage struct {
+	Timestamp string
+	Text      string
+}
+
+// briefMessageJSON is the --brief --json form of a briefMessage.
+type briefMessageJSON struct {
+	Timestamp any    `json:"timestamp,omitempty"`
+	Text      string `json:"text"`
+}
+
+// toJSON converts msg for --json output, formatting its timestamp with
+// --json-time. A nil message stays nil.
+func (msg *briefMessage) toJSON() *briefMessageJSON {
+	if msg == nil {
+		return nil
+	}
+	return &briefMessageJSON{Timestamp: display.JSONEntryTime(msg.Timestamp, jsonTime), Text: msg.Text}
+}
+
+// showBriefView displays the first user message and the final assistant message.
+func showBriefView(w io.Writer, conv *history.Conversation) error {
+	first := firstMessageText(conv.GetUserMessages(), false)
+	last := firstMessageText(conv.GetAssistantMessages(), true)
+
+	if showJSON {
+		encoder := display.NewJSONEncoder(w, jsonCompact)
+		return encoder.Encode(struct {
+			ID            string            `json:"id"`
+			Project       string            `json:"project,omitempty"`
+			MessageCount  int               `json:"message_count"`
+			FirstUser     *briefMessageJSON `json:"first_user,omitempty"`
+			LastAssistant *briefMessageJSON `json:"last_assistant,omitempty"`
+		}{
+			ID:            conv.Meta.ID,
+			Project:       conv.Meta.ProjectPath,
+			MessageCount:  conv.Meta.MessageCount,
+			FirstUser:     first.toJSON(),
+			LastAssistant: last.toJSON(),
+		})
+	}
+
+	fmt.Fprintf(w, "\n%s %s\n", display.Title("Brief"), display.ID(conv.Meta.ID))
+	fmt.Fprintf(w, "%s %s\n", display.Dim("Messages:"), display.Number(fmt.Sprintf("%d", conv.Meta.MessageCount)))
+	fmt.Fprintln(w, display.Separator(showSepWidth, showASCII))
+
+	printBriefMessage(w, display.UserRole("User"), first)
+	printBriefMessage(w, display.AssistantRole("Assistant"), last)
+	return nil
+}
+
+// printBriefMessage prints one --brief section, or a placeholder if msg is nil.
+func printBriefMessage(w io.Writer, label string, msg *briefMessage) {
+	fmt.Fprintf(w, "\n%s", label)
+	if msg == nil {
+		fmt.Fprintf(w, "\n%s\n", display.Dim("(no message found)"))
+		return
+	}
+	if msg.Timestamp != "" {
+		fmt.Fprintf(w, "  %s", display.Timestamp(msg.Timestamp))
+	}
+	fmt.Fprintf(w, "\n%s\n", msg.Text)
+}
+
+// firstMessageText returns the first entry with text content, scanning from the
+// end when reverse is set. Entries without text (e.g. tool results) are skipped.
+func firstMessageText(entries []*jsonl.RawEntry, reverse bool) *briefMessage {
+	for i := range entries {
+		entry := entries[i]
+		if reverse {
+			entry = entries[len(entries)-1-i]
+		}
+		msg, err := history.ParseMessageEntry(entry)
+		if err != nil {
+			continue
+		}
+		if text := strings.TrimSpace(history.ExtractMessageText(msg)); text != "" {
+			return &briefMessage{Timestamp: entry.Timestamp, Text: text}
+		}
+	}
+	return nil
+}
+
 // showAgentPrompt displays the prompt that was used to spawn an agent.
-func showAgentPrompt(conv *history.Conversation, agentPath string) error {
+func showAgentPrompt(w io.Writer, conv *history.Conversation, agentPath string) error {
+	if agentPath == "" {
+		return fmt.Errorf("--prompt requires the agent's project directory; not available when reading from stdin")
+	}
 	projectDir := filepath.Dir(agentPath)
 	parentSessionID := conv.Meta.ParentSessionID
 	if parentSessionID == "" {
@@ -288,7 +698,7 @@ func showAgentPrompt(conv *history.Conversation, agentPath string) error {
 	}
 
 	// Find parent conversation file
-	parentPath := filepath.Join(projectDir, parentSessionID+".jsonl")
+	parentPath := history.ConversationFilePath(projectDir, parentSessionID)
 
 	// Check if parent exists
 	if _, err := os.Stat(parentPath); os.IsNotExist(err) {
@@ -306,25 +716,40 @@ func showAgentPrompt(conv *history.Conversation, agentPath string) error {
 	}
 
 	// Display prompt
-	fmt.Fprintf(os.Stdout, "\n%s %s\n", display.Title("Agent Prompt"), display.ID("agent-"+conv.Meta.ID))
+	fmt.Fprintf(w, "\n%s %s\n", display.Title("Agent Prompt"), display.ID("agent-"+conv.Meta.ID))
 	if info.SubagentType != "" {
-		fmt.Fprintf(os.Stdout, "%s %s\n", display.Dim("Type:"), display.Match(info.SubagentType))
+		fmt.Fprintf(w, "%s %s\n", display.Dim("Type:"), display.Match(info.SubagentType))
 	}
 	if info.Description != "" {
-		fmt.Fprintf(os.Stdout, "%s %s\n", display.Dim("Description:"), info.Description)
+		fmt.Fprintf(w, "%s %s\n", display.Dim("Description:"), info.Description)
 	}
-	fmt.Fprintf(os.Stdout, "\n%s\n", display.Section("Prompt:"))
+	if note := agentMatchNote(info); note != "" {
+		fmt.Fprintf(w, "%s %s\n", display.Dim("Match:"), display.Warning(note))
+	}
+	fmt.Fprintf(w, "\n%s\n", display.Section("Prompt:"))
 	if info.Prompt != "" {
-		fmt.Fprintln(os.Stdout, info.Prompt)
+		fmt.Fprintln(w, sanitizeShowText(info.Prompt))
 	} else {
-		fmt.Fprintln(os.Stdout, display.Dim("(no prompt found)"))
+		fmt.Fprintln(w, display.Dim("(no prompt found)"))
 	}
 
 	return nil
 }
 
+// agentMatchNote explains how confident the Task call lookup is, or returns
+// "" when the call was matched by its tool ID.
+func agentMatchNote(info *history.AgentInfo) string {
+	switch info.Match {
+	case history.AgentMatchPrompt:
+		return "by prompt (no Task call ID references this agent)"
+	case history.AgentMatchOrder:
+		return "best effort, by order (the Nth Task call for the Nth agent); the prompt may belong to another agent"
+	}
+	return ""
+}
+
 // showAgentResult displays the final result from an agent.
-func showAgentResult(conv *history.Conversation) error {
+func showAgentResult(w io.Writer, conv *history.Conversation) error {
 	assistantMsgs := conv.GetAssistantMessages()
 	if len(assistantMsgs) == 0 {
 		return fmt.Errorf("no assistant messages found in agent conversation")
@@ -341,69 +766,55 @@ func showAgentResult(conv *history.Conversation) error {
 	text := history.ExtractMessageText(msg)
 
 	// Display result
-	fmt.Fprintf(os.Stdout, "\n%s %s\n", display.Title("Agent Result"), display.ID("agent-"+conv.Meta.ID))
-	fmt.Fprintf(os.Stdout, "%s %s\n\n", display.Dim("Messages:"), display.Number(fmt.Sprintf("%d", conv.Meta.MessageCount)))
+	fmt.Fprintf(w, "\n%s %s\n", display.Title("Agent Result"), display.ID("agent-"+conv.Meta.ID))
+	fmt.Fprintf(w, "%s %s\n\n", display.Dim("Messages:"), display.Number(fmt.Sprintf("%d", conv.Meta.MessageCount)))
 
 	if text != "" {
-		fmt.Fprintln(os.Stdout, text)
+		fmt.Fprintln(w, sanitizeShowText(text))
 	} else {
-		fmt.Fprintln(os.Stdout, display.Dim("(no text content in final response)"))
+		fmt.Fprintln(w, display.Dim("(no text content in final response)"))
 	}
 
 	return nil
 }
 
-// findConversationFile finds a conversation file by ID.
-func findConversationFile(id string) (string, error) {
-	// Check if it's an agent ID
-	isAgent := strings.HasPrefix(id, "agent-")
-	if isAgent {
-		id = strings.TrimPrefix(id, "agent-")
-	}
+// sanitizeShowOutput reports whether message content should have its control
+// characters marked: when printing to a terminal, unless --raw-bytes is set.
+func sanitizeShowOutput() bool {
+	return !showRawBytes && showOutput == "" && display.IsTTY()
+}
 
-	// Search in all projects
-	projects, err := history.ListProjects(cfg.ProjectsDir)
-	if err != nil {
-		return "", fmt.Errorf("listing projects: %w", err)
+// sanitizeShowText marks control characters in s when sanitizeShowOutput
+// is on.
+func sanitizeShowText(s string) string {
+	if !sanitizeShowOutput() {
+		return s
 	}
+	return jsonl.SanitizeForTerminal(s)
+}
 
-	for _, project := range projects {
-		entries, err := os.ReadDir(project.Dir)
-		if err != nil {
-			continue
-		}
-
-		for _, entry := range entries {
-			if entry.IsDir() {
-				continue
-			}
-
-			name := entry.Name()
-			if !strings.HasSuffix(name, ".jsonl") {
-				continue
-			}
+// newClient returns a history client for the configured projects directory.
+func newClient() *history.Client {
+	return history.NewClient(history.ClientOptions{
+		ProjectsDir: cfg.ProjectsDir,
+		Workers:     cfg.Workers,
+	})
+}
 
-			// Check for match
-			if isAgent {
-				// Look for agent-{id}.jsonl
-				if name == fmt.Sprintf("agent-%s.jsonl", id) {
-					return filepath.Join(project.Dir, name), nil
-				}
-				// Partial match
-				if strings.HasPrefix(name, "agent-") && strings.Contains(name, id) {
-					return filepath.Join(project.Dir, name), nil
-				}
-			} else {
-				// Look for {id}.jsonl or partial match
-				baseName := strings.TrimSuffix(name, ".jsonl")
-				if !strings.HasPrefix(baseName, "agent-") {
-					if baseName == id || strings.HasPrefix(baseName, id) {
-						return filepath.Join(project.Dir, name), nil
-					}
-				}
-			}
-		}
+// findConversationFile finds a conversation file by ID.
+func findConversationFile(id string) (string, error) {
+	meta, err := resolveConversation(id)
+	if err != nil {
+		return "", err
 	}
+	return meta.Path, nil
+}
 
-	return "", fmt.Errorf("conversation not found: %s", id)
+// resolveConversation finds a conversation by ID and returns its metadata.
+func resolveConversation(id string) (*history.ConversationMeta, error) {
+	meta, err := newClient().Resolve(id)
+	if errors.Is(err, history.ErrNotFound) {
+		return nil, &ExitError{Code: ExitCodeNotFound, Err: err}
+	}
+	return meta, err
 }
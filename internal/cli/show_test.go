@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dmora/ch/internal/config"
+	"github.com/dmora/ch/internal/display"
+)
+
+// useShowProjects points cfg at a projects dir holding conv-1 and conv-2,
+// and resets the show flags the tests set.
+func useShowProjects(t *testing.T) {
+	t.Helper()
+	oldCfg, oldJSON, oldCompact := cfg, showJSON, jsonCompact
+	t.Cleanup(func() { cfg, showJSON, jsonCompact = oldCfg, oldJSON, oldCompact })
+	display.SetColorEnabled(false)
+	t.Cleanup(func() { display.SetColorEnabled(true) })
+
+	projectsDir := t.TempDir()
+	cfg = &config.Config{ProjectsDir: projectsDir, Workers: 1}
+	project := filepath.Join(projectsDir, "-test-project")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"conv-1", "conv-2"} {
+		content := strings.ReplaceAll(bookmarkConversation, "conv-1", id)
+		content = strings.ReplaceAll(content, `"first"`, `"first in `+id+`"`)
+		if err := os.WriteFile(filepath.Join(project, id+".jsonl"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRenderShowMany_Text(t *testing.T) {
+	useShowProjects(t)
+
+	var out strings.Builder
+	if err := renderShowMany(context.Background(), &out, []string{"conv-2", "conv-1"}); err != nil {
+		t.Fatalf("renderShowMany() error = %v", err)
+	}
+	got := out.String()
+	second, first := strings.Index(got, "first in conv-2"), strings.Index(got, "first in conv-1")
+	if second < 0 || first < 0 || second > first {
+		t.Errorf("output = %q, want conv-2 then conv-1", got)
+	}
+	if rule := display.DoubleSeparator(0, showASCII); !strings.Contains(got, rule) {
+		t.Errorf("output = %q, want the conversations separated by %q", got, rule)
+	}
+}
+
+func TestRenderShowMany_SeparatorWidth(t *testing.T) {
+	useShowProjects(t)
+	oldWidth := showSepWidth
+	t.Cleanup(func() { showSepWidth = oldWidth })
+	showSepWidth = 20
+
+	var out strings.Builder
+	if err := renderShowMany(context.Background(), &out, []string{"conv-1", "conv-2"}); err != nil {
+		t.Fatalf("renderShowMany() error = %v", err)
+	}
+	rule := display.DoubleSeparator(20, showASCII)
+	if !strings.Contains(out.String(), "\n"+rule+"\n") {
+		t.Errorf("output = %q, want a %d-character rule between conversations", out.String(), 20)
+	}
+}
+
+func TestRenderShowMany_JSON(t *testing.T) {
+	useShowProjects(t)
+	showJSON = true
+
+	var out strings.Builder
+	if err := renderShowMany(context.Background(), &out, []string{"conv-1", "conv-2"}); err != nil {
+		t.Fatalf("renderShowMany() error = %v", err)
+	}
+	var got []json.RawMessage
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d objects, want 2", len(got))
+	}
+	for i, id := range []string{"conv-1", "conv-2"} {
+		if !strings.Contains(string(got[i]), "first in "+id) {
+			t.Errorf("object %d = %s, want %s", i, got[i], id)
+		}
+	}
+}
+
+func TestRenderShowMany_NotFound(t *testing.T) {
+	useShowProjects(t)
+
+	var out strings.Builder
+	if err := renderShowMany(context.Background(), &out, []string{"conv-1", "missing"}); err == nil {
+		t.Error("renderShowMany() error = nil, want not found")
+	}
+}
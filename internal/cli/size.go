@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dmora/ch/internal/display"
+)
+
+// byteSize is a flag value holding a size in bytes. It accepts plain byte
+// counts or a K, M, or G suffix (powers of 1024, optional trailing "B").
+type byteSize int64
+
+func (b *byteSize) String() string {
+	return strconv.FormatInt(int64(*b), 10)
+}
+
+func (b *byteSize) Type() string {
+	return "size"
+}
+
+func (b *byteSize) Set(s string) error {
+	v := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	multiplier := int64(1)
+	if n := len(v); n > 0 {
+		switch v[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			v = v[:n-1]
+		}
+	}
+
+	// ParseFloat accepts NaN and Inf, and float64(math.MaxInt64) rounds up
+	// to 2^63, which doesn't fit in an int64
+	n, err := strconv.ParseFloat(v, 64)
+	size := n * float64(multiplier)
+	if err != nil || math.IsNaN(size) || size < 0 || size >= math.MaxInt64 {
+		return fmt.Errorf("invalid size %q (e.g. 500K, 200M, 1G)", s)
+	}
+	*b = byteSize(size)
+	return nil
+}
+
+// warnSkippedLarge notes on stderr how many files --max-size skipped, so the
+// results on stdout stay machine-readable.
+func warnSkippedLarge(skipped int, maxSize byteSize) {
+	if skipped == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s\n", display.Dim(fmt.Sprintf("Skipped %d files larger than %s (--max-size)",
+		skipped, display.FormatBytes(int64(maxSize)))))
+}
@@ -0,0 +1,39 @@
+package cli
+
+import "testing"
+
+func TestByteSize_Set(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"0", 0},
+		{"1024", 1024},
+		{"100B", 100},
+		{"500K", 500 << 10},
+		{"500kb", 500 << 10},
+		{"200M", 200 << 20},
+		{" 200MB ", 200 << 20},
+		{"1G", 1 << 30},
+		{"1.5G", 3 << 29},
+		{"0.5K", 512},
+		{"8000000000G", 8000000000 << 30},
+	}
+	for _, tt := range tests {
+		var b byteSize
+		if err := b.Set(tt.in); err != nil {
+			t.Errorf("Set(%q) error = %v", tt.in, err)
+			continue
+		}
+		if int64(b) != tt.want {
+			t.Errorf("Set(%q) = %d, want %d", tt.in, b, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "K", "abc", "-1", "-5M", "10X", "NaN", "Inf", "-Inf", "infG", "1e400", "9223372036854775807", "9000000000G"} {
+		var b byteSize
+		if err := b.Set(in); err == nil {
+			t.Errorf("Set(%q) = %d, want an error", in, b)
+		}
+	}
+}
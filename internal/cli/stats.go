@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -21,22 +24,50 @@ var statsCmd = &cobra.Command{
 }
 
 var (
-	statsJSON   bool
-	statsTokens string
+	statsJSON    bool
+	statsCSV     bool
+	statsTokens  string
+	statsTools   bool
+	statsProject string
+	statsDump    bool
+	statsActive  time.Duration
 )
 
 func init() {
 	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Output as JSON")
+	statsCmd.Flags().BoolVar(&statsCSV, "csv", false, "Output aggregate stats as metric,value CSV")
 	statsCmd.Flags().StringVar(&statsTokens, "tokens", "", "Estimate token count for a conversation ID")
+	statsCmd.Flags().BoolVar(&statsTools, "tools", false, "Include tool usage counts (parses all assistant messages)")
+	statsCmd.Flags().BoolVar(&statsDump, "dump", false, "Emit one JSON object per conversation (JSONL) for analysis")
+	statsCmd.Flags().DurationVar(&statsActive, "active", 0, "Only count projects with a conversation within this duration (e.g. 168h)")
+	statsCmd.Flags().StringVarP(&statsProject, "project", "p", "", "Show detailed stats for a single project (supports partial names)")
 }
 
 func runStats(cmd *cobra.Command, args []string) error {
+	if statsJSON && statsCSV {
+		return fmt.Errorf("--json and --csv are mutually exclusive")
+	}
+	if statsCSV && (statsTokens != "" || statsDump || statsProject != "") {
+		return fmt.Errorf("--csv cannot be combined with --tokens, --dump, or --project")
+	}
+	if statsActive < 0 {
+		return fmt.Errorf("--active cannot be negative")
+	}
+	if statsActive > 0 && (statsTokens != "" || statsDump || statsProject != "") {
+		return fmt.Errorf("--active cannot be combined with --tokens, --dump, or --project")
+	}
 	// Handle --tokens flag
 	if statsTokens != "" {
 		return runTokenEstimate(statsTokens)
 	}
+	if statsDump {
+		return runStatsDump(cmd.Context())
+	}
+	if statsProject != "" {
+		return runProjectStats(statsProject)
+	}
 
-	projects, err := history.ListProjects(cfg.ProjectsDir)
+	projects, err := history.ListProjectsWithWorkers(cfg.ProjectsDir, cfg.Workers)
 	if err != nil {
 		return err
 	}
@@ -47,19 +78,29 @@ func runStats(cmd *cobra.Command, args []string) error {
 
 	var oldest, newest time.Time
 
-	for _, p := range projects {
-		stats.ConversationCount += p.ConversationCount
-		stats.AgentCount += p.AgentCount
-		stats.TotalSize += p.TotalSize
-	}
-
 	// Scan for message counts and timestamps
 	scanner := history.NewScanner(history.ScannerOptions{
 		ProjectsDir:   cfg.ProjectsDir,
 		IncludeAgents: true,
+		Workers:       cfg.Workers,
 	})
 
-	conversations, err := scanner.ScanAll()
+	conversations, err := scanner.ScanAll(cmd.Context())
+	if errors.Is(err, history.ErrCanceled) {
+		return fmt.Errorf("scanning conversations: %w", err)
+	}
+
+	if statsActive > 0 {
+		projects, conversations = history.FilterActive(projects, conversations, time.Now().Add(-statsActive))
+		stats.Active = &display.ActiveStats{Window: statsActive.String(), ProjectCount: len(projects)}
+	}
+
+	for _, p := range projects {
+		stats.ConversationCount += p.ConversationCount
+		stats.AgentCount += p.AgentCount
+		stats.TotalSize += p.TotalSize
+	}
+
 	if err == nil {
 		for _, c := range conversations {
 			stats.TotalMessages += c.MessageCount
@@ -72,14 +113,102 @@ func runStats(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if !oldest.IsZero() {
-		stats.OldestConversation = oldest.Format("2006-01-02 15:04")
+	// Tool usage requires parsing every assistant message, so it's opt-in
+	if statsTools {
+		paths := make([]string, len(conversations))
+		for i, c := range conversations {
+			paths[i] = c.Path
+		}
+		stats.ToolUsage = history.ToolUsage(paths, cfg.Workers)
+	}
+
+	stats.OldestConversation = oldest
+	stats.NewestConversation = newest
+
+	if statsCSV {
+		return display.RenderStatsCSV(os.Stdout, stats)
+	}
+	return display.RenderStats(os.Stdout, stats, statsJSON, jsonCompact, jsonTime)
+}
+
+// statsDumpRow is one line of stats --dump output.
+type statsDumpRow struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id,omitempty"`
+	Project   string `json:"project"`
+	IsAgent   bool   `json:"is_agent"`
+	Model     string `json:"model,omitempty"`
+	Messages  int    `json:"messages"`
+	Size      int64  `json:"size"`
+	Timestamp any    `json:"timestamp,omitempty"`
+	Path      string `json:"path"`
+}
+
+// runStatsDump writes every conversation's metadata as JSONL, one row per
+// line, so the output can be streamed into other tools. Rows are written as
+// conversations are scanned, in no particular order, so memory stays flat
+// however many conversations there are.
+func runStatsDump(ctx context.Context) error {
+	scanner := history.NewScanner(history.ScannerOptions{
+		ProjectsDir:   cfg.ProjectsDir,
+		IncludeAgents: true,
+		Workers:       cfg.Workers,
+	})
+
+	w := bufio.NewWriter(os.Stdout)
+	encoder := json.NewEncoder(w)
+	err := scanner.Walk(ctx, func(c *history.ConversationMeta) error {
+		row := statsDumpRow{
+			ID:        c.ID,
+			SessionID: c.SessionID,
+			Project:   c.ProjectPath,
+			IsAgent:   c.IsAgent,
+			Model:     c.Model,
+			Messages:  c.MessageCount,
+			Size:      c.FileSize,
+			Path:      c.Path,
+		}
+		if !c.Timestamp.IsZero() {
+			row.Timestamp = display.JSONTime(c.Timestamp, jsonTime)
+		}
+		return encoder.Encode(row)
+	})
+
+	// Deliver the rows written before an interruption
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	if errors.Is(err, history.ErrCanceled) {
+		return fmt.Errorf("scanning conversations: %w", err)
+	}
+	return err
+}
+
+// runProjectStats shows detailed statistics for a single project.
+func runProjectStats(query string) error {
+	resolvedPath, ambiguous, err := history.ResolveProjectPath(cfg.ProjectsDir, query)
+	if err != nil {
+		return fmt.Errorf("resolving project: %w", err)
 	}
-	if !newest.IsZero() {
-		stats.NewestConversation = newest.Format("2006-01-02 15:04")
+	if len(ambiguous) > 0 {
+		printAmbiguousProjects(query, ambiguous)
+		return nil
+	}
+
+	project, err := history.FindProject(cfg.ProjectsDir, resolvedPath)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return fmt.Errorf("no conversation history found for project '%s'", resolvedPath)
+	}
+
+	stats, err := history.GetProjectStats(project)
+	if err != nil {
+		return err
 	}
 
-	return display.RenderStats(os.Stdout, stats, statsJSON)
+	return display.RenderProjectStats(os.Stdout, stats, statsJSON, jsonCompact, jsonTime)
 }
 
 // runTokenEstimate estimates token count for a conversation.
@@ -146,16 +275,15 @@ func runTokenEstimate(id string) error {
 			EstimatedTokens: estimatedTokens,
 			FileSize:        conv.Meta.FileSize,
 		}
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
+		encoder := display.NewJSONEncoder(os.Stdout, jsonCompact)
 		return encoder.Encode(output)
 	}
 
 	fmt.Println()
 	fmt.Printf("%s %s\n", display.Title("Token Estimate"), display.ID(conv.Meta.ID))
-	fmt.Printf("%s %s\n", display.Dim("Messages:"), display.Number(fmt.Sprintf("%d", messageCount)))
-	fmt.Printf("%s %s\n", display.Dim("Characters:"), display.Number(fmt.Sprintf("%d", totalChars)))
-	fmt.Printf("%s %s\n", display.Dim("Est. Tokens:"), display.Number(fmt.Sprintf("~%d", estimatedTokens)))
+	fmt.Printf("%s %s\n", display.Dim("Messages:"), display.Number(display.FormatNumber(int64(messageCount))))
+	fmt.Printf("%s %s\n", display.Dim("Characters:"), display.Number(display.FormatNumber(int64(totalChars))))
+	fmt.Printf("%s %s\n", display.Dim("Est. Tokens:"), display.Number("~"+display.FormatNumber(int64(estimatedTokens))))
 	fmt.Printf("%s %s\n", display.Dim("File Size:"), display.FormatBytes(conv.Meta.FileSize))
 	fmt.Println()
 	fmt.Println(display.Dim("Note: Token estimate uses ~4 chars/token heuristic"))
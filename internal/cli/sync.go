@@ -1,13 +1,16 @@
 package cli
 
 import (
-	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/dmora/ch/internal/backend"
 	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/parallel"
 	"github.com/dmora/ch/internal/sync"
 	"github.com/dmora/ch/internal/syncdb"
 	"github.com/spf13/cobra"
@@ -23,10 +26,14 @@ sync state and avoid re-sending already synced messages.
 
 Examples:
   ch sync                    # Sync all conversations
-  ch sync --dry-run          # Show what would be synced
+  ch sync --dry-run          # Preview spans without contacting the backend
   ch sync --verbose          # Show detailed span information
   ch sync --file <path>      # Sync a specific file
-  ch sync status             # Show sync status`,
+  ch sync --since 24h        # Only sync files modified in the last day
+  ch sync --backend console  # Override the configured backend for this run
+  ch sync status             # Show sync status
+  ch sync status --last      # Show what changed in the last sync run
+  ch sync errors             # List recent per-file sync errors`,
 	RunE: runSync,
 }
 
@@ -35,35 +42,64 @@ var (
 	syncVerbose bool
 	syncJSON    bool
 	syncFile    string
+	syncSince   time.Duration
+	syncBackend string
 )
 
 func init() {
-	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show what would be synced without persisting")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Preview which spans would be sent without contacting the backend or persisting")
 	syncCmd.Flags().BoolVarP(&syncVerbose, "verbose", "v", false, "Show detailed span information")
-	syncCmd.Flags().BoolVar(&syncJSON, "json", false, "Output as JSON")
+	syncCmd.Flags().BoolVar(&syncJSON, "json", false, "Output the run summary (with structured errors) as JSON; console backend spans go to stderr as JSON")
 	syncCmd.Flags().StringVar(&syncFile, "file", "", "Sync a specific file")
+	syncCmd.Flags().StringVar(&syncBackend, "backend", "", "Backend to use for this run, overriding the config ("+strings.Join(backend.Names(), ", ")+")")
+	syncCmd.Flags().DurationVar(&syncSince, "since", 0, "Only sync files modified within this duration (e.g. 24h, 90m)")
+
+	syncStatusCmd.Flags().BoolVar(&syncStatusLast, "last", false, "Show a summary of the most recent sync run")
+
+	syncErrorsCmd.Flags().IntVarP(&syncErrorsLimit, "limit", "n", 20, "Maximum number of errors to show (0 for all)")
+	syncErrorsCmd.Flags().BoolVar(&syncErrorsJSON, "json", false, "Output as JSON")
+	syncErrorsCmd.Flags().BoolVar(&syncErrorsClear, "clear", false, "Delete all recorded sync errors")
 
 	// Add subcommands
 	syncCmd.AddCommand(syncStatusCmd)
+	syncCmd.AddCommand(syncErrorsCmd)
 }
 
 func runSync(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	if syncSince < 0 {
+		return fmt.Errorf("--since cannot be negative")
+	}
+	if syncSince > 0 && syncFile != "" {
+		return fmt.Errorf("--since cannot be used with --file")
+	}
+	if syncBackend != "" {
+		if !backend.IsRegistered(syncBackend) {
+			return fmt.Errorf("unknown backend: %s (must be one of: %s)", syncBackend, strings.Join(backend.Names(), ", "))
+		}
+		cfg.Sync.Backend = syncBackend
+	}
 
-	// Create backend based on config
+	ctx := cmd.Context()
+	dryRun := syncDryRun || cfg.Sync.DryRun
+
+	// Dry runs never touch the configured backend; the syncer previews spans instead
 	var be sync.Backend
-	switch cfg.Sync.Backend {
-	case "console", "":
-		be = backend.NewConsoleBackend(backend.ConsoleConfig{
-			Writer:  os.Stdout,
-			Verbose: syncVerbose || cfg.Sync.Console.Verbose,
-			Format:  pickFormat(syncJSON, cfg.Sync.Console.Format),
-			NoColor: !display.IsColorEnabled(),
-		})
-	default:
-		return fmt.Errorf("unknown backend: %s", cfg.Sync.Backend)
-	}
-	defer be.Close()
+	if !dryRun {
+		var err error
+		be, err = newBackend()
+		if err != nil {
+			return err
+		}
+		defer be.Close()
+	}
+
+	// The console backend prints spans as it goes, which a progress line
+	// would garble, so progress is only drawn when nothing else writes.
+	var progress parallel.ProgressFunc
+	stopProgress := func() {}
+	if dryRun || be.Name() != "console" {
+		progress, stopProgress = startProgress("Syncing")
+	}
 
 	// Create syncer
 	syncer, err := sync.NewSyncer(sync.SyncerOptions{
@@ -71,7 +107,10 @@ func runSync(cmd *cobra.Command, args []string) error {
 		Backend:     be,
 		ProjectsDir: cfg.ProjectsDir,
 		Workers:     cfg.Sync.Workers,
-		DryRun:      syncDryRun || cfg.Sync.DryRun,
+		DryRun:      dryRun,
+		Since:       syncSince,
+		Checksum:    cfg.Sync.Checksum,
+		Progress:    progress,
 	})
 	if err != nil {
 		return fmt.Errorf("creating syncer: %w", err)
@@ -94,13 +133,22 @@ func runSync(cmd *cobra.Command, args []string) error {
 	} else {
 		// Full sync
 		result, err = syncer.SyncAll(ctx)
+		stopProgress()
 		if err != nil {
 			return err
 		}
 	}
 
+	if syncJSON {
+		return printJSON(newSyncReport(result, dryRun, syncer.Preview()))
+	}
+
+	if dryRun {
+		printSyncPreview(syncer.Preview())
+	}
+
 	// Print summary
-	printSyncSummary(result, syncDryRun || cfg.Sync.DryRun)
+	printSyncSummary(result, dryRun)
 
 	// Report errors
 	if len(result.Errors) > 0 {
@@ -113,6 +161,85 @@ func runSync(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// syncReport is the JSON form of a sync run, for sync --json.
+type syncReport struct {
+	DryRun        bool                `json:"dry_run"`
+	FilesScanned  int                 `json:"files_scanned"`
+	FilesUpdated  int                 `json:"files_updated"`
+	FilesResynced int                 `json:"files_resynced"`
+	ResyncedFiles []string            `json:"resynced_files"`
+	SpansSynced   int                 `json:"spans_synced"`
+	DurationMS    int64               `json:"duration_ms"`
+	Errors        []syncReportError   `json:"errors"`
+	Preview       []*sync.FilePreview `json:"preview"` // Spans per file; dry runs only
+}
+
+// syncReportError is a sync failure; Path is empty for errors not tied to a file.
+type syncReportError struct {
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// newSyncReport builds the JSON report from a sync result.
+func newSyncReport(result *sync.SyncResult, dryRun bool, preview []*sync.FilePreview) *syncReport {
+	report := &syncReport{
+		DryRun:        dryRun,
+		FilesScanned:  result.FilesScanned,
+		FilesUpdated:  result.FilesUpdated,
+		FilesResynced: result.FilesResynced,
+		ResyncedFiles: result.ResyncedFiles,
+		SpansSynced:   result.SpansSynced,
+		DurationMS:    result.Duration.Milliseconds(),
+		Errors:        []syncReportError{},
+	}
+	if report.ResyncedFiles == nil {
+		report.ResyncedFiles = []string{}
+	}
+	report.Preview = []*sync.FilePreview{}
+	if dryRun && preview != nil {
+		report.Preview = preview
+	}
+
+	for _, err := range result.Errors {
+		var fileErr *sync.FileError
+		if errors.As(err, &fileErr) {
+			report.Errors = append(report.Errors, syncReportError{Path: fileErr.Path, Message: fileErr.Err.Error()})
+			continue
+		}
+		report.Errors = append(report.Errors, syncReportError{Message: err.Error()})
+	}
+	return report
+}
+
+// newBackend creates the sync backend selected in the config, applying the
+// command-line overrides of its settings.
+func newBackend() (sync.Backend, error) {
+	syncCfg := cfg.Sync
+	syncCfg.Console.Verbose = syncVerbose || syncCfg.Console.Verbose
+	syncCfg.Console.Format = pickFormat(syncJSON, syncCfg.Console.Format)
+	// Keep stdout for the report
+	syncCfg.Console.Stderr = syncJSON || syncCfg.Console.Stderr
+	return backend.New(syncCfg.Backend, syncCfg)
+}
+
+// printSyncPreview shows per-file span counts for a dry run.
+func printSyncPreview(files []*sync.FilePreview) {
+	if len(files) == 0 {
+		fmt.Println(display.Dim("No spans would be sent"))
+		return
+	}
+
+	fmt.Printf("%s\n", display.Dim("Spans that would be sent"))
+	for _, f := range files {
+		fmt.Printf("  %s  %s\n", display.Number(fmt.Sprintf("%4d", f.Spans)), f.Path)
+		sample := strings.Join(f.SampleNames, ", ")
+		if f.Spans > len(f.SampleNames) {
+			sample += ", ..."
+		}
+		fmt.Printf("        %s\n", display.Dim(sample))
+	}
+}
+
 func printSyncSummary(result *sync.SyncResult, dryRun bool) {
 	prefix := ""
 	if dryRun {
@@ -124,6 +251,13 @@ func printSyncSummary(result *sync.SyncResult, dryRun bool) {
 	fmt.Printf("  Files updated: %d\n", result.FilesUpdated)
 	fmt.Printf("  Spans synced:  %d\n", result.SpansSynced)
 	fmt.Printf("  Duration:      %s\n", result.Duration.Round(time.Millisecond))
+
+	if result.FilesResynced > 0 {
+		fmt.Printf("\n  %s\n", display.Warning(fmt.Sprintf("%d files resynced due to compaction", result.FilesResynced)))
+		for _, path := range result.ResyncedFiles {
+			fmt.Printf("    %s\n", path)
+		}
+	}
 }
 
 func pickFormat(jsonFlag bool, configFormat string) string {
@@ -141,6 +275,8 @@ var syncStatusCmd = &cobra.Command{
 	RunE:  runSyncStatus,
 }
 
+var syncStatusLast bool
+
 func runSyncStatus(cmd *cobra.Command, args []string) error {
 	// Open database directly for status
 	db, err := syncdb.Open(cfg.Sync.DBPath)
@@ -149,6 +285,10 @@ func runSyncStatus(cmd *cobra.Command, args []string) error {
 	}
 	defer db.Close()
 
+	if syncStatusLast {
+		return printLastRun(db)
+	}
+
 	stats, err := db.Stats()
 	if err != nil {
 		return fmt.Errorf("getting stats: %w", err)
@@ -163,3 +303,118 @@ func runSyncStatus(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// printLastRun shows the summary of the most recent sync run.
+func printLastRun(db *syncdb.DB) error {
+	run, err := db.LastRun()
+	if err != nil {
+		return fmt.Errorf("getting last run: %w", err)
+	}
+	if run == nil {
+		fmt.Println(display.Dim("No sync runs recorded yet. Run 'ch sync' first."))
+		return nil
+	}
+
+	startedAt := time.Unix(run.StartedAt, 0)
+	fmt.Printf("%s\n", display.Dim("Last Sync Run"))
+	fmt.Printf("  Started:       %s\n", startedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Duration:      %s\n", time.Unix(run.FinishedAt, 0).Sub(startedAt))
+	fmt.Printf("  Backend:       %s\n", run.Backend)
+	fmt.Printf("  Files scanned: %d\n", run.FilesScanned)
+	fmt.Printf("  Files updated: %d\n", run.FilesUpdated)
+	fmt.Printf("  Spans synced:  %d\n", run.SpansSynced)
+	fmt.Printf("  Errors:        %d\n", len(run.Errors))
+
+	for _, e := range run.Errors {
+		fmt.Printf("    %s\n", e)
+	}
+
+	return nil
+}
+
+// sync errors subcommand
+var syncErrorsCmd = &cobra.Command{
+	Use:   "errors",
+	Short: "List recent sync errors",
+	Long: `List files that failed to sync, newest first, with the error and when it happened.
+
+Errors are kept until cleared with --clear.`,
+	Args: cobra.NoArgs,
+	RunE: runSyncErrors,
+}
+
+var (
+	syncErrorsLimit int
+	syncErrorsJSON  bool
+	syncErrorsClear bool
+)
+
+// syncErrorJSON is the --json form of a recorded sync error.
+type syncErrorJSON struct {
+	File       string `json:"file"`
+	Message    string `json:"message"`
+	OccurredAt any    `json:"occurred_at"`
+}
+
+func runSyncErrors(cmd *cobra.Command, args []string) error {
+	if syncErrorsLimit < 0 {
+		return fmt.Errorf("--limit cannot be negative")
+	}
+	return syncErrors(os.Stdout)
+}
+
+// syncErrors lists or clears the recorded sync errors. A sync database that
+// doesn't exist yet holds no errors, and is not created.
+func syncErrors(out io.Writer) error {
+	var db *syncdb.DB
+	if _, err := os.Stat(cfg.Sync.DBPath); err == nil {
+		db, err = syncdb.Open(cfg.Sync.DBPath)
+		if err != nil {
+			return fmt.Errorf("opening sync database: %w", err)
+		}
+		defer db.Close()
+	}
+
+	if syncErrorsClear {
+		var n int64
+		if db != nil {
+			var err error
+			if n, err = db.ClearErrors(); err != nil {
+				return fmt.Errorf("clearing sync errors: %w", err)
+			}
+		}
+		fmt.Fprintf(out, "Cleared %d sync error(s)\n", n)
+		return nil
+	}
+
+	var syncErrs []*syncdb.SyncError
+	if db != nil {
+		var err error
+		if syncErrs, err = db.GetErrors(syncErrorsLimit); err != nil {
+			return fmt.Errorf("getting sync errors: %w", err)
+		}
+	}
+
+	if syncErrorsJSON {
+		rows := make([]syncErrorJSON, 0, len(syncErrs))
+		for _, e := range syncErrs {
+			rows = append(rows, syncErrorJSON{
+				File:       e.FilePath,
+				Message:    e.Message,
+				OccurredAt: display.JSONTime(time.Unix(e.OccurredAt, 0), jsonTime),
+			})
+		}
+		return display.NewJSONEncoder(out, jsonCompact).Encode(rows)
+	}
+
+	if len(syncErrs) == 0 {
+		fmt.Fprintln(out, display.Dim("No sync errors recorded."))
+		return nil
+	}
+
+	for _, e := range syncErrs {
+		fmt.Fprintf(out, "%s  %s\n", display.Dim(time.Unix(e.OccurredAt, 0).Format("2006-01-02 15:04:05")), e.FilePath)
+		fmt.Fprintf(out, "    %s\n", e.Message)
+	}
+	return nil
+}
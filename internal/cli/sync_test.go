@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dmora/ch/internal/config"
+	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/sync"
+	"github.com/dmora/ch/internal/syncdb"
+	"github.com/spf13/cobra"
+)
+
+// captureStdout returns what fn writes to os.Stdout.
+func captureStdout(t *testing.T, fn func() error) ([]byte, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = oldStdout }()
+
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+	fnErr := fn()
+	w.Close()
+	return <-done, fnErr
+}
+
+// useSyncProjects points cfg at a projects dir holding one conversation and
+// one unparseable file, with a temp sync database, and sets sync --json.
+func useSyncProjects(t *testing.T) (badPath string) {
+	t.Helper()
+	oldCfg, oldJSON, oldDryRun := cfg, syncJSON, syncDryRun
+	t.Cleanup(func() { cfg, syncJSON, syncDryRun = oldCfg, oldJSON, oldDryRun })
+
+	projectsDir := t.TempDir()
+	cfg = &config.Config{ProjectsDir: projectsDir}
+	cfg.Sync.Backend = "console"
+	cfg.Sync.DBPath = filepath.Join(t.TempDir(), "sync.db")
+	cfg.Sync.Workers = 1
+	syncJSON = true
+
+	project := filepath.Join(projectsDir, "-test-project")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "conv-1.jsonl"), []byte(bookmarkConversation), 0644); err != nil {
+		t.Fatal(err)
+	}
+	badPath = filepath.Join(project, "conv-2.jsonl")
+	if err := os.WriteFile(badPath, []byte("not json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return badPath
+}
+
+// decodeSyncReport decodes out as exactly one JSON object.
+func decodeSyncReport(t *testing.T, out []byte) map[string]any {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(out))
+	var report map[string]any
+	if err := dec.Decode(&report); err != nil {
+		t.Fatalf("stdout is not a JSON object: %v\n%s", err, out)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		t.Fatalf("stdout has more than one JSON value:\n%s", out)
+	}
+	return report
+}
+
+func TestRunSync_JSON(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		badPath := useSyncProjects(t)
+		syncDryRun = dryRun
+
+		cmd := &cobra.Command{}
+		cmd.SetContext(context.Background())
+		out, err := captureStdout(t, func() error { return runSync(cmd, nil) })
+		if err != nil {
+			t.Fatalf("dry run %v: runSync() error = %v", dryRun, err)
+		}
+
+		report := decodeSyncReport(t, out)
+		if report["dry_run"] != dryRun || report["files_scanned"] != float64(2) {
+			t.Errorf("dry run %v: report = %v", dryRun, report)
+		}
+		errs, _ := report["errors"].([]any)
+		if len(errs) != 1 {
+			t.Fatalf("dry run %v: errors = %v, want the unparseable file", dryRun, report["errors"])
+		}
+		if e := errs[0].(map[string]any); e["path"] != badPath || e["message"] == "" {
+			t.Errorf("dry run %v: error = %v, want path %s and a message", dryRun, e, badPath)
+		}
+		preview, _ := report["preview"].([]any)
+		if dryRun != (len(preview) == 1) {
+			t.Errorf("dry run %v: preview = %v", dryRun, report["preview"])
+		}
+	}
+}
+
+func TestNewSyncReport(t *testing.T) {
+	result := &sync.SyncResult{
+		FilesScanned: 3,
+		Errors: []error{
+			&sync.FileError{Path: "/p/conv.jsonl", Err: errors.New("parsing entry: bad")},
+			errors.New("finding files: denied"),
+		},
+	}
+	data, err := json.Marshal(newSyncReport(result, false, nil))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var got struct {
+		FilesScanned  int               `json:"files_scanned"`
+		ResyncedFiles []string          `json:"resynced_files"`
+		Errors        []syncReportError `json:"errors"`
+		Preview       []any             `json:"preview"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := []syncReportError{
+		{Path: "/p/conv.jsonl", Message: "parsing entry: bad"},
+		{Message: "finding files: denied"},
+	}
+	if got.FilesScanned != 3 || len(got.Errors) != 2 || got.Errors[0] != want[0] || got.Errors[1] != want[1] {
+		t.Errorf("report = %s", data)
+	}
+	// Empty lists encode as [] rather than null
+	if got.ResyncedFiles == nil || got.Preview == nil {
+		t.Errorf("report = %s, want empty lists", data)
+	}
+}
+
+// useSyncErrorsDB points the sync database at a temp path and resets the
+// sync errors flags.
+func useSyncErrorsDB(t *testing.T) string {
+	t.Helper()
+	oldCfg, oldJSON, oldClear, oldLimit := cfg, syncErrorsJSON, syncErrorsClear, syncErrorsLimit
+	t.Cleanup(func() { cfg, syncErrorsJSON, syncErrorsClear, syncErrorsLimit = oldCfg, oldJSON, oldClear, oldLimit })
+	display.SetColorEnabled(false)
+	t.Cleanup(func() { display.SetColorEnabled(true) })
+
+	dbPath := filepath.Join(t.TempDir(), "sync.db")
+	cfg = &config.Config{Sync: config.SyncConfig{DBPath: dbPath}}
+	syncErrorsJSON, syncErrorsClear, syncErrorsLimit = false, false, 20
+	return dbPath
+}
+
+func TestSyncErrors_NoDatabase(t *testing.T) {
+	dbPath := useSyncErrorsDB(t)
+
+	tests := []struct {
+		json, clear bool
+		want        string
+	}{
+		{want: "No sync errors recorded.\n"},
+		{json: true, want: "[]\n"},
+		{clear: true, want: "Cleared 0 sync error(s)\n"},
+	}
+	for _, tt := range tests {
+		syncErrorsJSON, syncErrorsClear = tt.json, tt.clear
+		var out strings.Builder
+		if err := syncErrors(&out); err != nil {
+			t.Fatalf("syncErrors(json=%v, clear=%v) error = %v", tt.json, tt.clear, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("syncErrors(json=%v, clear=%v) = %q, want %q", tt.json, tt.clear, out.String(), tt.want)
+		}
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("sync errors created %s (stat error = %v)", dbPath, err)
+	}
+}
+
+func TestSyncErrors(t *testing.T) {
+	dbPath := useSyncErrorsDB(t)
+	db, err := syncdb.Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	db.RecordError("/p/conv.jsonl", "parsing entry: bad")
+	db.Close()
+
+	var out strings.Builder
+	if err := syncErrors(&out); err != nil {
+		t.Fatalf("syncErrors() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "/p/conv.jsonl") || !strings.Contains(out.String(), "parsing entry: bad") {
+		t.Errorf("syncErrors() = %q, want the recorded error", out.String())
+	}
+
+	syncErrorsClear = true
+	out.Reset()
+	if err := syncErrors(&out); err != nil {
+		t.Fatalf("syncErrors(clear) error = %v", err)
+	}
+	if out.String() != "Cleared 1 sync error(s)\n" {
+		t.Errorf("syncErrors(clear) = %q", out.String())
+	}
+}
@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/syncdb"
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Label conversations with tags",
+	Long: `Label conversations with your own tags like "bug", "spike", or "reference".
+
+Tags are stored in the ch database keyed by conversation ID, so they survive
+even if the underlying conversation file is later compacted.
+
+Examples:
+  ch tag add abc123 bug spike    # Add tags to a conversation
+  ch tag remove abc123 spike     # Remove a tag
+  ch tag list abc123             # List tags for a conversation
+  ch tag list                    # List all tags with counts
+  ch list --tag bug              # List conversations tagged "bug"`,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <id> <tag>...",
+	Short: "Add tags to a conversation",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runTagAdd,
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:     "remove <id> <tag>...",
+	Short:   "Remove tags from a conversation",
+	Aliases: []string{"rm"},
+	Args:    cobra.MinimumNArgs(2),
+	RunE:    runTagRemove,
+}
+
+var tagListCmd = &cobra.Command{
+	Use:     "list [id]",
+	Short:   "List tags for a conversation, or all tags",
+	Aliases: []string{"ls"},
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    runTagList,
+}
+
+var tagListJSON bool
+
+func init() {
+	tagListCmd.Flags().BoolVar(&tagListJSON, "json", false, "Output as JSON")
+
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagListCmd)
+}
+
+func runTagAdd(cmd *cobra.Command, args []string) error {
+	id, err := resolveConversationID(args[0])
+	if err != nil {
+		return err
+	}
+	tags, err := normalizeTags(args[1:])
+	if err != nil {
+		return err
+	}
+
+	db, err := syncdb.Open(cfg.Sync.DBPath)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	for _, tag := range tags {
+		if err := db.AddTag(id, tag); err != nil {
+			return fmt.Errorf("adding tag %q: %w", tag, err)
+		}
+	}
+
+	fmt.Printf("Tagged %s: %s\n", display.ID(history.ShortID(id)), strings.Join(tags, ", "))
+	return nil
+}
+
+func runTagRemove(cmd *cobra.Command, args []string) error {
+	id, err := resolveConversationID(args[0])
+	if err != nil {
+		return err
+	}
+	tags, err := normalizeTags(args[1:])
+	if err != nil {
+		return err
+	}
+
+	db, err := syncdb.Open(cfg.Sync.DBPath)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	for _, tag := range tags {
+		if err := db.RemoveTag(id, tag); err != nil {
+			return fmt.Errorf("removing tag %q: %w", tag, err)
+		}
+	}
+
+	fmt.Printf("Untagged %s: %s\n", display.ID(history.ShortID(id)), strings.Join(tags, ", "))
+	return nil
+}
+
+func runTagList(cmd *cobra.Command, args []string) error {
+	db, err := syncdb.Open(cfg.Sync.DBPath)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if len(args) == 1 {
+		id, err := resolveConversationID(args[0])
+		if err != nil {
+			return err
+		}
+		tags, err := db.GetTags(id)
+		if err != nil {
+			return fmt.Errorf("getting tags: %w", err)
+		}
+		if tagListJSON {
+			if tags == nil {
+				tags = []string{}
+			}
+			return printJSON(tags)
+		}
+		if len(tags) == 0 {
+			fmt.Println(display.Dim("No tags"))
+			return nil
+		}
+		for _, tag := range tags {
+			fmt.Println(tag)
+		}
+		return nil
+	}
+
+	counts, err := db.TagCounts()
+	if err != nil {
+		return fmt.Errorf("getting tags: %w", err)
+	}
+	if tagListJSON {
+		output := make(map[string]int, len(counts))
+		for _, tc := range counts {
+			output[tc.Tag] = tc.Count
+		}
+		return printJSON(output)
+	}
+	if len(counts) == 0 {
+		fmt.Println(display.Dim("No tags"))
+		return nil
+	}
+	for _, tc := range counts {
+		fmt.Printf("%s  %s\n", tc.Tag, display.Dim(fmt.Sprintf("(%d)", tc.Count)))
+	}
+	return nil
+}
+
+// resolveConversationID finds a conversation by full or partial ID and returns its full ID.
+func resolveConversationID(id string) (string, error) {
+	path, err := findConversationFile(id)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Base(path)
+	if history.IsAgentFile(name) {
+		return history.ExtractAgentID(name), nil
+	}
+	return history.ExtractSessionID(name), nil
+}
+
+// normalizeTags trims tags and rejects empty ones.
+func normalizeTags(args []string) ([]string, error) {
+	tags := make([]string, 0, len(args))
+	for _, arg := range args {
+		tag := strings.TrimSpace(arg)
+		if tag == "" {
+			return nil, fmt.Errorf("tag cannot be empty")
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// loadTags returns all conversation tags, or nil if no tag database exists yet.
+func loadTags() (map[string][]string, error) {
+	if _, err := os.Stat(cfg.Sync.DBPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	db, err := syncdb.Open(cfg.Sync.DBPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	return db.AllTags()
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v interface{}) error {
+	encoder := display.NewJSONEncoder(os.Stdout, jsonCompact)
+	return encoder.Encode(v)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,6 +19,10 @@ type Config struct {
 	// ClaudeBin is the path to the Claude CLI binary.
 	ClaudeBin string `yaml:"claude_bin"`
 
+	// Workers is the number of parallel workers for scanning, search, and sync.
+	// Defaults to the number of CPUs.
+	Workers int `yaml:"workers"`
+
 	// Sync contains sync-specific configuration.
 	Sync SyncConfig `yaml:"sync"`
 }
@@ -26,18 +32,23 @@ type SyncConfig struct {
 	// Enabled controls whether sync is active.
 	Enabled bool `yaml:"enabled"`
 
-	// Backend specifies the sync backend ("console", "langfuse").
+	// Backend is the name of a backend registered in internal/backend (e.g. "console").
 	Backend string `yaml:"backend"`
 
 	// DBPath is the path to the sync state database.
 	DBPath string `yaml:"db_path"`
 
-	// Workers is the number of parallel sync workers.
+	// Workers is the number of parallel sync workers (default: the global
+	// workers). CH_WORKERS and --workers override it too; see SetWorkers.
 	Workers int `yaml:"workers"`
 
 	// DryRun if true, shows what would be synced without persisting.
 	DryRun bool `yaml:"dry_run"`
 
+	// Checksum detects changed files by hashing their content instead of
+	// comparing modification times, for filesystems with unreliable mtimes.
+	Checksum bool `yaml:"checksum"`
+
 	// Console backend settings.
 	Console ConsoleConfig `yaml:"console"`
 }
@@ -49,6 +60,10 @@ type ConsoleConfig struct {
 
 	// Format is "text" or "json".
 	Format string `yaml:"format"`
+
+	// Stderr writes spans to stderr instead of stdout. sync --json sets it
+	// so the spans don't mix with the report on stdout.
+	Stderr bool `yaml:"stderr"`
 }
 
 // DefaultConfig returns the default configuration.
@@ -58,11 +73,11 @@ func DefaultConfig() *Config {
 	return &Config{
 		ProjectsDir: filepath.Join(home, ".claude", "projects"),
 		ClaudeBin:   "claude",
+		Workers:     runtime.NumCPU(),
 		Sync: SyncConfig{
 			Enabled: true,
 			Backend: "console",
 			DBPath:  DefaultSyncDBPath(),
-			Workers: 4,
 			DryRun:  false,
 			Console: ConsoleConfig{
 				Verbose: false,
@@ -120,6 +135,9 @@ func Load() *Config {
 	if bin := os.Getenv("CLAUDE_BIN"); bin != "" {
 		cfg.ClaudeBin = bin
 	}
+	if n, err := strconv.Atoi(os.Getenv("CH_WORKERS")); err == nil && n > 0 {
+		cfg.SetWorkers(n)
+	}
 
 	// Sync-specific environment overrides
 	if db := os.Getenv("CH_SYNC_DB"); db != "" {
@@ -136,8 +154,11 @@ func Load() *Config {
 	if cfg.Sync.Backend == "" {
 		cfg.Sync.Backend = "console"
 	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
 	if cfg.Sync.Workers <= 0 {
-		cfg.Sync.Workers = 4
+		cfg.Sync.Workers = cfg.Workers
 	}
 	if cfg.Sync.Console.Format == "" {
 		cfg.Sync.Console.Format = "text"
@@ -146,6 +167,14 @@ func Load() *Config {
 	return cfg
 }
 
+// SetWorkers sets the worker count for every command, sync included. An
+// explicit count from the environment or command line replaces both the
+// global and the sync workers from the config file.
+func (c *Config) SetWorkers(n int) {
+	c.Workers = n
+	c.Sync.Workers = n
+}
+
 // Validate validates the configuration.
 func (c *Config) Validate() error {
 	// Check if projects directory exists
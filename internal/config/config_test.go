@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"runtime"
 	"testing"
 )
 
@@ -79,3 +80,59 @@ func TestEncodeProjectPath(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_Workers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := Load()
+	if cfg.Workers != runtime.NumCPU() {
+		t.Errorf("Workers = %d, want %d", cfg.Workers, runtime.NumCPU())
+	}
+	if cfg.Sync.Workers != cfg.Workers {
+		t.Errorf("Sync.Workers = %d, want %d", cfg.Sync.Workers, cfg.Workers)
+	}
+
+	t.Setenv("CH_WORKERS", "3")
+	cfg = Load()
+	if cfg.Workers != 3 {
+		t.Errorf("Workers = %d, want 3", cfg.Workers)
+	}
+	if cfg.Sync.Workers != 3 {
+		t.Errorf("Sync.Workers = %d, want 3", cfg.Sync.Workers)
+	}
+
+	t.Setenv("CH_WORKERS", "bogus")
+	cfg = Load()
+	if cfg.Workers != runtime.NumCPU() {
+		t.Errorf("invalid CH_WORKERS: Workers = %d, want %d", cfg.Workers, runtime.NumCPU())
+	}
+}
+
+func TestLoad_SyncWorkers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(DataDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ConfigPath(), []byte("workers: 4\nsync:\n  workers: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file's sync.workers is kept separate from workers
+	cfg := Load()
+	if cfg.Workers != 4 || cfg.Sync.Workers != 2 {
+		t.Errorf("Workers = %d, Sync.Workers = %d, want 4 and 2", cfg.Workers, cfg.Sync.Workers)
+	}
+
+	// An explicit count overrides both, as --workers does through SetWorkers
+	cfg.SetWorkers(5)
+	if cfg.Workers != 5 || cfg.Sync.Workers != 5 {
+		t.Errorf("after SetWorkers(5): Workers = %d, Sync.Workers = %d, want 5 and 5", cfg.Workers, cfg.Sync.Workers)
+	}
+
+	t.Setenv("CH_WORKERS", "8")
+	cfg = Load()
+	if cfg.Workers != 8 || cfg.Sync.Workers != 8 {
+		t.Errorf("CH_WORKERS=8: Workers = %d, Sync.Workers = %d, want 8 and 8", cfg.Workers, cfg.Sync.Workers)
+	}
+}
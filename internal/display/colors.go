@@ -2,7 +2,9 @@
 package display
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/fatih/color"
 	"github.com/mattn/go-isatty"
@@ -13,6 +15,31 @@ func IsTTY() bool {
 	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
 }
 
+// IsStderrTTY returns true if stderr is a terminal.
+func IsStderrTTY() bool {
+	return isatty.IsTerminal(os.Stderr.Fd()) || isatty.IsCygwinTerminal(os.Stderr.Fd())
+}
+
+// linesFromEnv returns the terminal height from $LINES, or 0 if unset or invalid.
+func linesFromEnv() int {
+	return sizeFromEnv("LINES")
+}
+
+// columnsFromEnv returns the terminal width from $COLUMNS, or 0 if unset or invalid.
+func columnsFromEnv() int {
+	return sizeFromEnv("COLUMNS")
+}
+
+// sizeFromEnv returns the non-negative integer in the environment variable
+// key, or 0 if unset or invalid.
+func sizeFromEnv(key string) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
 // SetColorEnabled enables or disables color output.
 func SetColorEnabled(enabled bool) {
 	color.NoColor = !enabled
@@ -25,6 +52,33 @@ func DisableColorIfNotTTY() {
 	}
 }
 
+// Color modes accepted by ApplyColorMode.
+const (
+	ColorAuto   = "auto"   // Color only when stdout is a terminal and NO_COLOR is unset
+	ColorAlways = "always" // Force color even when piping
+	ColorNever  = "never"  // Disable color entirely
+)
+
+// ApplyColorMode configures color output for the given mode.
+// An explicit "always" or "never" overrides both TTY detection and NO_COLOR.
+func ApplyColorMode(mode string) error {
+	switch mode {
+	case ColorAlways:
+		SetColorEnabled(true)
+	case ColorNever:
+		SetColorEnabled(false)
+	case ColorAuto, "":
+		if _, ok := os.LookupEnv("NO_COLOR"); ok {
+			SetColorEnabled(false)
+			return nil
+		}
+		DisableColorIfNotTTY()
+	default:
+		return fmt.Errorf("invalid color mode: %s (must be auto, always, or never)", mode)
+	}
+	return nil
+}
+
 // IsColorEnabled returns true if color output is enabled.
 func IsColorEnabled() bool {
 	return !color.NoColor
@@ -69,6 +123,19 @@ var (
 	Size   = color.New(color.FgCyan).SprintFunc()
 )
 
+// FormatNumber formats an integer with comma thousands separators, e.g. 1,234,567.
+func FormatNumber(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return sign + s
+}
+
 // FormatBytes formats a byte count as human-readable string.
 func FormatBytes(bytes int64) string {
 	const unit = 1024
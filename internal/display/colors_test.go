@@ -1,6 +1,7 @@
 package display
 
 import (
+	"math"
 	"testing"
 )
 
@@ -79,6 +80,44 @@ func TestSetColorEnabled(t *testing.T) {
 	SetColorEnabled(false)
 }
 
+func TestApplyColorMode(t *testing.T) {
+	defer SetColorEnabled(IsColorEnabled())
+
+	if err := ApplyColorMode(ColorAlways); err != nil {
+		t.Fatalf("ApplyColorMode(always) error = %v", err)
+	}
+	if !IsColorEnabled() {
+		t.Error("color should be enabled for mode always")
+	}
+
+	if err := ApplyColorMode(ColorNever); err != nil {
+		t.Fatalf("ApplyColorMode(never) error = %v", err)
+	}
+	if IsColorEnabled() {
+		t.Error("color should be disabled for mode never")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	SetColorEnabled(true)
+	if err := ApplyColorMode(ColorAuto); err != nil {
+		t.Fatalf("ApplyColorMode(auto) error = %v", err)
+	}
+	if IsColorEnabled() {
+		t.Error("color should be disabled in auto mode when NO_COLOR is set")
+	}
+
+	if err := ApplyColorMode(ColorAlways); err != nil {
+		t.Fatalf("ApplyColorMode(always) error = %v", err)
+	}
+	if !IsColorEnabled() {
+		t.Error("always should override NO_COLOR")
+	}
+
+	if err := ApplyColorMode("sometimes"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
 func TestDisableColorIfNotTTY(t *testing.T) {
 	// Test that DisableColorIfNotTTY doesn't panic
 	DisableColorIfNotTTY()
@@ -88,3 +127,31 @@ func TestIsTTY(t *testing.T) {
 	// Just verify it returns a boolean without panicking
 	_ = IsTTY()
 }
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0"},
+		{7, "7"},
+		{999, "999"},
+		{1000, "1,000"},
+		{9999, "9,999"},
+		{100000, "100,000"},
+		{999999, "999,999"},
+		{1000000, "1,000,000"},
+		{-1, "-1"},
+		{-999, "-999"},
+		{-1000, "-1,000"},
+		{-1234567, "-1,234,567"},
+		{math.MaxInt64, "9,223,372,036,854,775,807"},
+		{math.MinInt64, "-9,223,372,036,854,775,808"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatNumber(tt.n); got != tt.want {
+			t.Errorf("FormatNumber(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
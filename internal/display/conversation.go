@@ -1,12 +1,18 @@
 package display
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/dmora/ch/internal/history"
 	"github.com/dmora/ch/internal/jsonl"
@@ -14,43 +20,135 @@ import (
 
 // PaginationOptions controls message pagination for display.
 type PaginationOptions struct {
-	First      int // Show first N messages (0 = no limit)
-	Last       int // Show last N messages (0 = no limit)
-	RangeStart int // Start of range (1-based, 0 = not set)
-	RangeEnd   int // End of range (1-based, 0 = not set)
-	FitTokens  int // Auto-select messages to fit token budget (0 = disabled)
-	AfterIndex int // Start after message N for cursor pagination (0 = start from beginning)
-	Limit      int // Max messages to show with AfterIndex (0 = no limit)
+	First      int    // Show first N messages (0 = no limit)
+	Last       int    // Show last N messages (0 = no limit)
+	RangeStart int    // Start of range (1-based, 0 = not set)
+	RangeEnd   int    // End of range (1-based, 0 = not set)
+	FitTokens  int    // Auto-select messages to fit token budget (0 = disabled)
+	AfterIndex int    // Start after message N for cursor pagination (0 = start from beginning)
+	Limit      int    // Max messages to show; alone, caps output at the first Limit messages (0 = no limit)
+	AfterUUID  string // Show messages after the entry with this UUID (stable cursor)
+	BeforeUUID string // Show messages before the entry with this UUID (stable cursor)
 }
 
 // IsSet returns true if any pagination option is configured.
 func (p PaginationOptions) IsSet() bool {
-	return p.First > 0 || p.Last > 0 || p.RangeStart > 0 || p.FitTokens > 0 || p.AfterIndex > 0 || p.Limit > 0
+	return p.First > 0 || p.Last > 0 || p.RangeStart > 0 || p.FitTokens > 0 || p.AfterIndex > 0 || p.Limit > 0 ||
+		p.isUUIDCursor()
+}
+
+// isUUIDCursor returns true if pagination is anchored on a message UUID.
+func (p PaginationOptions) isUUIDCursor() bool {
+	return p.AfterUUID != "" || p.BeforeUUID != ""
+}
+
+// isLimitOnly returns true if Limit is used alone as a cap on the first messages.
+func (p PaginationOptions) isLimitOnly() bool {
+	return p.Limit > 0 && p.AfterIndex == 0 && !p.isUUIDCursor()
+}
+
+// anchorUUID returns the UUID used as the pagination anchor.
+func (p PaginationOptions) anchorUUID() string {
+	if p.AfterUUID != "" {
+		return p.AfterUUID
+	}
+	return p.BeforeUUID
 }
 
 // ConversationDisplayOptions configures conversation display.
 type ConversationDisplayOptions struct {
-	Writer        io.Writer
-	ShowThinking  bool              // Include thinking blocks
-	ShowTools     bool              // Include tool calls
-	ShowNumbering bool              // Show message indices [N] prefix
-	RoleFilter    string            // Filter by role: user, assistant, system (empty = all)
-	JSON          bool              // Output as JSON
-	Raw           bool              // Output raw JSONL
-	AgentCount    int               // Number of agents spawned by this conversation
-	Pagination    PaginationOptions // Pagination controls
+	Writer            io.Writer
+	ShowThinking      bool              // Include thinking blocks
+	ShowTools         bool              // Include tool calls
+	HideSystem        bool              // Omit system messages, from indices and counts too
+	HideHeader        bool              // Omit the metadata header
+	HideFooter        bool              // Omit the footer (resume hint, agent count, skipped lines)
+	ShowNumbering     bool              // Show message indices [N] prefix
+	RoleFilter        string            // Filter by role: user, assistant, system (empty = all)
+	JSON              bool              // Output as JSON
+	Compact           bool              // With JSON or Metadata, emit single-line JSON instead of indented
+	Raw               bool              // Output raw JSONL
+	Pretty            bool              // With Raw, indent each JSON line, separated by blank lines
+	Markdown          bool              // Output as Markdown
+	Metadata          bool              // Output entry metadata (type, UUIDs, timestamp) without bodies
+	ShowQueue         bool              // With Metadata, include queue-operation entries and their fields
+	ShowTokens        bool              // Show API-reported token usage of assistant messages
+	AgentCount        int               // Number of agents spawned by this conversation
+	Pagination        PaginationOptions // Pagination controls
+	CollapseStreaming bool              // Merge partial streaming chunks of the same assistant message
+	SanitizeOutput    bool              // Show control characters and escape sequences in message content as visible markers
+	ToolResultMaxLen  int               // Truncate tool results to this many bytes (0 = no truncation)
+	ToolInputMaxLen   int               // Truncate each tool input value to this many bytes (0 = no truncation)
+	ToolFilter        string            // Only show messages that call this tool (empty = all)
+	ErrorsOnly        bool              // Only show messages with a failed tool result
+	TimeFormat        string            // Header time format: absolute (default), relative, or a Go layout
+	TimeLayout        string            // JSON timestamp layout: rfc3339 (default, as recorded), unix, unixmilli, or a Go layout
+	Reverse           bool              // Show messages newest first (indices keep their original values)
+	Verbose           bool              // Show the raw JSON of content blocks ch doesn't recognize
+	SeparatorWidth    int               // Width of separator lines (default: DefaultSeparatorWidth)
+	ASCII             bool              // Draw separators with ASCII instead of Unicode characters
+}
+
+// DefaultSeparatorWidth is the width of separator lines when none is set.
+const DefaultSeparatorWidth = 60
+
+// Separator returns a horizontal rule, drawn with "-" instead of "─" in ASCII mode.
+func Separator(width int, ascii bool) string {
+	return rule("─", "-", width, ascii)
+}
+
+// DoubleSeparator returns a heavier rule for separating whole conversations,
+// drawn with "=" instead of "═" in ASCII mode.
+func DoubleSeparator(width int, ascii bool) string {
+	return rule("═", "=", width, ascii)
+}
+
+// rule repeats char (or asciiChar in ASCII mode) width times.
+func rule(char, asciiChar string, width int, ascii bool) string {
+	if width <= 0 {
+		width = DefaultSeparatorWidth
+	}
+	if ascii {
+		char = asciiChar
+	}
+	return strings.Repeat(char, width)
 }
 
+// PrefersASCII reports whether the locale explicitly selects a non-UTF-8
+// character set (e.g. LANG=C), where box-drawing characters would be mangled.
+// An unset locale is assumed to be UTF-8 friendly.
+func PrefersASCII() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return !strings.Contains(v, "utf-8") && !strings.Contains(v, "utf8")
+		}
+	}
+	return false
+}
+
+// Default truncation limits for tool output.
+const (
+	DefaultToolResultMaxLen = 500
+	DefaultToolInputMaxLen  = 100
+)
+
 // DefaultConversationDisplayOptions returns default display options.
 func DefaultConversationDisplayOptions() ConversationDisplayOptions {
 	return ConversationDisplayOptions{
-		Writer: os.Stdout,
+		Writer:           os.Stdout,
+		SanitizeOutput:   IsTTY(),
+		ToolResultMaxLen: DefaultToolResultMaxLen,
+		ToolInputMaxLen:  DefaultToolInputMaxLen,
 	}
 }
 
 // ConversationDisplay renders a full conversation.
 type ConversationDisplay struct {
 	opts ConversationDisplayOptions
+
+	lastShown      *jsonl.RawEntry // Latest message rendered, in conversation order
+	lastShownIndex int
 }
 
 // NewConversationDisplay creates a new conversation display.
@@ -63,23 +161,51 @@ func NewConversationDisplay(opts ConversationDisplayOptions) *ConversationDispla
 
 // Render renders the conversation.
 func (d *ConversationDisplay) Render(conv *history.Conversation) error {
+	if p := d.opts.Pagination; p.isUUIDCursor() && !d.opts.Raw {
+		if history.FindEntryByUUID(conv.Entries, p.anchorUUID()) < 0 {
+			return fmt.Errorf("message not found: %s", p.anchorUUID())
+		}
+	}
 	if d.opts.Raw {
 		return d.renderRaw(conv)
 	}
+	if d.opts.CollapseStreaming {
+		collapsed := *conv
+		collapsed.Entries = history.MergeStreamingEntries(conv.Entries)
+		conv = &collapsed
+	}
+	if d.opts.Metadata {
+		return d.renderMetadata(conv)
+	}
 	if d.opts.JSON {
 		return d.renderJSON(conv)
 	}
+	if d.opts.Markdown {
+		return d.renderMarkdown(conv)
+	}
 	return d.renderFormatted(conv)
 }
 
 func (d *ConversationDisplay) renderRaw(conv *history.Conversation) error {
+	// Conversations read from stdin have no file to re-read; re-encode entries.
+	if conv.Meta.Path == "" {
+		for i, entry := range conv.Entries {
+			line, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			d.writeRawLine(line, i == 0)
+		}
+		return nil
+	}
+
 	parser, err := jsonl.NewParser(conv.Meta.Path)
 	if err != nil {
 		return err
 	}
 	defer parser.Close()
 
-	for {
+	for first := true; ; first = false {
 		line, err := parser.NextRaw()
 		if err != nil {
 			return err
@@ -87,21 +213,41 @@ func (d *ConversationDisplay) renderRaw(conv *history.Conversation) error {
 		if line == nil {
 			break
 		}
-		fmt.Fprintln(d.opts.Writer, string(line))
+		d.writeRawLine(line, first)
 	}
 	return nil
 }
 
+// writeRawLine prints one JSONL line, indented when Pretty is set. Lines that
+// aren't valid JSON are printed as-is.
+func (d *ConversationDisplay) writeRawLine(line []byte, first bool) {
+	if !d.opts.Pretty {
+		fmt.Fprintln(d.opts.Writer, string(line))
+		return
+	}
+
+	if !first {
+		fmt.Fprintln(d.opts.Writer)
+	}
+	var buf bytes.Buffer
+	if json.Indent(&buf, line, "", "  ") != nil {
+		fmt.Fprintln(d.opts.Writer, string(line))
+		return
+	}
+	fmt.Fprintln(d.opts.Writer, buf.String())
+}
+
 func (d *ConversationDisplay) renderJSON(conv *history.Conversation) error {
 	type jsonMessage struct {
 		Type      string                 `json:"type"`
 		Index     int                    `json:"index,omitempty"` // 1-based message index
-		Timestamp string                 `json:"timestamp,omitempty"`
+		Timestamp any                    `json:"timestamp,omitempty"`
 		Role      string                 `json:"role,omitempty"`
 		Model     string                 `json:"model,omitempty"`
 		Text      string                 `json:"text,omitempty"`
 		Thinking  string                 `json:"thinking,omitempty"`
 		ToolCalls []jsonl.ToolCall       `json:"tool_calls,omitempty"`
+		Usage     *jsonl.Usage           `json:"usage,omitempty"` // With ShowTokens
 		Raw       map[string]interface{} `json:"raw,omitempty"`
 	}
 
@@ -118,20 +264,20 @@ func (d *ConversationDisplay) renderJSON(conv *history.Conversation) error {
 	msgIndex := 0
 
 	for _, entry := range conv.Entries {
-		if !entry.Type.IsMessage() {
+		if !d.isCountedMessage(entry) {
 			continue
 		}
 		msgIndex++
 
 		// Skip if not in filtered set
-		if d.opts.Pagination.IsSet() && !filteredSet[entry] {
+		if (d.opts.Pagination.IsSet() || d.hasContentFilter()) && !filteredSet[entry] {
 			continue
 		}
 
 		jm := jsonMessage{
 			Type:      string(entry.Type),
 			Index:     msgIndex,
-			Timestamp: entry.Timestamp,
+			Timestamp: JSONEntryTime(entry.Timestamp, d.opts.TimeLayout),
 		}
 
 		if entry.Message != nil {
@@ -146,16 +292,22 @@ func (d *ConversationDisplay) renderJSON(conv *history.Conversation) error {
 				if d.opts.ShowTools {
 					jm.ToolCalls = jsonl.ExtractToolCallDetails(msg)
 				}
+				if d.opts.ShowTokens {
+					jm.Usage = msg.Usage
+				}
 			}
 		}
 
 		messages = append(messages, jm)
 	}
+	if d.opts.Reverse {
+		slices.Reverse(messages)
+	}
 
 	// Count total messages
 	totalMessages := 0
 	for _, e := range conv.Entries {
-		if e.Type.IsMessage() {
+		if d.isCountedMessage(e) {
 			totalMessages++
 		}
 	}
@@ -164,24 +316,39 @@ func (d *ConversationDisplay) renderJSON(conv *history.Conversation) error {
 		ID            string        `json:"id"`
 		SessionID     string        `json:"session_id"`
 		Project       string        `json:"project"`
+		CWD           string        `json:"cwd,omitempty"`
+		Branch        string        `json:"branch,omitempty"`
+		Platform      string        `json:"platform,omitempty"`
+		Duration      int64         `json:"duration_seconds,omitempty"`
 		IsAgent       bool          `json:"is_agent"`
 		TotalMessages int           `json:"total_messages"`
 		ShownMessages int           `json:"shown_messages"`
 		HasGap        bool          `json:"has_gap,omitempty"`
+		SkippedLines  int           `json:"skipped_lines,omitempty"`
+		NextAfter     string        `json:"next_after,omitempty"`
+		PrevBefore    string        `json:"prev_before,omitempty"`
 		Messages      []jsonMessage `json:"messages"`
 	}{
 		ID:            conv.Meta.ID,
 		SessionID:     conv.Meta.SessionID,
 		Project:       conv.Meta.ProjectPath,
+		CWD:           conv.Meta.CWD,
+		Branch:        conv.Meta.Branch,
+		Platform:      conv.Meta.Platform,
+		Duration:      int64(conv.Meta.Duration.Seconds()),
 		IsAgent:       conv.Meta.IsAgent,
 		TotalMessages: totalMessages,
 		ShownMessages: len(messages),
 		HasGap:        hasGap,
+		SkippedLines:  len(conv.SkippedLines),
 		Messages:      messages,
 	}
 
-	encoder := json.NewEncoder(d.opts.Writer)
-	encoder.SetIndent("", "  ")
+	if d.opts.Pagination.isUUIDCursor() {
+		output.NextAfter, output.PrevBefore = uuidCursors(filteredMessages, d.extractMessages(conv.Entries))
+	}
+
+	encoder := NewJSONEncoder(d.opts.Writer, d.opts.Compact)
 	return encoder.Encode(output)
 }
 
@@ -196,6 +363,9 @@ func (d *ConversationDisplay) filterMessages(entries []*jsonl.RawEntry) ([]*json
 	}
 
 	p := d.opts.Pagination
+	if p.isUUIDCursor() {
+		return d.applyUUIDPagination(entries, messages)
+	}
 	if p.AfterIndex > 0 || p.Limit > 0 {
 		return d.applyCursorPagination(messages)
 	}
@@ -212,17 +382,52 @@ func (d *ConversationDisplay) filterMessages(entries []*jsonl.RawEntry) ([]*json
 func (d *ConversationDisplay) extractMessages(entries []*jsonl.RawEntry) []*jsonl.RawEntry {
 	var messages []*jsonl.RawEntry
 	for _, entry := range entries {
-		if !entry.Type.IsMessage() {
+		if !d.isCountedMessage(entry) {
 			continue
 		}
 		if d.opts.RoleFilter != "" && string(entry.Type) != d.opts.RoleFilter {
 			continue
 		}
+		if d.opts.ToolFilter != "" && !usesTool(entry, d.opts.ToolFilter) {
+			continue
+		}
+		if d.opts.ErrorsOnly && !history.HasToolError(entry) {
+			continue
+		}
 		messages = append(messages, entry)
 	}
 	return messages
 }
 
+// hasContentFilter reports whether messages are filtered by what they contain
+// (--tool, --errors-only) rather than by position.
+func (d *ConversationDisplay) hasContentFilter() bool {
+	return d.opts.ToolFilter != "" || d.opts.ErrorsOnly
+}
+
+// isCountedMessage reports whether entry is a message that takes part in
+// indexing and counts. System messages are excluded when HideSystem is set.
+func (d *ConversationDisplay) isCountedMessage(entry *jsonl.RawEntry) bool {
+	if !entry.Type.IsMessage() {
+		return false
+	}
+	return !d.opts.HideSystem || entry.Type != jsonl.EntryTypeSystem
+}
+
+// usesTool reports whether an entry contains a tool_use block for the named tool.
+func usesTool(entry *jsonl.RawEntry, name string) bool {
+	msg, err := jsonl.ParseMessage(entry)
+	if err != nil {
+		return false
+	}
+	for _, tool := range jsonl.ExtractToolCalls(msg) {
+		if tool == name {
+			return true
+		}
+	}
+	return false
+}
+
 // applyRangePagination applies --range X-Y pagination.
 func (d *ConversationDisplay) applyRangePagination(messages []*jsonl.RawEntry) ([]*jsonl.RawEntry, bool) {
 	total := len(messages)
@@ -272,11 +477,19 @@ func (d *ConversationDisplay) applyFirstLastPagination(messages []*jsonl.RawEntr
 }
 
 // applyCursorPagination implements --after-index N --limit M cursor-based iteration.
+// With --limit alone, the gap reported is the trailing messages cut by the cap.
 func (d *ConversationDisplay) applyCursorPagination(messages []*jsonl.RawEntry) ([]*jsonl.RawEntry, bool) {
 	afterIdx := d.opts.Pagination.AfterIndex
 	limit := d.opts.Pagination.Limit
 	total := len(messages)
 
+	if d.opts.Pagination.isLimitOnly() {
+		if limit >= total {
+			return messages, false
+		}
+		return messages[:limit], true
+	}
+
 	// Start position: after message N means start at index N (0-based)
 	startPos := afterIdx
 	if startPos >= total {
@@ -292,6 +505,62 @@ func (d *ConversationDisplay) applyCursorPagination(messages []*jsonl.RawEntry)
 	return messages[startPos:endPos], hasGapBefore
 }
 
+// applyUUIDPagination implements --after/--before UUID cursor pagination.
+// The anchor message itself is excluded. With AfterUUID the first Limit messages
+// after the anchor are kept; with BeforeUUID the last Limit messages before it.
+func (d *ConversationDisplay) applyUUIDPagination(entries, messages []*jsonl.RawEntry) ([]*jsonl.RawEntry, bool) {
+	p := d.opts.Pagination
+	anchorPos := history.FindEntryByUUID(entries, p.anchorUUID())
+	if anchorPos < 0 {
+		return nil, false
+	}
+
+	// Count messages strictly before the anchor entry
+	isMessage := make(map[*jsonl.RawEntry]bool, len(messages))
+	for _, m := range messages {
+		isMessage[m] = true
+	}
+	split := 0
+	for _, e := range entries[:anchorPos] {
+		if isMessage[e] {
+			split++
+		}
+	}
+
+	if p.AfterUUID != "" {
+		start := split
+		if start < len(messages) && messages[start] == entries[anchorPos] {
+			start++
+		}
+		end := len(messages)
+		if p.Limit > 0 && start+p.Limit < end {
+			end = start + p.Limit
+		}
+		return messages[start:end], start > 0
+	}
+
+	start := 0
+	if p.Limit > 0 && split > p.Limit {
+		start = split - p.Limit
+	}
+	return messages[start:split], start > 0
+}
+
+// uuidCursors returns the UUIDs to continue paging forward (after the last shown
+// message) and backward (before the first shown message). Empty if there is nothing more.
+func uuidCursors(shown, all []*jsonl.RawEntry) (next, prev string) {
+	if len(shown) == 0 || len(all) == 0 {
+		return "", ""
+	}
+	if last := shown[len(shown)-1]; last != all[len(all)-1] {
+		next = last.UUID
+	}
+	if first := shown[0]; first != all[0] {
+		prev = first.UUID
+	}
+	return next, prev
+}
+
 // estimateTokens estimates the token count for a message.
 // Uses ~4 characters per token as a rough heuristic.
 func estimateTokens(entry *jsonl.RawEntry) int {
@@ -333,10 +602,25 @@ func (d *ConversationDisplay) fitToTokenBudget(messages []*jsonl.RawEntry, budge
 func (d *ConversationDisplay) renderGapIndicator(totalMessages, firstCount, lastCount int) {
 	omitted := totalMessages - firstCount - lastCount
 	fmt.Fprintln(d.opts.Writer)
-	fmt.Fprintf(d.opts.Writer, "%s\n", Dim(strings.Repeat("·", 40)))
+	fmt.Fprintf(d.opts.Writer, "%s\n", Dim(d.gapLine()))
 	fmt.Fprintf(d.opts.Writer, "%s\n",
 		Dim(fmt.Sprintf("    ... %d messages omitted ...", omitted)))
-	fmt.Fprintf(d.opts.Writer, "%s\n", Dim(strings.Repeat("·", 40)))
+	fmt.Fprintf(d.opts.Writer, "%s\n", Dim(d.gapLine()))
+}
+
+// separator returns the rule drawn under the header and above the footer.
+func (d *ConversationDisplay) separator() string {
+	return Separator(d.opts.SeparatorWidth, d.opts.ASCII)
+}
+
+// gapLine returns the dotted line around omitted messages, two thirds the
+// separator width.
+func (d *ConversationDisplay) gapLine() string {
+	width := d.opts.SeparatorWidth
+	if width <= 0 {
+		width = DefaultSeparatorWidth
+	}
+	return rule("·", ".", width*2/3, d.opts.ASCII)
 }
 
 // renderPaginationInfo shows pagination status in output.
@@ -363,6 +647,48 @@ func (d *ConversationDisplay) renderCursorInfo(shown, total, afterIndex int) {
 	}
 }
 
+// renderUUIDCursorInfo shows UUID cursor pagination status with page hints.
+func (d *ConversationDisplay) renderUUIDCursorInfo(shown, all []*jsonl.RawEntry) {
+	p := d.opts.Pagination
+	direction := "after"
+	if p.AfterUUID == "" {
+		direction = "before"
+	}
+
+	fmt.Fprintln(d.opts.Writer)
+	fmt.Fprintf(d.opts.Writer, "%s %s\n",
+		Dim("Showing:"),
+		Number(fmt.Sprintf("%d messages %s %s", len(shown), direction, p.anchorUUID())))
+
+	limit := ""
+	if p.Limit > 0 {
+		limit = fmt.Sprintf(" --limit %d", p.Limit)
+	}
+	next, prev := uuidCursors(shown, all)
+	if p.AfterUUID != "" && next != "" {
+		fmt.Fprintf(d.opts.Writer, "%s %s\n", Dim("Next page:"), ID(fmt.Sprintf("--after %s%s", next, limit)))
+	}
+	if p.BeforeUUID != "" && prev != "" {
+		fmt.Fprintf(d.opts.Writer, "%s %s\n", Dim("Previous page:"), ID(fmt.Sprintf("--before %s%s", prev, limit)))
+	}
+}
+
+// renderContentFilterInfo shows how many messages matched the content filters.
+func (d *ConversationDisplay) renderContentFilterInfo(shown, total int) {
+	var criteria []string
+	if d.opts.ToolFilter != "" {
+		criteria = append(criteria, "calling "+d.opts.ToolFilter)
+	}
+	if d.opts.ErrorsOnly {
+		criteria = append(criteria, "with tool errors")
+	}
+	fmt.Fprintln(d.opts.Writer)
+	fmt.Fprintf(d.opts.Writer, "%s %s %s\n",
+		Dim("Showing:"),
+		Number(fmt.Sprintf("%d of %d messages", shown, total)),
+		Dim(strings.Join(criteria, ", ")))
+}
+
 // renderFitTokensInfo shows auto-selected pagination info.
 func (d *ConversationDisplay) renderFitTokensInfo(shown, total, budget int) {
 	fmt.Fprintln(d.opts.Writer)
@@ -373,14 +699,24 @@ func (d *ConversationDisplay) renderFitTokensInfo(shown, total, budget int) {
 }
 
 func (d *ConversationDisplay) renderFormatted(conv *history.Conversation) error {
-	d.renderHeader(conv)
+	if !d.opts.HideHeader {
+		d.renderHeader(conv)
+	}
 
 	messages, hasGap := d.filterMessages(conv.Entries)
 	indexMap, totalMessages := d.buildIndexMap(conv.Entries)
 
 	d.renderMessagesWithGap(messages, indexMap, totalMessages, hasGap)
-	d.renderPaginationStatus(len(messages), totalMessages)
-	d.renderFooter(conv)
+	if d.opts.Pagination.isUUIDCursor() {
+		d.renderUUIDCursorInfo(messages, d.extractMessages(conv.Entries))
+	} else if d.hasContentFilter() {
+		d.renderContentFilterInfo(len(messages), totalMessages)
+	} else {
+		d.renderPaginationStatus(len(messages), totalMessages)
+	}
+	if !d.opts.HideFooter {
+		d.renderFooter(conv)
+	}
 
 	return nil
 }
@@ -390,7 +726,7 @@ func (d *ConversationDisplay) buildIndexMap(entries []*jsonl.RawEntry) (map[*jso
 	indexMap := make(map[*jsonl.RawEntry]int)
 	totalMessages := 0
 	for _, e := range entries {
-		if e.Type.IsMessage() {
+		if d.isCountedMessage(e) {
 			totalMessages++
 			indexMap[e] = totalMessages
 		}
@@ -400,8 +736,15 @@ func (d *ConversationDisplay) buildIndexMap(entries []*jsonl.RawEntry) (map[*jso
 
 // renderMessagesWithGap renders messages, handling gaps appropriately.
 func (d *ConversationDisplay) renderMessagesWithGap(messages []*jsonl.RawEntry, indexMap map[*jsonl.RawEntry]int, totalMessages int, hasGap bool) {
+	if d.opts.Reverse {
+		d.renderReversed(messages, indexMap, totalMessages, hasGap)
+		return
+	}
 	if hasGap && d.opts.Pagination.First > 0 && d.opts.Pagination.Last > 0 {
 		d.renderFirstLastWithGap(messages, indexMap, totalMessages)
+	} else if hasGap && d.opts.Pagination.isLimitOnly() {
+		d.renderAllMessages(messages, indexMap)
+		d.renderLaterGap(totalMessages - len(messages))
 	} else if hasGap {
 		d.renderWithSimpleGap(messages, indexMap, totalMessages)
 	} else {
@@ -435,6 +778,42 @@ func (d *ConversationDisplay) renderWithSimpleGap(messages []*jsonl.RawEntry, in
 	d.renderAllMessages(messages, indexMap)
 }
 
+// renderLaterGap renders the indicator for messages cut off after the shown ones.
+func (d *ConversationDisplay) renderLaterGap(omitted int) {
+	fmt.Fprintln(d.opts.Writer)
+	fmt.Fprintf(d.opts.Writer, "%s\n", Dim(fmt.Sprintf("    ... %d later messages omitted ...", omitted)))
+}
+
+// renderReversed renders messages newest first. Gap indicators are placed
+// where the omitted messages would fall in reversed order.
+func (d *ConversationDisplay) renderReversed(messages []*jsonl.RawEntry, indexMap map[*jsonl.RawEntry]int, totalMessages int, hasGap bool) {
+	reversed := slices.Clone(messages)
+	slices.Reverse(reversed)
+
+	if hasGap && d.opts.Pagination.First > 0 && d.opts.Pagination.Last > 0 {
+		firstCount := min(d.opts.Pagination.First, len(messages))
+		lastCount := len(messages) - firstCount
+		d.renderAllMessages(reversed[:lastCount], indexMap)
+		d.renderGapIndicator(totalMessages, firstCount, d.opts.Pagination.Last)
+		d.renderAllMessages(reversed[lastCount:], indexMap)
+		return
+	}
+
+	if hasGap && d.opts.Pagination.isLimitOnly() {
+		d.renderLaterGap(totalMessages - len(messages))
+		fmt.Fprintln(d.opts.Writer)
+		d.renderAllMessages(reversed, indexMap)
+		return
+	}
+
+	d.renderAllMessages(reversed, indexMap)
+	if hasGap {
+		omitted := totalMessages - len(messages)
+		fmt.Fprintln(d.opts.Writer)
+		fmt.Fprintf(d.opts.Writer, "%s\n", Dim(fmt.Sprintf("    ... %d earlier messages omitted ...", omitted)))
+	}
+}
+
 // renderAllMessages renders all messages without gaps.
 func (d *ConversationDisplay) renderAllMessages(messages []*jsonl.RawEntry, indexMap map[*jsonl.RawEntry]int) {
 	for _, entry := range messages {
@@ -455,13 +834,22 @@ func (d *ConversationDisplay) renderPaginationStatus(shown, total int) {
 }
 
 func (d *ConversationDisplay) renderFooter(conv *history.Conversation) {
-	// Don't show footer for agents
-	if conv.Meta.IsAgent {
+	skipped := len(conv.SkippedLines)
+
+	// Don't show footer for agents unless lines were skipped
+	if conv.Meta.IsAgent && skipped == 0 {
 		return
 	}
 
 	fmt.Fprintln(d.opts.Writer)
-	fmt.Fprintln(d.opts.Writer, strings.Repeat("─", 60))
+	fmt.Fprintln(d.opts.Writer, d.separator())
+
+	if skipped > 0 {
+		fmt.Fprintln(d.opts.Writer, Warning(fmt.Sprintf("%d malformed line(s) skipped.", skipped)))
+	}
+	if conv.Meta.IsAgent {
+		return
+	}
 
 	shortID := history.ShortID(conv.Meta.ID)
 
@@ -491,17 +879,45 @@ func (d *ConversationDisplay) renderHeader(conv *history.Conversation) {
 
 	// Metadata
 	fmt.Fprintf(d.opts.Writer, "%s %s\n", Dim("Project:"), Project(conv.Meta.ProjectPath))
-	fmt.Fprintf(d.opts.Writer, "%s %s\n", Dim("Time:"), Timestamp(conv.Meta.Timestamp.Format(time.RFC3339)))
+	if conv.Meta.CWD != "" && conv.Meta.CWD != conv.Meta.ProjectPath {
+		fmt.Fprintf(d.opts.Writer, "%s %s\n", Dim("CWD:"), Project(conv.Meta.CWD))
+	}
+	if conv.Meta.Branch != "" {
+		fmt.Fprintf(d.opts.Writer, "%s %s\n", Dim("Branch:"), Info(conv.Meta.Branch))
+	}
+	if conv.Meta.Platform != "" {
+		fmt.Fprintf(d.opts.Writer, "%s %s\n", Dim("Platform:"), conv.Meta.Platform)
+	}
+	fmt.Fprintf(d.opts.Writer, "%s %s\n", Dim("Time:"), Timestamp(FormatTime(conv.Meta.Timestamp, d.opts.TimeFormat)))
+	if conv.Meta.Duration > 0 {
+		fmt.Fprintf(d.opts.Writer, "%s %s\n", Dim("Duration:"), FormatDuration(conv.Meta.Duration))
+	}
 	fmt.Fprintf(d.opts.Writer, "%s %s\n", Dim("Messages:"), Number(fmt.Sprintf("%d", conv.Meta.MessageCount)))
 	if conv.Meta.Model != "" {
 		fmt.Fprintf(d.opts.Writer, "%s %s\n", Dim("Model:"), Model(conv.Meta.Model))
 	}
 
 	fmt.Fprintln(d.opts.Writer)
-	fmt.Fprintln(d.opts.Writer, strings.Repeat("─", 60))
+	fmt.Fprintln(d.opts.Writer, d.separator())
 }
 
 func (d *ConversationDisplay) renderEntry(entry *jsonl.RawEntry, index int) {
+	if d.lastShown == nil || index > d.lastShownIndex {
+		d.lastShown, d.lastShownIndex = entry, index
+	}
+	d.renderLabeledEntry(entry, index, "")
+}
+
+// LastShown returns the latest message, in conversation order, that a text
+// Render passed to the page, with its 1-based index. The entry is nil if no
+// message was shown.
+func (d *ConversationDisplay) LastShown() (*jsonl.RawEntry, int) {
+	return d.lastShown, d.lastShownIndex
+}
+
+// renderLabeledEntry renders an entry whose role header is prefixed with
+// label, identifying its source in a merged transcript.
+func (d *ConversationDisplay) renderLabeledEntry(entry *jsonl.RawEntry, index int, label string) {
 	msg, err := jsonl.ParseMessage(entry)
 	if err != nil || msg == nil {
 		return
@@ -512,7 +928,10 @@ func (d *ConversationDisplay) renderEntry(entry *jsonl.RawEntry, index int) {
 	}
 
 	fmt.Fprintln(d.opts.Writer)
-	d.renderRoleHeader(entry, index)
+	if label != "" {
+		fmt.Fprintf(d.opts.Writer, "%s ", ID("["+label+"]"))
+	}
+	d.renderRoleHeader(entry, msg, index)
 
 	for _, block := range msg.Content {
 		d.renderBlock(&block)
@@ -531,17 +950,25 @@ func (d *ConversationDisplay) hasVisibleContent(msg *jsonl.Message) bool {
 			if d.opts.ShowThinking && block.Thinking != "" {
 				return true
 			}
+		case jsonl.BlockTypeRedactedThinking:
+			if d.opts.ShowThinking {
+				return true
+			}
 		case jsonl.BlockTypeToolUse, jsonl.BlockTypeToolResult:
 			if d.opts.ShowTools {
 				return true
 			}
+		case jsonl.BlockTypeImage:
+		default:
+			return true
 		}
 	}
 	return false
 }
 
-// renderRoleHeader renders the role prefix and timestamp for an entry.
-func (d *ConversationDisplay) renderRoleHeader(entry *jsonl.RawEntry, index int) {
+// renderRoleHeader renders the role prefix and timestamp for an entry, and
+// with ShowTokens the token usage recorded for assistant messages.
+func (d *ConversationDisplay) renderRoleHeader(entry *jsonl.RawEntry, msg *jsonl.Message, index int) {
 	if d.opts.ShowNumbering && index > 0 {
 		fmt.Fprintf(d.opts.Writer, "%s ", Number(fmt.Sprintf("[%d]", index)))
 	}
@@ -560,6 +987,10 @@ func (d *ConversationDisplay) renderRoleHeader(entry *jsonl.RawEntry, index int)
 			fmt.Fprintf(d.opts.Writer, "  %s", Timestamp(t.Format("15:04:05")))
 		}
 	}
+	if d.opts.ShowTokens && entry.Type == jsonl.EntryTypeAssistant && msg.Usage != nil {
+		fmt.Fprintf(d.opts.Writer, "  %s", Dim(fmt.Sprintf("(in: %d, out: %d tokens)",
+			msg.Usage.TotalInputTokens(), msg.Usage.OutputTokens)))
+	}
 	fmt.Fprintln(d.opts.Writer)
 }
 
@@ -573,21 +1004,53 @@ func (d *ConversationDisplay) renderBlock(block *jsonl.ContentBlock) {
 		d.renderToolUseBlock(block)
 	case jsonl.BlockTypeToolResult:
 		d.renderToolResultBlock(block)
+	case jsonl.BlockTypeRedactedThinking:
+		if d.opts.ShowThinking {
+			fmt.Fprintf(d.opts.Writer, "\n%s\n", Dim("[redacted thinking]"))
+		}
+	case jsonl.BlockTypeImage:
+		// Images can't be shown in a terminal
+	default:
+		d.renderUnknownBlock(block)
 	}
 }
 
+// renderUnknownBlock renders a placeholder for a block type added to Claude
+// Code after this version of ch, with its raw JSON in verbose mode.
+func (d *ConversationDisplay) renderUnknownBlock(block *jsonl.ContentBlock) {
+	fmt.Fprintln(d.opts.Writer, Dim(fmt.Sprintf("[unknown block: %s]", block.Type)))
+	if !d.opts.Verbose || len(block.Raw) == 0 {
+		return
+	}
+	var buf bytes.Buffer
+	if json.Indent(&buf, block.Raw, "  ", "  ") != nil {
+		buf.Reset()
+		buf.Write(block.Raw)
+	}
+	fmt.Fprintln(d.opts.Writer, Dim("  "+buf.String()))
+}
+
 func (d *ConversationDisplay) renderTextBlock(block *jsonl.ContentBlock) {
 	if block.Text != "" {
-		fmt.Fprintln(d.opts.Writer, block.Text)
+		fmt.Fprintln(d.opts.Writer, d.sanitize(block.Text))
 	}
 }
 
+// sanitize marks control characters in s when SanitizeOutput is set, so
+// escape sequences in tool output can't garble the terminal.
+func (d *ConversationDisplay) sanitize(s string) string {
+	if !d.opts.SanitizeOutput {
+		return s
+	}
+	return jsonl.SanitizeForTerminal(s)
+}
+
 func (d *ConversationDisplay) renderThinkingBlock(block *jsonl.ContentBlock) {
 	if !d.opts.ShowThinking || block.Thinking == "" {
 		return
 	}
 	fmt.Fprintf(d.opts.Writer, "\n%s\n", Section("Thinking:"))
-	lines := strings.Split(block.Thinking, "\n")
+	lines := strings.Split(d.sanitize(block.Thinking), "\n")
 	for _, line := range lines {
 		fmt.Fprintln(d.opts.Writer, Thinking("  "+line))
 	}
@@ -606,10 +1069,7 @@ func (d *ConversationDisplay) renderToolUseBlock(block *jsonl.ContentBlock) {
 		return
 	}
 	for k, v := range input {
-		val := fmt.Sprintf("%v", v)
-		if len(val) > 100 {
-			val = val[:100] + "..."
-		}
+		val := d.sanitize(truncateTo(fmt.Sprintf("%v", v), d.opts.ToolInputMaxLen))
 		fmt.Fprintf(d.opts.Writer, "  %s: %s\n", Dim(k), val)
 	}
 }
@@ -623,25 +1083,38 @@ func (d *ConversationDisplay) renderToolResultBlock(block *jsonl.ContentBlock) {
 		status = Error("ERROR")
 	}
 	fmt.Fprintf(d.opts.Writer, "%s %s\n", ToolCall("Result:"), status)
-	if block.Content == nil {
+	content := jsonl.ToolResultText(block)
+	if content == "" {
 		return
 	}
-	var content string
-	if json.Unmarshal(block.Content, &content) != nil {
+	content = d.sanitize(truncateTo(content, d.opts.ToolResultMaxLen))
+	if block.IsError {
+		fmt.Fprintln(d.opts.Writer, Error(content))
 		return
 	}
-	if len(content) > 500 {
-		content = content[:500] + "..."
-	}
 	fmt.Fprintln(d.opts.Writer, Dim(content))
 }
 
-// RenderAgentList renders a list of agents for a conversation.
-func RenderAgentList(w io.Writer, agents []*history.ConversationMeta, parentID string, asJSON bool, filter string) error {
+// truncateTo cuts s to at most maxLen bytes with a "..." suffix, backing up
+// to a rune boundary so no character is split. A maxLen of 0 disables truncation.
+func truncateTo(s string, maxLen int) string {
+	if maxLen <= 0 || len(s) <= maxLen {
+		return s
+	}
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
+
+// RenderAgentList renders a list of agents for a conversation. timeLayout
+// formats JSON timestamps (see JSONTime).
+func RenderAgentList(w io.Writer, agents []*history.ConversationMeta, parentID string, asJSON, compact bool, timeLayout, filter string) error {
 	if asJSON {
 		type jsonAgent struct {
 			ID        string `json:"id"`
-			Timestamp string `json:"timestamp"`
+			Timestamp any    `json:"timestamp"`
 			Messages  int    `json:"messages"`
 			Preview   string `json:"preview"`
 		}
@@ -650,14 +1123,13 @@ func RenderAgentList(w io.Writer, agents []*history.ConversationMeta, parentID s
 		for i, a := range agents {
 			output[i] = jsonAgent{
 				ID:        a.ID,
-				Timestamp: a.Timestamp.Format(time.RFC3339),
+				Timestamp: JSONTime(a.Timestamp, timeLayout),
 				Messages:  a.MessageCount,
 				Preview:   a.Preview,
 			}
 		}
 
-		encoder := json.NewEncoder(w)
-		encoder.SetIndent("", "  ")
+		encoder := NewJSONEncoder(w, compact)
 		return encoder.Encode(output)
 	}
 
@@ -693,42 +1165,298 @@ func RenderAgentList(w io.Writer, agents []*history.ConversationMeta, parentID s
 	return nil
 }
 
+// RenderAgentTree renders the agent hierarchy for a conversation. timeLayout
+// formats JSON timestamps (see JSONTime).
+func RenderAgentTree(w io.Writer, roots []*history.AgentNode, parentID string, asJSON, compact bool, timeLayout string) error {
+	if asJSON {
+		encoder := NewJSONEncoder(w, compact)
+		return encoder.Encode(agentTreeJSON(roots, timeLayout))
+	}
+
+	if len(roots) == 0 {
+		fmt.Fprintln(w, Dim("No agents found for this conversation"))
+		return nil
+	}
+
+	fmt.Fprintf(w, "\n%s %s\n", Title("Agent tree for conversation"), ID(history.ShortID(parentID)))
+	fmt.Fprintf(w, "%s\n\n", Dim(fmt.Sprintf("Found %d agent(s)", countAgentNodes(roots))))
+
+	fmt.Fprintln(w, ID(history.ShortID(parentID)))
+	renderAgentNodes(w, roots, "")
+	return nil
+}
+
+// jsonAgentNode is the JSON form of an agent tree node.
+type jsonAgentNode struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Timestamp   any             `json:"timestamp"`
+	Messages    int             `json:"messages"`
+	Preview     string          `json:"preview"`
+	Children    []jsonAgentNode `json:"children,omitempty"`
+}
+
+// agentTreeJSON converts agent nodes into their nested JSON form.
+func agentTreeJSON(nodes []*history.AgentNode, timeLayout string) []jsonAgentNode {
+	output := make([]jsonAgentNode, len(nodes))
+	for i, n := range nodes {
+		output[i] = jsonAgentNode{
+			ID:        n.Meta.ID,
+			Timestamp: JSONTime(n.Meta.Timestamp, timeLayout),
+			Messages:  n.Meta.MessageCount,
+			Preview:   n.Meta.Preview,
+			Children:  agentTreeJSON(n.Children, timeLayout),
+		}
+		if n.Info != nil {
+			output[i].Type = n.Info.SubagentType
+			output[i].Description = n.Info.Description
+		}
+	}
+	return output
+}
+
+// renderAgentNodes renders agent nodes with tree-drawing prefixes.
+func renderAgentNodes(w io.Writer, nodes []*history.AgentNode, prefix string) {
+	for i, n := range nodes {
+		branch, childPrefix := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, childPrefix = "└── ", "    "
+		}
+
+		label := ID("agent-" + n.Meta.ID)
+		if n.Info != nil && n.Info.SubagentType != "" {
+			label += " " + Match(n.Info.SubagentType)
+		}
+		fmt.Fprintf(w, "%s%s%s  %s  %s\n",
+			Dim(prefix), Dim(branch), label,
+			Timestamp(n.Meta.Timestamp.Format("15:04:05")),
+			Dim(fmt.Sprintf("(%d messages)", n.Meta.MessageCount)),
+		)
+
+		desc := n.Meta.Preview
+		if n.Info != nil && n.Info.Description != "" {
+			desc = n.Info.Description
+		}
+		if desc != "" {
+			fmt.Fprintf(w, "%s%s    %s\n", Dim(prefix), Dim(childPrefix), truncateString(desc, 70))
+		}
+
+		renderAgentNodes(w, n.Children, prefix+childPrefix)
+	}
+}
+
+// countAgentNodes counts all nodes in an agent tree.
+func countAgentNodes(nodes []*history.AgentNode) int {
+	count := len(nodes)
+	for _, n := range nodes {
+		count += countAgentNodes(n.Children)
+	}
+	return count
+}
+
 // RenderStats renders usage statistics.
-func RenderStats(w io.Writer, stats *Stats, asJSON bool) error {
+func RenderStats(w io.Writer, stats *Stats, asJSON, compact bool, timeLayout string) error {
 	if asJSON {
-		encoder := json.NewEncoder(w)
-		encoder.SetIndent("", "  ")
-		return encoder.Encode(stats)
+		encoder := NewJSONEncoder(w, compact)
+		return encoder.Encode(struct {
+			*Stats
+			OldestConversation any `json:"oldest_conversation,omitempty"`
+			NewestConversation any `json:"newest_conversation,omitempty"`
+		}{
+			Stats:              stats,
+			OldestConversation: jsonOptionalTime(stats.OldestConversation, timeLayout),
+			NewestConversation: jsonOptionalTime(stats.NewestConversation, timeLayout),
+		})
 	}
 
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, Title("Claude Code Usage Statistics"))
 	fmt.Fprintln(w)
 
-	fmt.Fprintf(w, "  %s %s\n", Dim("Projects:"), Number(fmt.Sprintf("%d", stats.ProjectCount)))
-	fmt.Fprintf(w, "  %s %s\n", Dim("Conversations:"), Number(fmt.Sprintf("%d", stats.ConversationCount)))
-	fmt.Fprintf(w, "  %s %s\n", Dim("Agents:"), Number(fmt.Sprintf("%d", stats.AgentCount)))
-	fmt.Fprintf(w, "  %s %s\n", Dim("Total Messages:"), Number(fmt.Sprintf("%d", stats.TotalMessages)))
+	fmt.Fprintf(w, "  %s %s\n", Dim("Projects:"), Number(FormatNumber(int64(stats.ProjectCount))))
+	if stats.Active != nil {
+		fmt.Fprintf(w, "  %s %s %s\n", Dim("Active Projects:"), Number(FormatNumber(int64(stats.Active.ProjectCount))),
+			Dim(fmt.Sprintf("(within %s)", stats.Active.Window)))
+	}
+	fmt.Fprintf(w, "  %s %s\n", Dim("Conversations:"), Number(FormatNumber(int64(stats.ConversationCount))))
+	fmt.Fprintf(w, "  %s %s\n", Dim("Agents:"), Number(FormatNumber(int64(stats.AgentCount))))
+	fmt.Fprintf(w, "  %s %s\n", Dim("Total Messages:"), Number(FormatNumber(int64(stats.TotalMessages))))
+	fmt.Fprintf(w, "  %s %s\n", Dim("Total Size:"), FormatBytes(stats.TotalSize))
+
+	if !stats.OldestConversation.IsZero() {
+		fmt.Fprintf(w, "  %s %s\n", Dim("Oldest:"), Timestamp(stats.OldestConversation.Format(statsTimeLayout)))
+	}
+	if !stats.NewestConversation.IsZero() {
+		fmt.Fprintf(w, "  %s %s\n", Dim("Newest:"), Timestamp(stats.NewestConversation.Format(statsTimeLayout)))
+	}
+
+	if len(stats.ToolUsage) > 0 {
+		renderToolUsage(w, stats.ToolUsage, TopToolsLimit)
+	}
+
+	fmt.Fprintln(w)
+	return nil
+}
+
+// RenderStatsCSV renders aggregate stats as metric,value CSV rows.
+// Tool usage counts are emitted as tool:<name> rows, most used first.
+func RenderStatsCSV(w io.Writer, stats *Stats) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		{"metric", "value"},
+		{"project_count", strconv.Itoa(stats.ProjectCount)},
+		{"conversation_count", strconv.Itoa(stats.ConversationCount)},
+		{"agent_count", strconv.Itoa(stats.AgentCount)},
+		{"total_messages", strconv.Itoa(stats.TotalMessages)},
+		{"total_size", strconv.FormatInt(stats.TotalSize, 10)},
+		{"oldest_conversation", formatOptionalTime(stats.OldestConversation, statsTimeLayout)},
+		{"newest_conversation", formatOptionalTime(stats.NewestConversation, statsTimeLayout)},
+	}
+	if stats.Active != nil {
+		rows = append(rows,
+			[]string{"active_window", stats.Active.Window},
+			[]string{"active_project_count", strconv.Itoa(stats.Active.ProjectCount)})
+	}
+	for _, tc := range sortToolUsage(stats.ToolUsage) {
+		rows = append(rows, []string{"tool:" + tc.Name, strconv.Itoa(tc.Count)})
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// RenderProjectStats renders statistics for a single project. timeLayout
+// formats JSON timestamps (see JSONTime).
+func RenderProjectStats(w io.Writer, stats *history.ProjectStats, asJSON, compact bool, timeLayout string) error {
+	if asJSON {
+		output := struct {
+			Project           string `json:"project"`
+			Dir               string `json:"dir"`
+			ConversationCount int    `json:"conversation_count"`
+			AgentCount        int    `json:"agent_count"`
+			TotalMessages     int    `json:"total_messages"`
+			TotalSize         int64  `json:"total_size"`
+			Oldest            any    `json:"oldest,omitempty"`
+			Newest            any    `json:"newest,omitempty"`
+		}{
+			Project:           stats.Project.Path,
+			Dir:               stats.Project.Dir,
+			ConversationCount: stats.ConversationCount,
+			AgentCount:        stats.AgentCount,
+			TotalMessages:     stats.MessageCount,
+			TotalSize:         stats.TotalSize,
+			Oldest:            jsonOptionalTime(stats.OldestTimestamp, timeLayout),
+			Newest:            jsonOptionalTime(stats.NewestTimestamp, timeLayout),
+		}
+		encoder := NewJSONEncoder(w, compact)
+		return encoder.Encode(output)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s %s\n", Title("Project Statistics"), Project(stats.Project.Path))
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "  %s %s\n", Dim("Conversations:"), Number(FormatNumber(int64(stats.ConversationCount))))
+	fmt.Fprintf(w, "  %s %s\n", Dim("Agents:"), Number(FormatNumber(int64(stats.AgentCount))))
+	fmt.Fprintf(w, "  %s %s\n", Dim("Total Messages:"), Number(FormatNumber(int64(stats.MessageCount))))
 	fmt.Fprintf(w, "  %s %s\n", Dim("Total Size:"), FormatBytes(stats.TotalSize))
 
-	if stats.OldestConversation != "" {
-		fmt.Fprintf(w, "  %s %s\n", Dim("Oldest:"), Timestamp(stats.OldestConversation))
+	if !stats.OldestTimestamp.IsZero() {
+		fmt.Fprintf(w, "  %s %s\n", Dim("Oldest:"), Timestamp(stats.OldestTimestamp.Format("2006-01-02")))
 	}
-	if stats.NewestConversation != "" {
-		fmt.Fprintf(w, "  %s %s\n", Dim("Newest:"), Timestamp(stats.NewestConversation))
+	if !stats.NewestTimestamp.IsZero() {
+		fmt.Fprintf(w, "  %s %s\n", Dim("Newest:"), Timestamp(stats.NewestTimestamp.Format("2006-01-02")))
 	}
 
 	fmt.Fprintln(w)
 	return nil
 }
 
+// TopToolsLimit is the number of tools shown in the stats tool usage table.
+const TopToolsLimit = 15
+
+// toolCount pairs a tool name with its call count.
+type toolCount struct {
+	Name  string
+	Count int
+}
+
+// sortToolUsage returns tool counts sorted by count (descending), then name.
+func sortToolUsage(usage map[string]int) []toolCount {
+	sorted := make([]toolCount, 0, len(usage))
+	for name, count := range usage {
+		sorted = append(sorted, toolCount{Name: name, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// renderToolUsage renders the top N tools by call count.
+func renderToolUsage(w io.Writer, usage map[string]int, limit int) {
+	sorted := sortToolUsage(usage)
+
+	total := 0
+	nameWidth := 0
+	for _, tc := range sorted {
+		total += tc.Count
+	}
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	for _, tc := range sorted {
+		if len(tc.Name) > nameWidth {
+			nameWidth = len(tc.Name)
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  %s %s\n", Section("Tool Usage"), Dim(fmt.Sprintf("(%s calls, %d tools)", FormatNumber(int64(total)), len(usage))))
+	for _, tc := range sorted {
+		pct := float64(tc.Count) * 100 / float64(total)
+		fmt.Fprintf(w, "    %-*s  %s  %s\n", nameWidth, tc.Name,
+			Number(fmt.Sprintf("%6s", FormatNumber(int64(tc.Count)))),
+			Dim(fmt.Sprintf("%5.1f%%", pct)))
+	}
+	if hidden := len(usage) - len(sorted); hidden > 0 {
+		fmt.Fprintf(w, "    %s\n", Dim(fmt.Sprintf("... %d more (use --json for the full list)", hidden)))
+	}
+}
+
+// statsTimeLayout formats the oldest and newest conversation in text and CSV
+// stats.
+const statsTimeLayout = "2006-01-02 15:04"
+
+// formatOptionalTime formats t with layout, or returns "" if t is zero.
+func formatOptionalTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
 // Stats represents usage statistics.
 type Stats struct {
-	ProjectCount       int    `json:"project_count"`
-	ConversationCount  int    `json:"conversation_count"`
-	AgentCount         int    `json:"agent_count"`
-	TotalMessages      int    `json:"total_messages"`
-	TotalSize          int64  `json:"total_size"`
-	OldestConversation string `json:"oldest_conversation,omitempty"`
-	NewestConversation string `json:"newest_conversation,omitempty"`
+	ProjectCount       int            `json:"project_count"`
+	ConversationCount  int            `json:"conversation_count"`
+	AgentCount         int            `json:"agent_count"`
+	TotalMessages      int            `json:"total_messages"`
+	TotalSize          int64          `json:"total_size"`
+	OldestConversation time.Time      `json:"-"` // Rendered with the JSON time layout
+	NewestConversation time.Time      `json:"-"`
+	ToolUsage          map[string]int `json:"tool_usage,omitempty"`
+	Active             *ActiveStats   `json:"active,omitempty"`
+}
+
+// ActiveStats describes the --active window. When set, the other Stats
+// totals cover only the active projects; ProjectCount stays the overall total.
+type ActiveStats struct {
+	Window       string `json:"window"`
+	ProjectCount int    `json:"project_count"`
 }
@@ -3,6 +3,10 @@ package display
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -144,6 +148,34 @@ func TestConversationDisplay_RenderRaw(t *testing.T) {
 	}
 }
 
+func TestConversationDisplay_RenderRawPretty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conv.jsonl")
+	content := `{"type":"user","message":{"role":"user","content":"Hi"}}` + "\n" + "not json\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	conv := &history.Conversation{Meta: history.ConversationMeta{Path: path}}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, Raw: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if buf.String() != content {
+		t.Errorf("plain raw output = %q, want the file verbatim", buf.String())
+	}
+
+	buf.Reset()
+	disp = NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, Raw: true, Pretty: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := "{\n  \"type\": \"user\",\n  \"message\": {\n    \"role\": \"user\",\n    \"content\": \"Hi\"\n  }\n}\n\nnot json\n"
+	if buf.String() != want {
+		t.Errorf("pretty raw output = %q, want %q", buf.String(), want)
+	}
+}
+
 func TestRenderAgentList(t *testing.T) {
 	agents := []*history.ConversationMeta{
 		{
@@ -162,7 +194,7 @@ func TestRenderAgentList(t *testing.T) {
 
 	t.Run("table output", func(t *testing.T) {
 		var buf bytes.Buffer
-		err := RenderAgentList(&buf, agents, "parent123", false, "")
+		err := RenderAgentList(&buf, agents, "parent123", false, false, "", "")
 		if err != nil {
 			t.Fatalf("RenderAgentList() error = %v", err)
 		}
@@ -174,7 +206,7 @@ func TestRenderAgentList(t *testing.T) {
 
 	t.Run("JSON output", func(t *testing.T) {
 		var buf bytes.Buffer
-		err := RenderAgentList(&buf, agents, "parent123", true, "")
+		err := RenderAgentList(&buf, agents, "parent123", true, false, "", "")
 		if err != nil {
 			t.Fatalf("RenderAgentList() error = %v", err)
 		}
@@ -190,7 +222,7 @@ func TestRenderAgentList(t *testing.T) {
 
 	t.Run("empty list", func(t *testing.T) {
 		var buf bytes.Buffer
-		err := RenderAgentList(&buf, []*history.ConversationMeta{}, "parent123", false, "")
+		err := RenderAgentList(&buf, []*history.ConversationMeta{}, "parent123", false, false, "", "")
 		if err != nil {
 			t.Fatalf("RenderAgentList() error = %v", err)
 		}
@@ -201,7 +233,7 @@ func TestRenderAgentList(t *testing.T) {
 
 	t.Run("empty list with filter", func(t *testing.T) {
 		var buf bytes.Buffer
-		err := RenderAgentList(&buf, []*history.ConversationMeta{}, "parent123", false, "test-type")
+		err := RenderAgentList(&buf, []*history.ConversationMeta{}, "parent123", false, false, "", "test-type")
 		if err != nil {
 			t.Fatalf("RenderAgentList() error = %v", err)
 		}
@@ -218,13 +250,13 @@ func TestRenderStats(t *testing.T) {
 		AgentCount:         50,
 		TotalMessages:      1000,
 		TotalSize:          1024 * 1024 * 100, // 100 MB
-		OldestConversation: "2024-01-01 10:00",
-		NewestConversation: "2024-06-01 10:00",
+		OldestConversation: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		NewestConversation: time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
 	}
 
 	t.Run("table output", func(t *testing.T) {
 		var buf bytes.Buffer
-		err := RenderStats(&buf, stats, false)
+		err := RenderStats(&buf, stats, false, false, "")
 		if err != nil {
 			t.Fatalf("RenderStats() error = %v", err)
 		}
@@ -236,7 +268,7 @@ func TestRenderStats(t *testing.T) {
 
 	t.Run("JSON output", func(t *testing.T) {
 		var buf bytes.Buffer
-		err := RenderStats(&buf, stats, true)
+		err := RenderStats(&buf, stats, true, false, "")
 		if err != nil {
 			t.Fatalf("RenderStats() error = %v", err)
 		}
@@ -248,9 +280,72 @@ func TestRenderStats(t *testing.T) {
 		if result["project_count"].(float64) != 5 {
 			t.Errorf("Expected project_count 5, got %v", result["project_count"])
 		}
+		if result["oldest_conversation"] != "2024-01-01T10:00:00Z" {
+			t.Errorf("Expected RFC3339 oldest_conversation, got %v", result["oldest_conversation"])
+		}
+	})
+
+	t.Run("JSON time layout", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := RenderStats(&buf, stats, true, true, TimeLayoutUnix); err != nil {
+			t.Fatalf("RenderStats() error = %v", err)
+		}
+		if !strings.Contains(buf.String(), `"newest_conversation":1717236000`) {
+			t.Errorf("Expected unix newest_conversation, got: %s", buf.String())
+		}
+	})
+	t.Run("compact JSON output", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := RenderStats(&buf, stats, true, true, ""); err != nil {
+			t.Fatalf("RenderStats() error = %v", err)
+		}
+
+		out := strings.TrimSuffix(buf.String(), "\n")
+		if strings.Contains(out, "\n") {
+			t.Errorf("Expected single-line JSON, got: %s", out)
+		}
+		var result map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+			t.Fatalf("Failed to parse JSON: %v", err)
+		}
 	})
 }
 
+func TestRenderProjectStats(t *testing.T) {
+	stats := &history.ProjectStats{
+		Project:           &history.Project{Path: "/Users/test/project", Dir: "/projects/-Users-test-project"},
+		ConversationCount: 3,
+		AgentCount:        2,
+		MessageCount:      40,
+		TotalSize:         2048,
+		OldestTimestamp:   time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		NewestTimestamp:   time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	if err := RenderProjectStats(&buf, stats, false, false, ""); err != nil {
+		t.Fatalf("RenderProjectStats() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "/Users/test/project") {
+		t.Errorf("Expected project path in output, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := RenderProjectStats(&buf, stats, true, false, TimeLayoutUnix); err != nil {
+		t.Fatalf("RenderProjectStats() error = %v", err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if result["total_messages"].(float64) != 40 {
+		t.Errorf("Expected total_messages 40, got %v", result["total_messages"])
+	}
+	if result["oldest"] != float64(1704103200) {
+		t.Errorf("Expected unix oldest, got %v", result["oldest"])
+	}
+}
+
 func TestDefaultConversationDisplayOptions(t *testing.T) {
 	opts := DefaultConversationDisplayOptions()
 	if opts.Writer == nil {
@@ -263,3 +358,720 @@ func TestDefaultConversationDisplayOptions(t *testing.T) {
 		t.Error("ShowTools should be false by default")
 	}
 }
+
+func TestRenderAgentTree(t *testing.T) {
+	roots := []*history.AgentNode{
+		{
+			Meta: &history.ConversationMeta{ID: "aaa", MessageCount: 3, Timestamp: time.Now()},
+			Info: &history.AgentInfo{SubagentType: "Explore", Description: "look around"},
+			Children: []*history.AgentNode{
+				{Meta: &history.ConversationMeta{ID: "bbb", MessageCount: 1, Timestamp: time.Now()}},
+			},
+		},
+	}
+
+	t.Run("text output", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := RenderAgentTree(&buf, roots, "main1234", false, false, ""); err != nil {
+			t.Fatalf("RenderAgentTree() error = %v", err)
+		}
+		output := buf.String()
+		for _, want := range []string{"agent-aaa", "Explore", "└── ", "    └── ", "agent-bbb", "Found 2 agent(s)"} {
+			if !bytes.Contains(buf.Bytes(), []byte(want)) {
+				t.Errorf("expected %q in output, got:\n%s", want, output)
+			}
+		}
+	})
+
+	t.Run("JSON output", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := RenderAgentTree(&buf, roots, "main1234", true, false, ""); err != nil {
+			t.Fatalf("RenderAgentTree() error = %v", err)
+		}
+		var result []struct {
+			ID       string `json:"id"`
+			Type     string `json:"type"`
+			Children []struct {
+				ID string `json:"id"`
+			} `json:"children"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+			t.Fatalf("JSON unmarshal error = %v", err)
+		}
+		if len(result) != 1 || result[0].Type != "Explore" {
+			t.Fatalf("unexpected tree: %+v", result)
+		}
+		if len(result[0].Children) != 1 || result[0].Children[0].ID != "bbb" {
+			t.Errorf("expected nested child bbb, got %+v", result[0].Children)
+		}
+	})
+}
+
+func TestConversationDisplay_UUIDPagination(t *testing.T) {
+	var entries []*jsonl.RawEntry
+	for i := 1; i <= 5; i++ {
+		entries = append(entries, &jsonl.RawEntry{
+			Type:    jsonl.EntryTypeUser,
+			UUID:    fmt.Sprintf("uuid-%d", i),
+			Message: json.RawMessage(fmt.Sprintf(`{"role":"user","content":"message %d"}`, i)),
+		})
+	}
+	conv := &history.Conversation{
+		Meta:    history.ConversationMeta{ID: "abc123"},
+		Entries: entries,
+	}
+
+	render := func(p PaginationOptions) (shown []int, next, prev string, err error) {
+		var buf bytes.Buffer
+		disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, JSON: true, Pagination: p})
+		if err := disp.Render(conv); err != nil {
+			return nil, "", "", err
+		}
+		var out struct {
+			NextAfter  string `json:"next_after"`
+			PrevBefore string `json:"prev_before"`
+			Messages   []struct {
+				Index int `json:"index"`
+			} `json:"messages"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+			return nil, "", "", err
+		}
+		for _, m := range out.Messages {
+			shown = append(shown, m.Index)
+		}
+		return shown, out.NextAfter, out.PrevBefore, nil
+	}
+
+	shown, next, _, err := render(PaginationOptions{AfterUUID: "uuid-2", Limit: 2})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if fmt.Sprint(shown) != "[3 4]" {
+		t.Errorf("after uuid-2 limit 2 = %v, want [3 4]", shown)
+	}
+	if next != "uuid-4" {
+		t.Errorf("next_after = %q, want uuid-4", next)
+	}
+
+	shown, next, _, _ = render(PaginationOptions{AfterUUID: "uuid-4"})
+	if fmt.Sprint(shown) != "[5]" || next != "" {
+		t.Errorf("after uuid-4 = %v (next %q), want [5] with no next page", shown, next)
+	}
+
+	shown, _, prev, _ := render(PaginationOptions{BeforeUUID: "uuid-4", Limit: 2})
+	if fmt.Sprint(shown) != "[2 3]" {
+		t.Errorf("before uuid-4 limit 2 = %v, want [2 3]", shown)
+	}
+	if prev != "uuid-2" {
+		t.Errorf("prev_before = %q, want uuid-2", prev)
+	}
+
+	if _, _, _, err := render(PaginationOptions{AfterUUID: "missing"}); err == nil {
+		t.Error("expected error for unknown UUID")
+	}
+}
+
+func TestConversationDisplay_LimitAlone(t *testing.T) {
+	var entries []*jsonl.RawEntry
+	for i := 1; i <= 5; i++ {
+		entries = append(entries, &jsonl.RawEntry{
+			Type:    jsonl.EntryTypeUser,
+			Message: json.RawMessage(fmt.Sprintf(`{"role":"user","content":"message %d"}`, i)),
+		})
+	}
+	conv := &history.Conversation{
+		Meta:    history.ConversationMeta{ID: "abc123"},
+		Entries: entries,
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, JSON: true, Pagination: PaginationOptions{Limit: 2}})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	var out struct {
+		HasGap   bool `json:"has_gap"`
+		Messages []struct {
+			Index int `json:"index"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("JSON unmarshal error = %v", err)
+	}
+	if len(out.Messages) != 2 || out.Messages[0].Index != 1 || out.Messages[1].Index != 2 {
+		t.Errorf("limit 2 = %+v, want messages 1 and 2", out.Messages)
+	}
+	if !out.HasGap {
+		t.Error("expected has_gap when the limit cuts messages")
+	}
+
+	buf.Reset()
+	disp = NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, Pagination: PaginationOptions{Limit: 2}})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	text := buf.String()
+	for _, want := range []string{"message 2", "3 later messages omitted", "--after-index 2 --limit 2"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "message 3") {
+		t.Errorf("output should stop at the limit:\n%s", text)
+	}
+
+	buf.Reset()
+	disp = NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, Pagination: PaginationOptions{Limit: 10}})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "omitted") {
+		t.Errorf("no gap expected when the limit covers every message:\n%s", buf.String())
+	}
+}
+
+func TestConversationDisplay_LastShown(t *testing.T) {
+	var entries []*jsonl.RawEntry
+	for i := 1; i <= 4; i++ {
+		entries = append(entries, &jsonl.RawEntry{
+			Type:    jsonl.EntryTypeUser,
+			UUID:    fmt.Sprintf("uuid-%d", i),
+			Message: json.RawMessage(fmt.Sprintf(`{"role":"user","content":"message %d"}`, i)),
+		})
+	}
+	conv := &history.Conversation{
+		Meta:    history.ConversationMeta{ID: "abc123"},
+		Entries: entries,
+	}
+
+	disp := NewConversationDisplay(ConversationDisplayOptions{
+		Writer:     &bytes.Buffer{},
+		Reverse:    true,
+		Pagination: PaginationOptions{First: 2},
+	})
+	if last, _ := disp.LastShown(); last != nil {
+		t.Errorf("LastShown() before Render = %v, want nil", last.UUID)
+	}
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	last, index := disp.LastShown()
+	if last == nil || last.UUID != "uuid-2" || index != 2 {
+		t.Errorf("LastShown() = %v, %d; want uuid-2, 2", last, index)
+	}
+}
+
+func TestConversationDisplay_ToolTruncation(t *testing.T) {
+	longOutput := strings.Repeat("x", 600)
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123"},
+		Entries: []*jsonl.RawEntry{
+			{
+				Type:    jsonl.EntryTypeUser,
+				Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"` + longOutput + `"}]}`),
+			},
+		},
+	}
+
+	render := func(maxLen int) string {
+		var buf bytes.Buffer
+		disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, ShowTools: true, ToolResultMaxLen: maxLen})
+		if err := disp.Render(conv); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		return buf.String()
+	}
+
+	if out := render(DefaultToolResultMaxLen); strings.Contains(out, longOutput) {
+		t.Error("tool result should be truncated at the default limit")
+	}
+	if out := render(0); !strings.Contains(out, longOutput) {
+		t.Error("tool result should not be truncated when the limit is 0")
+	}
+}
+
+func TestConversationDisplay_SanitizeOutput(t *testing.T) {
+	block := &jsonl.ContentBlock{Type: jsonl.BlockTypeText, Text: "bin\x1b[2Jary"}
+
+	var buf bytes.Buffer
+	NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, SanitizeOutput: true}).renderTextBlock(block)
+	if got := buf.String(); got != "bin\\x1b[2Jary\n" {
+		t.Errorf("sanitized output = %q, want escape sequence marked", got)
+	}
+
+	buf.Reset()
+	NewConversationDisplay(ConversationDisplayOptions{Writer: &buf}).renderTextBlock(block)
+	if got := buf.String(); got != "bin\x1b[2Jary\n" {
+		t.Errorf("unsanitized output = %q, want bytes unchanged", got)
+	}
+}
+
+func TestTruncateTo_RuneBoundary(t *testing.T) {
+	if got := truncateTo("ab✓✓", 4); got != "ab..." {
+		t.Errorf("truncateTo() = %q, want cut before the split rune", got)
+	}
+	if got := truncateTo("abcdef", 3); got != "abc..." {
+		t.Errorf("truncateTo() = %q, want abc...", got)
+	}
+}
+
+func TestConversationDisplay_ToolResultArrayContent(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123"},
+		Entries: []*jsonl.RawEntry{
+			{
+				Type:    jsonl.EntryTypeUser,
+				Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"file contents here"}]}]}`),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, ShowTools: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "file contents here") {
+		t.Errorf("expected array tool_result text in output, got: %s", buf.String())
+	}
+}
+
+func TestConversationDisplay_ToolFilter(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123"},
+		Entries: []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":"list files"}`)},
+			{Type: jsonl.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}}]}`)},
+			{Type: jsonl.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"a.go"}}]}`)},
+		},
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, JSON: true, ToolFilter: "Bash"})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	var result struct {
+		Messages []struct {
+			Index int `json:"index"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if len(result.Messages) != 1 || result.Messages[0].Index != 2 {
+		t.Errorf("expected only message 2 to match Bash, got %+v", result.Messages)
+	}
+}
+
+func TestConversationDisplay_SkippedLinesFooter(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123"},
+		Entries: []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":"hello"}`)},
+		},
+		SkippedLines: []jsonl.LineError{{Line: 2}, {Line: 5}},
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "2 malformed line(s) skipped.") {
+		t.Errorf("expected skipped lines note in footer, got:\n%s", buf.String())
+	}
+}
+
+func TestConversationDisplay_Metadata(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123"},
+		Entries: []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeUser, UUID: "u1", SessionID: "s1", Timestamp: "2024-01-01T10:00:00Z", Message: json.RawMessage(`{"role":"user","content":"secret body"}`)},
+			{Type: jsonl.EntryTypeAssistant, UUID: "u2", ParentUUID: "u1", SessionID: "s1", IsSidechain: true},
+		},
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, Metadata: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "u2") || !strings.Contains(out, "2024-01-01T10:00:00Z") {
+		t.Errorf("expected entry metadata in output, got:\n%s", out)
+	}
+	if strings.Contains(out, "secret body") {
+		t.Error("metadata view should not include message bodies")
+	}
+
+	buf.Reset()
+	disp = NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, Metadata: true, JSON: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	var result struct {
+		Entries []struct {
+			Index       int    `json:"index"`
+			ParentUUID  string `json:"parent_uuid"`
+			IsSidechain bool   `json:"is_sidechain"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if len(result.Entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(result.Entries))
+	}
+	if e := result.Entries[1]; e.Index != 2 || e.ParentUUID != "u1" || !e.IsSidechain {
+		t.Errorf("entries[1] = %+v, want index 2, parent u1, sidechain", e)
+	}
+}
+
+func TestConversationDisplay_MetadataQueue(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123"},
+		Entries: []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeUser, UUID: "u1", Message: json.RawMessage(`{"role":"user","content":"hi"}`)},
+			{Type: jsonl.EntryTypeQueueOp, Operation: "enqueue", Content: json.RawMessage(`"run the tests too"`), Timestamp: "2024-01-01T10:00:05Z"},
+			{Type: jsonl.EntryTypeAssistant, UUID: "u2", ParentUUID: "u1"},
+		},
+	}
+
+	render := func(showQueue bool) []struct {
+		Index     int    `json:"index"`
+		Type      string `json:"type"`
+		Operation string `json:"operation"`
+		Content   string `json:"content"`
+	} {
+		var buf bytes.Buffer
+		disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, Metadata: true, JSON: true, ShowQueue: showQueue})
+		if err := disp.Render(conv); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		var result struct {
+			Entries []struct {
+				Index     int    `json:"index"`
+				Type      string `json:"type"`
+				Operation string `json:"operation"`
+				Content   string `json:"content"`
+			} `json:"entries"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+			t.Fatalf("Failed to parse JSON: %v", err)
+		}
+		return result.Entries
+	}
+
+	entries := render(false)
+	if len(entries) != 2 || entries[1].Index != 3 {
+		t.Errorf("without ShowQueue = %+v, want the two messages at indices 1 and 3", entries)
+	}
+
+	entries = render(true)
+	if len(entries) != 3 {
+		t.Fatalf("len(entries) = %d, want 3", len(entries))
+	}
+	if q := entries[1]; q.Type != "queue-operation" || q.Operation != "enqueue" || q.Content != "run the tests too" {
+		t.Errorf("queue entry = %+v, want enqueue of the queued prompt", q)
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, Metadata: true, ShowQueue: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "enqueue run the tests too") {
+		t.Errorf("expected the queue operation in the table, got:\n%s", buf.String())
+	}
+}
+
+func TestConversationDisplay_MetadataSystemContent(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123"},
+		Entries: []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeSystem, UUID: "s1", Content: json.RawMessage(`"Conversation compacted: secret summary"`)},
+		},
+	}
+
+	for _, showQueue := range []bool{false, true} {
+		var buf bytes.Buffer
+		disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, Metadata: true, JSON: true, ShowQueue: showQueue})
+		if err := disp.Render(conv); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if strings.Contains(buf.String(), "secret summary") || strings.Contains(buf.String(), `"content"`) {
+			t.Errorf("ShowQueue %v: system entry content leaked into metadata:\n%s", showQueue, buf.String())
+		}
+	}
+}
+
+func TestConversationDisplay_ShowTokens(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123"},
+		Entries: []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":"hi"}`)},
+			{Type: jsonl.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":"hello","usage":{"input_tokens":34,"cache_read_input_tokens":1200,"output_tokens":567}}`)},
+			{Type: jsonl.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":"no usage here"}`)},
+		},
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, ShowTokens: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	out := buf.String()
+	if strings.Count(out, "tokens)") != 1 || !strings.Contains(out, "(in: 1234, out: 567 tokens)") {
+		t.Errorf("expected usage on the one assistant message recording it, got:\n%s", out)
+	}
+
+	buf.Reset()
+	disp = NewConversationDisplay(ConversationDisplayOptions{Writer: &buf})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "tokens)") {
+		t.Errorf("usage should only be shown with ShowTokens, got:\n%s", buf.String())
+	}
+}
+
+func TestConversationDisplay_Reverse(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123"},
+		Entries: []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":"first"}`)},
+			{Type: jsonl.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":"second"}`)},
+			{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":"third"}`)},
+		},
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, ShowNumbering: true, Reverse: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	out := buf.String()
+	if strings.Index(out, "third") > strings.Index(out, "first") {
+		t.Errorf("expected newest message first, got:\n%s", out)
+	}
+	if !strings.Contains(out, "[3]") {
+		t.Errorf("expected original index [3] to be kept, got:\n%s", out)
+	}
+
+	buf.Reset()
+	disp = NewConversationDisplay(ConversationDisplayOptions{
+		Writer:     &buf,
+		JSON:       true,
+		Reverse:    true,
+		Pagination: PaginationOptions{Last: 2},
+	})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	var result struct {
+		Messages []struct {
+			Index int `json:"index"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if len(result.Messages) != 2 || result.Messages[0].Index != 3 || result.Messages[1].Index != 2 {
+		t.Errorf("expected indices [3 2], got %+v", result.Messages)
+	}
+}
+
+func TestConversationDisplay_HideSystem(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123"},
+		Entries: []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":"hello"}`)},
+			{Type: jsonl.EntryTypeSystem, Message: json.RawMessage(`{"role":"system","content":"reminder noise"}`)},
+			{Type: jsonl.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":"hi"}`)},
+		},
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, ShowNumbering: true, HideSystem: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "reminder noise") {
+		t.Errorf("system message should be hidden, got:\n%s", out)
+	}
+	if !strings.Contains(out, "[2]") || strings.Contains(out, "[3]") {
+		t.Errorf("expected indices to skip system messages, got:\n%s", out)
+	}
+
+	buf.Reset()
+	disp = NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, JSON: true, HideSystem: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	var result struct {
+		TotalMessages int `json:"total_messages"`
+		ShownMessages int `json:"shown_messages"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if result.TotalMessages != 2 || result.ShownMessages != 2 {
+		t.Errorf("counts = %d/%d, want 2/2", result.ShownMessages, result.TotalMessages)
+	}
+
+	buf.Reset()
+	disp = NewConversationDisplay(ConversationDisplayOptions{Writer: &buf})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "reminder noise") {
+		t.Errorf("system message should be shown by default, got:\n%s", buf.String())
+	}
+}
+
+func TestConversationDisplay_HeaderFooter(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123", ProjectPath: "/tmp/project"},
+		Entries: []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":"hello"}`)},
+		},
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Project:") || !strings.Contains(buf.String(), "Resume:") {
+		t.Errorf("expected header and footer, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	disp = NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, HideHeader: true, HideFooter: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "Project:") || strings.Contains(out, "Resume:") {
+		t.Errorf("expected message-only output, got:\n%s", out)
+	}
+	if !strings.Contains(out, "hello") {
+		t.Errorf("expected message text, got:\n%s", out)
+	}
+}
+
+func TestConversationDisplay_UnknownBlocks(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123"},
+		Entries: []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"redacted_thinking","data":"xyz"},{"type":"server_tool_use","name":"web_search"}]}`)},
+		},
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, ShowThinking: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"[redacted thinking]", "[unknown block: server_tool_use]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "web_search") {
+		t.Errorf("raw JSON should only be shown in verbose mode, got:\n%s", out)
+	}
+
+	buf.Reset()
+	disp = NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, Verbose: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	out = buf.String()
+	if !strings.Contains(out, `"name": "web_search"`) {
+		t.Errorf("expected raw JSON in verbose mode, got:\n%s", out)
+	}
+	if strings.Contains(out, "[redacted thinking]") {
+		t.Errorf("redacted thinking should follow ShowThinking, got:\n%s", out)
+	}
+}
+
+func TestConversationDisplay_ErrorsOnly(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123"},
+		Entries: []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}}]}`)},
+			{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok output"}]}`)},
+			{Type: jsonl.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Bash","input":{"command":"false"}}]}`)},
+			{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","content":"exit status 1","is_error":true}]}`)},
+		},
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, ShowTools: true, ShowNumbering: true, ErrorsOnly: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "[4]") || !strings.Contains(out, "exit status 1") {
+		t.Errorf("expected failed result [4] in output, got:\n%s", out)
+	}
+	if strings.Contains(out, "ok output") {
+		t.Errorf("successful results should be filtered out, got:\n%s", out)
+	}
+	if !strings.Contains(out, "1 of 4 messages") {
+		t.Errorf("expected filter summary, got:\n%s", out)
+	}
+}
+
+func TestConversationDisplay_ASCIISeparators(t *testing.T) {
+	var entries []*jsonl.RawEntry
+	for i := 0; i < 6; i++ {
+		entries = append(entries, &jsonl.RawEntry{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":"hello"}`)})
+	}
+	conv := &history.Conversation{Meta: history.ConversationMeta{ID: "abc123"}, Entries: entries}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{
+		Writer:         &buf,
+		ASCII:          true,
+		SeparatorWidth: 30,
+		Pagination:     PaginationOptions{First: 1, Last: 1},
+	})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	out := buf.String()
+	if strings.ContainsAny(out, "─·") {
+		t.Errorf("expected only ASCII separators, got:\n%s", out)
+	}
+	if !strings.Contains(out, strings.Repeat("-", 30)+"\n") || !strings.Contains(out, strings.Repeat(".", 20)+"\n") {
+		t.Errorf("expected 30-wide rule and 20-wide gap line, got:\n%s", out)
+	}
+}
+
+func TestPrefersASCII(t *testing.T) {
+	tests := []struct {
+		lcAll, lang string
+		want        bool
+	}{
+		{"", "", false},
+		{"", "en_US.UTF-8", false},
+		{"", "C", true},
+		{"C.UTF-8", "C", false},
+		{"POSIX", "en_US.utf8", true},
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_CTYPE", "")
+		t.Setenv("LANG", tt.lang)
+		if got := PrefersASCII(); got != tt.want {
+			t.Errorf("PrefersASCII() with LC_ALL=%q LANG=%q = %v, want %v", tt.lcAll, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package display
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// NewJSONEncoder returns a JSON encoder for w. Output is indented by two
+// spaces unless compact is set, in which case each value is a single line.
+func NewJSONEncoder(w io.Writer, compact bool) *json.Encoder {
+	encoder := json.NewEncoder(w)
+	if !compact {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder
+}
@@ -0,0 +1,122 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/jsonl"
+)
+
+// renderMarkdown renders the conversation as a Markdown document.
+// Honors the same thinking/tools/pagination options as the formatted view.
+func (d *ConversationDisplay) renderMarkdown(conv *history.Conversation) error {
+	w := d.opts.Writer
+
+	title := "Conversation"
+	if conv.Meta.IsAgent {
+		title = "Agent Conversation"
+	}
+	fmt.Fprintf(w, "# %s %s\n\n", title, conv.Meta.ID)
+
+	if conv.Meta.ParentSessionID != "" {
+		fmt.Fprintf(w, "- **Parent:** %s\n", conv.Meta.ParentSessionID)
+	}
+	fmt.Fprintf(w, "- **Project:** %s\n", conv.Meta.ProjectPath)
+	if !conv.Meta.Timestamp.IsZero() {
+		fmt.Fprintf(w, "- **Time:** %s\n", FormatTime(conv.Meta.Timestamp, d.opts.TimeFormat))
+	}
+	fmt.Fprintf(w, "- **Messages:** %d\n", conv.Meta.MessageCount)
+	if conv.Meta.Model != "" {
+		fmt.Fprintf(w, "- **Model:** %s\n", conv.Meta.Model)
+	}
+
+	messages, _ := d.filterMessages(conv.Entries)
+	if d.opts.Reverse {
+		messages = slices.Clone(messages)
+		slices.Reverse(messages)
+	}
+	for _, entry := range messages {
+		d.renderMarkdownEntry(entry)
+	}
+	return nil
+}
+
+// renderMarkdownEntry renders a single message as a Markdown section.
+func (d *ConversationDisplay) renderMarkdownEntry(entry *jsonl.RawEntry) {
+	msg, err := jsonl.ParseMessage(entry)
+	if err != nil || msg == nil || !d.hasVisibleContent(msg) {
+		return
+	}
+
+	w := d.opts.Writer
+	role := "User"
+	switch entry.Type {
+	case jsonl.EntryTypeAssistant:
+		role = "Assistant"
+	case jsonl.EntryTypeSystem:
+		role = "System"
+	}
+
+	fmt.Fprintf(w, "\n---\n\n## %s", role)
+	if t, err := time.Parse(time.RFC3339, entry.Timestamp); err == nil && !t.IsZero() {
+		fmt.Fprintf(w, " (%s)", t.Format("15:04:05"))
+	}
+	fmt.Fprintln(w)
+
+	for _, block := range msg.Content {
+		d.renderMarkdownBlock(&block)
+	}
+}
+
+// renderMarkdownBlock renders a content block as Markdown.
+func (d *ConversationDisplay) renderMarkdownBlock(block *jsonl.ContentBlock) {
+	w := d.opts.Writer
+
+	switch block.Type {
+	case jsonl.BlockTypeText:
+		if block.Text != "" {
+			fmt.Fprintf(w, "\n%s\n", block.Text)
+		}
+	case jsonl.BlockTypeThinking:
+		if d.opts.ShowThinking && block.Thinking != "" {
+			fmt.Fprintf(w, "\n<details>\n<summary>Thinking</summary>\n\n%s\n\n</details>\n", block.Thinking)
+		}
+	case jsonl.BlockTypeToolUse:
+		if !d.opts.ShowTools {
+			return
+		}
+		fmt.Fprintf(w, "\n**Tool:** `%s`\n", block.Name)
+		if len(block.Input) > 0 {
+			input, err := json.MarshalIndent(block.Input, "", "  ")
+			if err != nil {
+				input = block.Input
+			}
+			fmt.Fprintf(w, "\n```json\n%s\n```\n", truncateTo(string(input), d.opts.ToolInputMaxLen))
+		}
+	case jsonl.BlockTypeToolResult:
+		if !d.opts.ShowTools {
+			return
+		}
+		status := "OK"
+		if block.IsError {
+			status = "ERROR"
+		}
+		fmt.Fprintf(w, "\n**Result:** %s\n", status)
+		if content := jsonl.ToolResultText(block); content != "" {
+			content = truncateTo(content, d.opts.ToolResultMaxLen)
+			fmt.Fprintf(w, "\n```\n%s\n```\n", strings.TrimRight(content, "\n"))
+		}
+	case jsonl.BlockTypeRedactedThinking:
+		if d.opts.ShowThinking {
+			fmt.Fprintf(w, "\n_[redacted thinking]_\n")
+		}
+	case jsonl.BlockTypeImage:
+		// Images aren't embedded in exports
+	default:
+		fmt.Fprintf(w, "\n_[unknown block: %s]_\n", block.Type)
+	}
+}
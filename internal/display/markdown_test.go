@@ -0,0 +1,48 @@
+package display
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/jsonl"
+)
+
+func TestConversationDisplay_RenderMarkdown(t *testing.T) {
+	conv := &history.Conversation{
+		Meta: history.ConversationMeta{ID: "abc123", ProjectPath: "/Users/test/project", MessageCount: 2},
+		Entries: []*jsonl.RawEntry{
+			{
+				Type:      jsonl.EntryTypeUser,
+				Timestamp: "2024-01-01T10:00:00Z",
+				Message:   json.RawMessage(`{"role":"user","content":"Hello"}`),
+			},
+			{
+				Type:      jsonl.EntryTypeAssistant,
+				Timestamp: "2024-01-01T10:00:01Z",
+				Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"thinking","thinking":"pondering"},{"type":"text","text":"Hi!"},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}}]}`),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, Markdown: true, ShowTools: true})
+	if err := disp.Render(conv); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{"# Conversation abc123", "## User (10:00:00)", "## Assistant (10:00:01)", "Hi!", "**Tool:** `Bash`"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Markdown output missing %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "pondering") {
+		t.Error("thinking should be omitted when ShowThinking is false")
+	}
+	if strings.Contains(output, "\x1b[") {
+		t.Error("Markdown output should not contain ANSI escapes")
+	}
+}
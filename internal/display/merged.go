@@ -0,0 +1,80 @@
+package display
+
+import (
+	"fmt"
+
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/jsonl"
+)
+
+// mergedMessage is the per-message view rendered by RenderMerged with JSON.
+type mergedMessage struct {
+	Agent     string           `json:"agent"`
+	Type      string           `json:"type"`
+	Timestamp any              `json:"timestamp,omitempty"`
+	Role      string           `json:"role,omitempty"`
+	Model     string           `json:"model,omitempty"`
+	Text      string           `json:"text,omitempty"`
+	Thinking  string           `json:"thinking,omitempty"`
+	ToolCalls []jsonl.ToolCall `json:"tool_calls,omitempty"`
+}
+
+// RenderMerged renders a transcript interleaved from several conversations
+// (see history.MergeConversations). Each message is labeled with the short
+// ID of the conversation it came from; sessionID names the parent session.
+func (d *ConversationDisplay) RenderMerged(sessionID string, entries []*history.LabeledEntry) error {
+	if d.opts.JSON {
+		return d.renderMergedJSON(sessionID, entries)
+	}
+
+	if !d.opts.HideHeader {
+		fmt.Fprintln(d.opts.Writer)
+		fmt.Fprintf(d.opts.Writer, "%s %s\n", Title("Merged Agents"), ID(sessionID))
+		fmt.Fprintln(d.opts.Writer)
+		fmt.Fprintln(d.opts.Writer, d.separator())
+	}
+
+	for _, le := range entries {
+		if d.isCountedMessage(le.Entry) {
+			d.renderLabeledEntry(le.Entry, 0, "agent-"+history.ShortID(le.Label))
+		}
+	}
+	fmt.Fprintln(d.opts.Writer)
+	return nil
+}
+
+// renderMergedJSON renders the merged transcript as a single JSON object.
+func (d *ConversationDisplay) renderMergedJSON(sessionID string, entries []*history.LabeledEntry) error {
+	messages := []mergedMessage{}
+	for _, le := range entries {
+		if !d.isCountedMessage(le.Entry) {
+			continue
+		}
+		mm := mergedMessage{
+			Agent:     le.Label,
+			Type:      string(le.Entry.Type),
+			Timestamp: JSONEntryTime(le.Entry.Timestamp, d.opts.TimeLayout),
+		}
+		if msg, _ := jsonl.ParseMessage(le.Entry); msg != nil {
+			mm.Role = msg.Role
+			mm.Model = msg.Model
+			mm.Text = jsonl.ExtractText(msg)
+			if d.opts.ShowThinking {
+				mm.Thinking = jsonl.ExtractThinking(msg)
+			}
+			if d.opts.ShowTools {
+				mm.ToolCalls = jsonl.ExtractToolCallDetails(msg)
+			}
+		}
+		messages = append(messages, mm)
+	}
+
+	encoder := NewJSONEncoder(d.opts.Writer, d.opts.Compact)
+	return encoder.Encode(struct {
+		SessionID string          `json:"session_id"`
+		Messages  []mergedMessage `json:"messages"`
+	}{
+		SessionID: sessionID,
+		Messages:  messages,
+	})
+}
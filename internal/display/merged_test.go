@@ -0,0 +1,45 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/jsonl"
+)
+
+func TestConversationDisplay_RenderMerged(t *testing.T) {
+	SetColorEnabled(false)
+	defer SetColorEnabled(true)
+
+	entries := []*history.LabeledEntry{
+		{Label: "aaaa1111", Entry: &jsonl.RawEntry{Type: jsonl.EntryTypeUser, Message: []byte(`{"role":"user","content":"first task"}`)}},
+		{Label: "bbbb2222", Entry: &jsonl.RawEntry{Type: jsonl.EntryTypeUser, Message: []byte(`{"role":"user","content":"second task"}`)}},
+		{Label: "aaaa1111", Entry: &jsonl.RawEntry{Type: jsonl.EntryTypeSummary, Summary: "not a message"}},
+	}
+
+	var buf bytes.Buffer
+	disp := NewConversationDisplay(ConversationDisplayOptions{Writer: &buf})
+	if err := disp.RenderMerged("session1", entries); err != nil {
+		t.Fatalf("RenderMerged() error = %v", err)
+	}
+	out := buf.String()
+	first := strings.Index(out, "[agent-aaaa1111] User")
+	second := strings.Index(out, "[agent-bbbb2222] User")
+	if first < 0 || second < first {
+		t.Errorf("expected labeled messages in merge order, got:\n%s", out)
+	}
+	if strings.Contains(out, "not a message") {
+		t.Errorf("non-message entries should be skipped, got:\n%s", out)
+	}
+
+	buf.Reset()
+	disp = NewConversationDisplay(ConversationDisplayOptions{Writer: &buf, JSON: true, Compact: true})
+	if err := disp.RenderMerged("session1", entries); err != nil {
+		t.Fatalf("RenderMerged() JSON error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `{"agent":"bbbb2222","type":"user","role":"user","text":"second task"}`) {
+		t.Errorf("unexpected JSON output: %s", buf.String())
+	}
+}
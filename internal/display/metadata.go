@@ -0,0 +1,134 @@
+package display
+
+import (
+	"fmt"
+
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/jsonl"
+	"github.com/olekukonko/tablewriter"
+)
+
+// entryMetadata is the per-entry view rendered by --metadata.
+type entryMetadata struct {
+	Index       int    `json:"index"` // 1-based position among parsed entries
+	Type        string `json:"type"`
+	UUID        string `json:"uuid,omitempty"`
+	ParentUUID  string `json:"parent_uuid,omitempty"`
+	SessionID   string `json:"session_id,omitempty"`
+	AgentID     string `json:"agent_id,omitempty"`
+	Timestamp   string `json:"-"`                   // As recorded, for the table
+	JSONTime    any    `json:"timestamp,omitempty"` // Timestamp in the JSON time layout
+	IsSidechain bool   `json:"is_sidechain"`
+	Operation   string `json:"operation,omitempty"` // Queue operations only
+	Content     string `json:"content,omitempty"`   // Queued prompt, queue operations only
+}
+
+// queuePreviewLen is the width of queued prompts in the metadata table.
+const queuePreviewLen = 40
+
+// renderMetadata renders the structural fields of every entry without message bodies.
+// Useful for debugging how entries and agents link to their parents. Queue
+// operations are omitted unless ShowQueue is set; indices count them either
+// way, so they match the entry's position in the file.
+func (d *ConversationDisplay) renderMetadata(conv *history.Conversation) error {
+	rows := make([]entryMetadata, 0, len(conv.Entries))
+	for i, e := range conv.Entries {
+		if e.Type == jsonl.EntryTypeQueueOp && !d.opts.ShowQueue {
+			continue
+		}
+		row := entryMetadata{
+			Index:       i + 1,
+			Type:        string(e.Type),
+			UUID:        e.UUID,
+			ParentUUID:  e.ParentUUID,
+			SessionID:   e.SessionID,
+			AgentID:     e.AgentID,
+			Timestamp:   e.Timestamp,
+			IsSidechain: e.IsSidechain,
+		}
+		// Other entries (e.g. system compact boundaries) may have a top-level
+		// content too, but it's a body the metadata view leaves out
+		if e.Type == jsonl.EntryTypeQueueOp {
+			row.Operation = e.Operation
+			row.Content = e.QueueContent()
+		}
+		rows = append(rows, row)
+	}
+
+	if d.opts.JSON {
+		for i := range rows {
+			rows[i].JSONTime = JSONEntryTime(rows[i].Timestamp, d.opts.TimeLayout)
+		}
+		encoder := NewJSONEncoder(d.opts.Writer, d.opts.Compact)
+		return encoder.Encode(struct {
+			ID      string          `json:"id"`
+			Entries []entryMetadata `json:"entries"`
+		}{
+			ID:      conv.Meta.ID,
+			Entries: rows,
+		})
+	}
+
+	if len(rows) == 0 {
+		fmt.Fprintln(d.opts.Writer, Dim("No entries found"))
+		return nil
+	}
+
+	fmt.Fprintf(d.opts.Writer, "\n%s %s\n", Title("Entry Metadata"), ID(conv.Meta.ID))
+	fmt.Fprintf(d.opts.Writer, "%s %d\n\n", Dim("Entries:"), len(rows))
+
+	table := tablewriter.NewWriter(d.opts.Writer)
+	header := []string{"#", "Type", "UUID", "Parent", "Session", "Timestamp", "Sidechain"}
+	if d.opts.ShowQueue {
+		header = append(header, "Queue")
+	}
+	table.SetHeader(header)
+	table.SetBorder(false)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetTablePadding("  ")
+	table.SetNoWhiteSpace(true)
+	table.SetAutoWrapText(false)
+
+	for _, r := range rows {
+		sidechain := ""
+		if r.IsSidechain {
+			sidechain = Info("yes")
+		}
+		row := []string{
+			fmt.Sprintf("%d", r.Index),
+			r.Type,
+			orDash(r.UUID),
+			orDash(r.ParentUUID),
+			orDash(r.SessionID),
+			orDash(r.Timestamp),
+			sidechain,
+		}
+		if d.opts.ShowQueue {
+			row = append(row, queueCell(r))
+		}
+		table.Append(row)
+	}
+
+	table.Render()
+	return nil
+}
+
+// queueCell formats a queue operation and a preview of its prompt.
+func queueCell(r entryMetadata) string {
+	if r.Operation == "" {
+		return ""
+	}
+	if r.Content == "" {
+		return ToolName(r.Operation)
+	}
+	return ToolName(r.Operation) + " " + Dim(truncateString(r.Content, queuePreviewLen))
+}
+
+// orDash returns s, or a dimmed dash if s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return Dim("-")
+	}
+	return s
+}
@@ -1,10 +1,13 @@
 package display
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,10 +17,23 @@ import (
 
 // TableOptions configures table output.
 type TableOptions struct {
-	Writer      io.Writer
-	ShowAgent   bool // Show agent indicator
-	JSON        bool // Output as JSON
-	ShowIndices bool // Show message indices in search results
+	Writer         io.Writer
+	ShowAgent      bool                // Show agent indicator
+	JSON           bool                // Output as JSON
+	Compact        bool                // With JSON, emit single-line output instead of indented
+	CSV            bool                // Output as CSV with a header row
+	IDOnly         bool                // Output only conversation IDs, one per line (agents as agent-<id>)
+	ShowIndices    bool                // Show message indices in search results
+	CountOnly      bool                // Render only per-conversation match counts (search results)
+	GroupByProject bool                // Group search results under per-project subheaders
+	ShowCWD        bool                // Show the recorded working directory column
+	ShowDuration   bool                // Show the session duration column
+	ShowFullID     bool                // Show complete conversation IDs instead of short IDs
+	TimeFormat     string              // Time column format: relative (default), absolute, or a Go layout
+	TimeLayout     string              // JSON timestamp layout: rfc3339 (default), unix, unixmilli, or a Go layout
+	PreviewLen     int                 // Preview column width in characters (default: DefaultPreviewWidth)
+	Width          int                 // Terminal width the preview column fills when PreviewLen is unset (0 = unknown)
+	Tags           map[string][]string // User tags keyed by conversation ID
 
 	// Context for headers/footers
 	ProjectPath    string // Current project path (empty if global)
@@ -26,8 +42,25 @@ type TableOptions struct {
 	TotalAgents    int    // Total agents across all shown conversations
 	CurrentProject string // Current working directory's project (for marking)
 	Query          string // Search query (for search results)
+	TotalMatched   int    // Total matching conversations before limit (for search results)
+
+	// Trailing search summary (search --stat)
+	ShowStat     bool          // Print the summary line after the results
+	FilesScanned int           // Files searched
+	TotalMatches int           // Matches across all matching conversations, before limit
+	Elapsed      time.Duration // Time the search took
 }
 
+// DefaultPreviewWidth is the default width of the conversation preview column.
+const DefaultPreviewWidth = 60
+
+// minPreviewWidth is the narrowest the preview column shrinks to when fitting
+// the table to the terminal width.
+const minPreviewWidth = 20
+
+// tablePadding separates table columns.
+const tablePadding = "  "
+
 // DefaultTableOptions returns default table options.
 func DefaultTableOptions() TableOptions {
 	return TableOptions{
@@ -53,46 +86,109 @@ func (t *ConversationTable) Render(conversations []*history.ConversationMeta) er
 	if t.opts.JSON {
 		return t.renderJSON(conversations)
 	}
+	if t.opts.CSV {
+		return t.renderCSV(conversations)
+	}
+	if t.opts.IDOnly {
+		return t.renderIDs(conversations)
+	}
 	return t.renderTable(conversations)
 }
 
+// renderIDs prints one undecorated ID per line, short unless ShowFullID is
+// set. Agents get the agent- prefix so the IDs resolve to them in other commands.
+func (t *ConversationTable) renderIDs(conversations []*history.ConversationMeta) error {
+	for _, c := range conversations {
+		id := history.ShortID(c.ID)
+		if t.opts.ShowFullID {
+			id = c.ID
+		}
+		if c.IsAgent {
+			id = "agent-" + id
+		}
+		if _, err := fmt.Fprintln(t.opts.Writer, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (t *ConversationTable) renderJSON(conversations []*history.ConversationMeta) error {
 	type jsonConversation struct {
-		ID         string `json:"id"`
-		SessionID  string `json:"session_id,omitempty"`
-		Project    string `json:"project"`
-		Timestamp  string `json:"timestamp"`
-		Preview    string `json:"preview"`
-		Messages   int    `json:"messages"`
-		IsAgent    bool   `json:"is_agent,omitempty"`
-		AgentCount int    `json:"agent_count,omitempty"`
-		Model      string `json:"model,omitempty"`
-		FileSize   int64  `json:"file_size"`
-		Path       string `json:"path"`
+		ID              string   `json:"id"`
+		SessionID       string   `json:"session_id,omitempty"`
+		Project         string   `json:"project"`
+		Timestamp       any      `json:"timestamp"`
+		TimeSource      string   `json:"time_source,omitempty"`
+		Preview         string   `json:"preview"`
+		Messages        int      `json:"messages"`
+		IsAgent         bool     `json:"is_agent,omitempty"`
+		AgentCount      int      `json:"agent_count,omitempty"`
+		Model           string   `json:"model,omitempty"`
+		CWD             string   `json:"cwd,omitempty"`
+		Branch          string   `json:"branch,omitempty"`
+		Platform        string   `json:"platform,omitempty"`
+		Duration        int64    `json:"duration_seconds,omitempty"`
+		FileSize        int64    `json:"file_size"`
+		EstimatedTokens int64    `json:"estimated_tokens"` // file_size / 4; overcounts due to JSON overhead
+		Path            string   `json:"path"`
+		Tags            []string `json:"tags,omitempty"`
 	}
 
 	output := make([]jsonConversation, len(conversations))
 	for i, c := range conversations {
 		output[i] = jsonConversation{
-			ID:         c.ID,
-			SessionID:  c.SessionID,
-			Project:    c.ProjectPath,
-			Timestamp:  c.Timestamp.Format(time.RFC3339),
-			Preview:    c.Preview,
-			Messages:   c.MessageCount,
-			IsAgent:    c.IsAgent,
-			AgentCount: c.AgentCount,
-			Model:      c.Model,
-			FileSize:   c.FileSize,
-			Path:       c.Path,
+			ID:              c.ID,
+			SessionID:       c.SessionID,
+			Project:         c.ProjectPath,
+			Timestamp:       JSONTime(c.Timestamp, t.opts.TimeLayout),
+			TimeSource:      c.TimeSource,
+			Preview:         c.Preview,
+			Messages:        c.MessageCount,
+			IsAgent:         c.IsAgent,
+			AgentCount:      c.AgentCount,
+			Model:           c.Model,
+			CWD:             c.CWD,
+			Branch:          c.Branch,
+			Platform:        c.Platform,
+			Duration:        int64(c.Duration.Seconds()),
+			FileSize:        c.FileSize,
+			EstimatedTokens: c.FileSize / 4,
+			Path:            c.Path,
+			Tags:            t.opts.Tags[c.ID],
 		}
 	}
 
-	encoder := json.NewEncoder(t.opts.Writer)
-	encoder.SetIndent("", "  ")
+	encoder := NewJSONEncoder(t.opts.Writer, t.opts.Compact)
 	return encoder.Encode(output)
 }
 
+// renderCSV writes one row per conversation. encoding/csv quotes previews
+// containing commas, quotes, or newlines.
+func (t *ConversationTable) renderCSV(conversations []*history.ConversationMeta) error {
+	w := csv.NewWriter(t.opts.Writer)
+	if err := w.Write([]string{"id", "project", "timestamp", "messages", "size", "model", "is_agent", "preview"}); err != nil {
+		return err
+	}
+	for _, c := range conversations {
+		record := []string{
+			c.ID,
+			c.ProjectPath,
+			c.Timestamp.Format(time.RFC3339),
+			strconv.Itoa(c.MessageCount),
+			strconv.FormatInt(c.FileSize, 10),
+			c.Model,
+			strconv.FormatBool(c.IsAgent),
+			c.Preview,
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
 func (t *ConversationTable) renderTable(conversations []*history.ConversationMeta) error {
 	if len(conversations) == 0 {
 		fmt.Fprintln(t.opts.Writer, Dim("No conversations found"))
@@ -102,28 +198,73 @@ func (t *ConversationTable) renderTable(conversations []*history.ConversationMet
 	// Context header
 	t.renderContextHeader(len(conversations))
 
-	table := tablewriter.NewWriter(t.opts.Writer)
-	table.SetHeader([]string{"ID", "Time", "Messages", "Preview"})
-	table.SetBorder(false)
-	table.SetHeaderLine(false)
-	table.SetColumnSeparator("")
-	table.SetTablePadding("  ")
-	table.SetNoWhiteSpace(true)
-	table.SetAutoWrapText(false)
+	showTags := t.hasTags(conversations)
+	header := []string{"ID", "Time", "Messages", "Preview"}
+	if t.opts.ShowDuration {
+		header = append(header, "Duration")
+	}
+	if t.opts.ShowCWD {
+		header = append(header, "CWD")
+	}
+	if showTags {
+		header = append(header, "Tags")
+	}
+
+	timeFormat := t.opts.TimeFormat
+	if timeFormat == "" {
+		timeFormat = TimeFormatRelative
+	}
 
+	// The preview is filled in once the other columns' widths are known
+	const previewCol = 3
+	rows := make([][]string, 0, len(conversations))
 	for _, c := range conversations {
 		id := history.ShortID(c.ID)
+		if t.opts.ShowFullID {
+			id = c.ID
+		}
 		if c.IsAgent {
 			id = Dim("agent-") + id
 		} else if c.AgentCount > 0 {
 			id = id + Dim(fmt.Sprintf(" [+%d]", c.AgentCount))
 		}
 
-		timestamp := formatRelativeTime(c.Timestamp)
+		timestamp := Dim(timeCell(c, timeFormat))
 		messages := fmt.Sprintf("%d", c.MessageCount)
-		preview := truncateString(c.Preview, 60)
 
-		table.Append([]string{id, timestamp, messages, preview})
+		row := []string{id, timestamp, messages, ""}
+		if t.opts.ShowDuration {
+			row = append(row, durationCell(c.Duration))
+		}
+		if t.opts.ShowCWD {
+			row = append(row, Project(c.CWD))
+		}
+		if showTags {
+			row = append(row, Info(strings.Join(t.opts.Tags[c.ID], ",")))
+		}
+		rows = append(rows, row)
+	}
+
+	previewLen := t.opts.PreviewLen
+	if previewLen <= 0 {
+		previewLen = DefaultPreviewWidth
+		if t.opts.Width > 0 {
+			previewLen = fitPreviewWidth(header, rows, previewCol, t.opts.Width)
+		}
+	}
+
+	table := tablewriter.NewWriter(t.opts.Writer)
+	table.SetHeader(header)
+	table.SetBorder(false)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetTablePadding(tablePadding)
+	table.SetNoWhiteSpace(true)
+	table.SetAutoWrapText(false)
+
+	for i, row := range rows {
+		row[previewCol] = truncateString(conversations[i].Preview, previewLen)
+		table.Append(row)
 	}
 
 	table.Render()
@@ -134,6 +275,35 @@ func (t *ConversationTable) renderTable(conversations []*history.ConversationMet
 	return nil
 }
 
+// fitPreviewWidth returns the preview width that fills a terminal width
+// columns wide, given the widths of the other columns, but no narrower than
+// minPreviewWidth.
+func fitPreviewWidth(header []string, rows [][]string, previewCol, width int) int {
+	// tablewriter pads after every column, including the last
+	used := len(tablePadding) * len(header)
+	for col := range header {
+		if col == previewCol {
+			continue
+		}
+		colWidth := tablewriter.DisplayWidth(header[col])
+		for _, row := range rows {
+			colWidth = max(colWidth, tablewriter.DisplayWidth(row[col]))
+		}
+		used += colWidth
+	}
+	return max(width-used, minPreviewWidth)
+}
+
+// hasTags reports whether any of the conversations has user tags.
+func (t *ConversationTable) hasTags(conversations []*history.ConversationMeta) bool {
+	for _, c := range conversations {
+		if len(t.opts.Tags[c.ID]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // renderContextHeader prints context about what's being displayed.
 func (t *ConversationTable) renderContextHeader(count int) {
 	if t.opts.IsGlobal {
@@ -161,31 +331,40 @@ func (t *ConversationTable) renderFooterHint(conversations []*history.Conversati
 	if hasAgents {
 		fmt.Fprintf(t.opts.Writer, "\n%s\n", Dim("[+N] = spawned agents. Use 'ch agents <id>' to list, 'ch resume <id>' to continue."))
 	}
+
+	for _, c := range conversations {
+		if c.HasMtimeTime() {
+			fmt.Fprintf(t.opts.Writer, "\n%s\n", Dim(mtimeMarker+" = no recorded timestamps; time is the file's modification time."))
+			break
+		}
+	}
 }
 
-// formatRelativeTime formats a time as a relative string (e.g., "2h ago").
-func formatRelativeTime(t time.Time) string {
-	now := time.Now()
-	diff := now.Sub(t)
+// mtimeMarker prefixes list times taken from the file mtime rather than the
+// conversation's entries.
+const mtimeMarker = "~"
 
-	switch {
-	case diff < time.Minute:
-		return Dim("just now")
-	case diff < time.Hour:
-		mins := int(diff.Minutes())
-		return Dim(fmt.Sprintf("%dm ago", mins))
-	case diff < 24*time.Hour:
-		hours := int(diff.Hours())
-		return Dim(fmt.Sprintf("%dh ago", hours))
-	case diff < 7*24*time.Hour:
-		days := int(diff.Hours() / 24)
-		return Dim(fmt.Sprintf("%dd ago", days))
-	default:
-		return Dim(t.Format("Jan 2"))
+// timeCell formats a conversation's time for the list table, marking
+// mtime-derived times.
+func timeCell(c *history.ConversationMeta, format string) string {
+	s := FormatTime(c.Timestamp, format)
+	if c.HasMtimeTime() {
+		return mtimeMarker + s
 	}
+	return s
 }
 
-// truncateString truncates a string to maxLen characters.
+// durationCell formats a session duration for the list table; sessions
+// without a measurable duration show a dash.
+func durationCell(d time.Duration) string {
+	if d <= 0 {
+		return Dim("-")
+	}
+	return FormatDuration(d)
+}
+
+// truncateString truncates a string to maxLen characters (runes), so
+// multi-byte text is never cut mid-character.
 func truncateString(s string, maxLen int) string {
 	// Remove newlines
 	s = strings.ReplaceAll(s, "\n", " ")
@@ -198,10 +377,11 @@ func truncateString(s string, maxLen int) string {
 
 	s = strings.TrimSpace(s)
 
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	return string(runes[:maxLen-3]) + "..."
 }
 
 // ProjectTable renders a table of projects.
@@ -245,8 +425,7 @@ func (t *ProjectTable) renderJSON(projects []*history.Project) error {
 		}
 	}
 
-	encoder := json.NewEncoder(t.opts.Writer)
-	encoder.SetIndent("", "  ")
+	encoder := NewJSONEncoder(t.opts.Writer, t.opts.Compact)
 	return encoder.Encode(output)
 }
 
@@ -280,8 +459,8 @@ func (t *ProjectTable) renderTable(projects []*history.Project) error {
 		if t.opts.CurrentProject != "" && p.Path == t.opts.CurrentProject {
 			path = path + " " + Match("*")
 		}
-		convs := fmt.Sprintf("%d", p.ConversationCount)
-		agents := fmt.Sprintf("%d", p.AgentCount)
+		convs := FormatNumber(int64(p.ConversationCount))
+		agents := FormatNumber(int64(p.AgentCount))
 		size := FormatBytes(p.TotalSize)
 
 		table.Append([]string{path, convs, agents, size})
@@ -292,8 +471,8 @@ func (t *ProjectTable) renderTable(projects []*history.Project) error {
 	// Totals footer
 	fmt.Fprintf(t.opts.Writer, "\n%s  %s  %s  %s\n",
 		Dim("TOTAL"),
-		Number(fmt.Sprintf("%d", totalConvs)),
-		Number(fmt.Sprintf("%d", totalAgents)),
+		Number(FormatNumber(int64(totalConvs))),
+		Number(FormatNumber(int64(totalAgents))),
 		FormatBytes(totalSize),
 	)
 
@@ -320,6 +499,9 @@ func NewSearchResultTable(opts TableOptions) *SearchResultTable {
 
 // Render renders search results.
 func (t *SearchResultTable) Render(results []*history.SearchResult) error {
+	if t.opts.CountOnly {
+		return t.renderCounts(results)
+	}
 	if t.opts.JSON {
 		return t.renderJSON(results)
 	}
@@ -336,26 +518,96 @@ func (t *SearchResultTable) renderJSON(results []*history.SearchResult) error {
 		Path           string   `json:"path"`
 	}
 
-	output := make([]jsonResult, len(results))
-	for i, r := range results {
-		output[i] = jsonResult{
-			ID:             r.Meta.ID,
-			Project:        r.Meta.ProjectPath,
-			MatchCount:     r.MatchCount,
-			MessageIndices: r.MessageIndices,
-			Previews:       r.Previews,
-			Path:           r.Meta.Path,
+	toJSON := func(results []*history.SearchResult) []jsonResult {
+		items := make([]jsonResult, len(results))
+		for i, r := range results {
+			items[i] = jsonResult{
+				ID:             r.Meta.ID,
+				Project:        r.Meta.ProjectPath,
+				MatchCount:     r.MatchCount,
+				MessageIndices: r.MessageIndices,
+				Previews:       r.Previews,
+				Path:           r.Meta.Path,
+			}
+		}
+		return items
+	}
+
+	type jsonGroup struct {
+		Project    string       `json:"project"`
+		MatchCount int          `json:"match_count"`
+		Results    []jsonResult `json:"results"`
+	}
+
+	encoder := NewJSONEncoder(t.opts.Writer, t.opts.Compact)
+
+	// With GroupByProject, results are nested under projects instead
+	if t.opts.GroupByProject {
+		output := struct {
+			TotalMatched int         `json:"total_matched"`
+			Shown        int         `json:"shown"`
+			Projects     []jsonGroup `json:"projects"`
+		}{
+			TotalMatched: t.totalMatched(results),
+			Shown:        len(results),
+			Projects:     []jsonGroup{},
 		}
+		for _, g := range groupSearchResults(results) {
+			output.Projects = append(output.Projects, jsonGroup{Project: g.Project, MatchCount: g.MatchCount, Results: toJSON(g.Results)})
+		}
+		return encoder.Encode(output)
 	}
 
-	encoder := json.NewEncoder(t.opts.Writer)
-	encoder.SetIndent("", "  ")
+	output := struct {
+		TotalMatched int          `json:"total_matched"`
+		Shown        int          `json:"shown"`
+		Results      []jsonResult `json:"results"`
+	}{
+		TotalMatched: t.totalMatched(results),
+		Shown:        len(results),
+		Results:      toJSON(results),
+	}
 	return encoder.Encode(output)
 }
 
+// renderCounts renders one line per result in grep -c style:
+// shortid<TAB>matchcount<TAB>project, or a compact JSON array.
+func (t *SearchResultTable) renderCounts(results []*history.SearchResult) error {
+	if t.opts.JSON {
+		type jsonCount struct {
+			ID         string `json:"id"`
+			MatchCount int    `json:"match_count"`
+			Project    string `json:"project"`
+		}
+		counts := make([]jsonCount, len(results))
+		for i, r := range results {
+			counts[i] = jsonCount{ID: r.Meta.ID, MatchCount: r.MatchCount, Project: r.Meta.ProjectPath}
+		}
+		return json.NewEncoder(t.opts.Writer).Encode(counts)
+	}
+
+	for _, r := range results {
+		id := history.ShortID(r.Meta.ID)
+		if r.Meta.IsAgent {
+			id = "agent-" + id
+		}
+		fmt.Fprintf(t.opts.Writer, "%s\t%d\t%s\n", id, r.MatchCount, r.Meta.ProjectPath)
+	}
+	return nil
+}
+
+// totalMatched returns the total match count, falling back to the number of shown results.
+func (t *SearchResultTable) totalMatched(results []*history.SearchResult) int {
+	if t.opts.TotalMatched > len(results) {
+		return t.opts.TotalMatched
+	}
+	return len(results)
+}
+
 func (t *SearchResultTable) renderTable(results []*history.SearchResult) error {
 	if len(results) == 0 {
 		fmt.Fprintln(t.opts.Writer, Dim("No matches found"))
+		t.renderStat(results)
 		return nil
 	}
 
@@ -366,38 +618,109 @@ func (t *SearchResultTable) renderTable(results []*history.SearchResult) error {
 	}
 
 	// Summary header
-	fmt.Fprintf(t.opts.Writer, "%s\n\n", Dim(fmt.Sprintf("Found %d matches in %d conversations", totalMatches, len(results))))
-
-	for i, r := range results {
-		if i > 0 {
-			fmt.Fprintln(t.opts.Writer)
+	fmt.Fprintf(t.opts.Writer, "%s\n", Dim(fmt.Sprintf("Found %s matches in %s conversations", FormatNumber(int64(totalMatches)), FormatNumber(int64(len(results))))))
+	if total := t.totalMatched(results); total > len(results) {
+		fmt.Fprintf(t.opts.Writer, "%s\n", Dim(fmt.Sprintf("Showing %d of %s matching conversations (use --limit to see more)", len(results), FormatNumber(int64(total)))))
+	}
+	fmt.Fprintln(t.opts.Writer)
+
+	if t.opts.GroupByProject {
+		for i, g := range groupSearchResults(results) {
+			if i > 0 {
+				fmt.Fprintln(t.opts.Writer)
+			}
+			fmt.Fprintf(t.opts.Writer, "%s  %s\n", Project(g.Project),
+				Dim(fmt.Sprintf("(%d conversations, %d matches)", len(g.Results), g.MatchCount)))
+			for _, r := range g.Results {
+				fmt.Fprintln(t.opts.Writer)
+				t.renderResult(r, "  ")
+			}
 		}
-
-		// Header
-		id := history.ShortID(r.Meta.ID)
-		if r.Meta.IsAgent {
-			id = "agent-" + id
+	} else {
+		for i, r := range results {
+			if i > 0 {
+				fmt.Fprintln(t.opts.Writer)
+			}
+			t.renderResult(r, "")
 		}
+	}
+
+	t.renderStat(results)
+	return nil
+}
+
+// renderResult renders one search result. Under a project subheader the
+// result is indented and the project path omitted.
+func (t *SearchResultTable) renderResult(r *history.SearchResult, indent string) {
+	// Header
+	id := history.ShortID(r.Meta.ID)
+	if r.Meta.IsAgent {
+		id = "agent-" + id
+	}
+	if indent == "" {
 		fmt.Fprintf(t.opts.Writer, "%s  %s  %s\n",
 			ID(id),
 			Dim(r.Meta.ProjectPath),
 			Match(fmt.Sprintf("[%d matches]", r.MatchCount)),
 		)
+	} else {
+		fmt.Fprintf(t.opts.Writer, "%s%s  %s\n", indent, ID(id), Match(fmt.Sprintf("[%d matches]", r.MatchCount)))
+	}
 
-		// Show message indices if enabled
-		if t.opts.ShowIndices && len(r.MessageIndices) > 0 {
-			fmt.Fprintf(t.opts.Writer, "  %s %s\n",
-				Dim("Messages:"),
-				formatMessageIndices(r.MessageIndices))
-		}
+	// Show message indices if enabled
+	if t.opts.ShowIndices && len(r.MessageIndices) > 0 {
+		fmt.Fprintf(t.opts.Writer, "%s  %s %s\n",
+			indent,
+			Dim("Messages:"),
+			formatMessageIndices(r.MessageIndices))
+	}
 
-		// Previews
-		for _, preview := range r.Previews {
-			fmt.Fprintf(t.opts.Writer, "  %s\n", preview)
+	// Previews
+	for _, preview := range r.Previews {
+		fmt.Fprintf(t.opts.Writer, "%s  %s\n", indent, preview)
+	}
+}
+
+// searchGroup is the search results of one project.
+type searchGroup struct {
+	Project    string
+	MatchCount int
+	Results    []*history.SearchResult
+}
+
+// groupSearchResults groups results by project, keeping their order within
+// each project. Projects are ordered by total match count, then path.
+func groupSearchResults(results []*history.SearchResult) []*searchGroup {
+	byProject := make(map[string]*searchGroup)
+	var groups []*searchGroup
+	for _, r := range results {
+		g, ok := byProject[r.Meta.ProjectPath]
+		if !ok {
+			g = &searchGroup{Project: r.Meta.ProjectPath}
+			byProject[r.Meta.ProjectPath] = g
+			groups = append(groups, g)
 		}
+		g.MatchCount += r.MatchCount
+		g.Results = append(g.Results, r)
 	}
 
-	return nil
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].MatchCount != groups[j].MatchCount {
+			return groups[i].MatchCount > groups[j].MatchCount
+		}
+		return groups[i].Project < groups[j].Project
+	})
+	return groups
+}
+
+// renderStat prints the grep-style trailing summary line when ShowStat is set.
+func (t *SearchResultTable) renderStat(results []*history.SearchResult) {
+	if !t.opts.ShowStat {
+		return
+	}
+	fmt.Fprintf(t.opts.Writer, "\n%s\n", Dim(fmt.Sprintf("scanned %s files, %s matched, %s total matches in %.1fs",
+		FormatNumber(int64(t.opts.FilesScanned)), FormatNumber(int64(t.totalMatched(results))),
+		FormatNumber(int64(t.opts.TotalMatches)), t.opts.Elapsed.Seconds())))
 }
 
 // formatMessageIndices formats a list of message indices for display.
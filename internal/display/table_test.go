@@ -2,7 +2,9 @@ package display
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,6 +22,7 @@ func TestConversationTable_Render(t *testing.T) {
 			Timestamp:    time.Now().Add(-1 * time.Hour),
 			Preview:      "Hello, how are you?",
 			MessageCount: 10,
+			FileSize:     4000,
 			IsAgent:      false,
 			AgentCount:   3,
 		},
@@ -47,6 +50,44 @@ func TestConversationTable_Render(t *testing.T) {
 		if output == "" {
 			t.Error("Render() produced empty output")
 		}
+		if strings.Contains(output, "abc123-def456-789") {
+			t.Errorf("expected short IDs by default, got:\n%s", output)
+		}
+	})
+
+	t.Run("full IDs", func(t *testing.T) {
+		var buf bytes.Buffer
+		table := NewConversationTable(TableOptions{Writer: &buf, ShowFullID: true})
+		if err := table.Render(conversations); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if !strings.Contains(buf.String(), "abc123-def456-789") {
+			t.Errorf("expected full ID in output, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("preview fills the width", func(t *testing.T) {
+		SetColorEnabled(false)
+		defer SetColorEnabled(true)
+
+		long := []*history.ConversationMeta{{
+			ID:        "abc123-def456-789",
+			Timestamp: time.Now(),
+			Preview:   strings.Repeat("word ", 60),
+		}}
+		// ID, "just now", and the message count take 24 columns, plus padding
+		for _, tt := range []struct{ width, want int }{{100, 100}, {40, 24 + 8 + minPreviewWidth}} {
+			var buf bytes.Buffer
+			table := NewConversationTable(TableOptions{Writer: &buf, Width: tt.width})
+			if err := table.Render(long); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, line := range strings.Split(buf.String(), "\n") {
+				if strings.Contains(line, "word") && len(line) != tt.want {
+					t.Errorf("Width %d: row is %d columns, want %d:\n%s", tt.width, len(line), tt.want, line)
+				}
+			}
+		}
 	})
 
 	t.Run("JSON output", func(t *testing.T) {
@@ -64,6 +105,100 @@ func TestConversationTable_Render(t *testing.T) {
 		if len(result) != 2 {
 			t.Errorf("JSON result length = %d, want 2", len(result))
 		}
+		if got := result[0]["estimated_tokens"]; got != float64(1000) {
+			t.Errorf("estimated_tokens = %v, want 1000", got)
+		}
+	})
+
+	t.Run("CSV output", func(t *testing.T) {
+		var buf bytes.Buffer
+		table := NewConversationTable(TableOptions{Writer: &buf, CSV: true})
+		if err := table.Render(conversations); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("CSV parse error = %v", err)
+		}
+		if len(records) != 3 {
+			t.Fatalf("CSV rows = %d, want 3 (header + 2)", len(records))
+		}
+		if records[0][0] != "id" || records[1][0] != "abc123-def456-789" || records[2][6] != "true" {
+			t.Errorf("unexpected CSV records: %v", records)
+		}
+		if records[1][7] != "Hello, how are you?" {
+			t.Errorf("preview = %q, want the comma preserved", records[1][7])
+		}
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		tags := map[string][]string{"abc123-def456-789": {"bug", "spike"}}
+
+		var buf bytes.Buffer
+		table := NewConversationTable(TableOptions{Writer: &buf, Tags: tags})
+		if err := table.Render(conversations); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if !strings.Contains(buf.String(), "bug,spike") {
+			t.Errorf("table output should include tags column, got: %s", buf.String())
+		}
+
+		buf.Reset()
+		table = NewConversationTable(TableOptions{Writer: &buf, JSON: true, Tags: tags})
+		if err := table.Render(conversations); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		var result []struct {
+			Tags []string `json:"tags"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+			t.Fatalf("JSON unmarshal error = %v", err)
+		}
+		if len(result[0].Tags) != 2 || len(result[1].Tags) != 0 {
+			t.Errorf("JSON tags = %v / %v, want [bug spike] / []", result[0].Tags, result[1].Tags)
+		}
+	})
+
+	t.Run("ID only", func(t *testing.T) {
+		var buf bytes.Buffer
+		table := NewConversationTable(TableOptions{Writer: &buf, IDOnly: true})
+		if err := table.Render(conversations); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if got, want := buf.String(), "abc123-d\nagent-def789\n"; got != want {
+			t.Errorf("ID-only output = %q, want %q", got, want)
+		}
+
+		buf.Reset()
+		table = NewConversationTable(TableOptions{Writer: &buf, IDOnly: true, ShowFullID: true})
+		if err := table.Render(conversations); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if !strings.HasPrefix(buf.String(), "abc123-def456-789\n") {
+			t.Errorf("expected full IDs, got %q", buf.String())
+		}
+	})
+
+	t.Run("mtime marker", func(t *testing.T) {
+		var buf bytes.Buffer
+		table := NewConversationTable(TableOptions{Writer: &buf})
+		if err := table.Render(conversations); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if strings.Contains(buf.String(), "~") {
+			t.Errorf("unexpected mtime marker, got:\n%s", buf.String())
+		}
+
+		touched := *conversations[0]
+		touched.TimeSource = history.TimeSourceMtime
+		buf.Reset()
+		if err := table.Render([]*history.ConversationMeta{&touched}); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if !strings.Contains(buf.String(), "~1h ago") || !strings.Contains(buf.String(), "modification time") {
+			t.Errorf("expected marked time and legend, got:\n%s", buf.String())
+		}
 	})
 
 	t.Run("empty list", func(t *testing.T) {
@@ -136,6 +271,29 @@ func TestSearchResultTable_Render(t *testing.T) {
 		},
 	}
 
+	t.Run("message indices", func(t *testing.T) {
+		indexed := *results[0]
+		indexed.MessageIndices = []int{2, 7, 9, 12, 15, 20, 31}
+
+		var buf bytes.Buffer
+		table := NewSearchResultTable(TableOptions{Writer: &buf})
+		if err := table.Render([]*history.SearchResult{&indexed}); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if strings.Contains(buf.String(), "Messages:") {
+			t.Errorf("indices shown without ShowIndices, got:\n%s", buf.String())
+		}
+
+		buf.Reset()
+		table = NewSearchResultTable(TableOptions{Writer: &buf, ShowIndices: true})
+		if err := table.Render([]*history.SearchResult{&indexed}); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if !strings.Contains(buf.String(), "[2, 7, 9, 12, 15, ... +2 more]") {
+			t.Errorf("expected message indices, got:\n%s", buf.String())
+		}
+	})
+
 	t.Run("table output", func(t *testing.T) {
 		var buf bytes.Buffer
 		table := NewSearchResultTable(TableOptions{Writer: &buf})
@@ -151,18 +309,111 @@ func TestSearchResultTable_Render(t *testing.T) {
 
 	t.Run("JSON output", func(t *testing.T) {
 		var buf bytes.Buffer
-		table := NewSearchResultTable(TableOptions{Writer: &buf, JSON: true})
+		table := NewSearchResultTable(TableOptions{Writer: &buf, JSON: true, TotalMatched: 7})
 		err := table.Render(results)
 		if err != nil {
 			t.Fatalf("Render() error = %v", err)
 		}
 
-		var result []map[string]interface{}
+		var result struct {
+			TotalMatched int                      `json:"total_matched"`
+			Shown        int                      `json:"shown"`
+			Results      []map[string]interface{} `json:"results"`
+		}
 		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
 			t.Fatalf("JSON unmarshal error = %v", err)
 		}
-		if len(result) != 1 {
-			t.Errorf("JSON result length = %d, want 1", len(result))
+		if len(result.Results) != 1 {
+			t.Errorf("JSON result length = %d, want 1", len(result.Results))
+		}
+		if result.TotalMatched != 7 {
+			t.Errorf("total_matched = %d, want 7", result.TotalMatched)
+		}
+		if result.Shown != 1 {
+			t.Errorf("shown = %d, want 1", result.Shown)
+		}
+	})
+
+	t.Run("JSON output with no results", func(t *testing.T) {
+		for _, group := range []bool{false, true} {
+			var buf bytes.Buffer
+			table := NewSearchResultTable(TableOptions{Writer: &buf, JSON: true, Compact: true, GroupByProject: group})
+			if err := table.Render(nil); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			want := `{"total_matched":0,"shown":0,"results":[]}`
+			if group {
+				want = `{"total_matched":0,"shown":0,"projects":[]}`
+			}
+			if got := strings.TrimSpace(buf.String()); got != want {
+				t.Errorf("GroupByProject=%v: got %s, want %s", group, got, want)
+			}
+		}
+	})
+
+	t.Run("table shows total when truncated", func(t *testing.T) {
+		var buf bytes.Buffer
+		table := NewSearchResultTable(TableOptions{Writer: &buf, TotalMatched: 143})
+		if err := table.Render(results); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if !bytes.Contains(buf.Bytes(), []byte("Showing 1 of 143 matching conversations")) {
+			t.Errorf("expected total count in output, got: %s", buf.String())
+		}
+	})
+
+	t.Run("group by project", func(t *testing.T) {
+		grouped := append([]*history.SearchResult{
+			{Meta: &history.ConversationMeta{ID: "def456", ProjectPath: "/Users/test/other"}, MatchCount: 2},
+			{Meta: &history.ConversationMeta{ID: "ghi789", ProjectPath: "/Users/test/project"}, MatchCount: 1},
+		}, results...)
+
+		var buf bytes.Buffer
+		table := NewSearchResultTable(TableOptions{Writer: &buf, GroupByProject: true})
+		if err := table.Render(grouped); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		output := buf.String()
+		project, other := strings.Index(output, "/Users/test/project"), strings.Index(output, "/Users/test/other")
+		if project < 0 || other < 0 || project > other {
+			t.Errorf("expected project with most matches first, got:\n%s", output)
+		}
+		if strings.Count(output, "/Users/test/project") != 1 {
+			t.Errorf("expected one subheader per project, got:\n%s", output)
+		}
+
+		buf.Reset()
+		table = NewSearchResultTable(TableOptions{Writer: &buf, GroupByProject: true, JSON: true})
+		if err := table.Render(grouped); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		var result struct {
+			Shown    int `json:"shown"`
+			Projects []struct {
+				Project    string                   `json:"project"`
+				MatchCount int                      `json:"match_count"`
+				Results    []map[string]interface{} `json:"results"`
+			} `json:"projects"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+			t.Fatalf("JSON unmarshal error = %v", err)
+		}
+		if result.Shown != 3 || len(result.Projects) != 2 {
+			t.Fatalf("shown = %d, projects = %d, want 3 and 2", result.Shown, len(result.Projects))
+		}
+		if p := result.Projects[0]; p.Project != "/Users/test/project" || p.MatchCount != 6 || len(p.Results) != 2 {
+			t.Errorf("first project = %+v, want /Users/test/project with 6 matches in 2 results", p)
+		}
+	})
+
+	t.Run("count only", func(t *testing.T) {
+		var buf bytes.Buffer
+		table := NewSearchResultTable(TableOptions{Writer: &buf, CountOnly: true})
+		if err := table.Render(results); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if got, want := buf.String(), "abc123\t5\t/Users/test/project\n"; got != want {
+			t.Errorf("count output = %q, want %q", got, want)
 		}
 	})
 }
@@ -179,6 +430,7 @@ func TestTruncateString(t *testing.T) {
 		{"truncate", "hello world", 8, "hello..."},
 		{"with newlines", "hello\nworld", 20, "hello world"},
 		{"with tabs", "hello\tworld", 20, "hello world"},
+		{"multi-byte", "héllo wörld", 8, "héllo..."},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package display
+
+// TerminalHeight returns the number of rows in the terminal attached to
+// stdout as reported by $LINES, or 0 if it can't be determined.
+func TerminalHeight() int {
+	return linesFromEnv()
+}
+
+// TerminalWidth returns the number of columns in the terminal attached to
+// stdout as reported by $COLUMNS, or 0 if it can't be determined.
+func TerminalWidth() int {
+	return columnsFromEnv()
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package display
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// TerminalHeight returns the number of rows in the terminal attached to
+// stdout, or 0 if it can't be determined.
+func TerminalHeight() int {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Row == 0 {
+		return linesFromEnv()
+	}
+	return int(ws.Row)
+}
+
+// TerminalWidth returns the number of columns in the terminal attached to
+// stdout, or 0 if it can't be determined.
+func TerminalWidth() int {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 {
+		return columnsFromEnv()
+	}
+	return int(ws.Col)
+}
@@ -0,0 +1,130 @@
+package display
+
+import (
+	"fmt"
+	"time"
+)
+
+// Time display formats. Any other non-empty value is used as a Go time layout.
+const (
+	TimeFormatRelative = "relative" // e.g. "2h ago", falling back to "Jan 2" after a week
+	TimeFormatAbsolute = "absolute" // RFC3339
+)
+
+// Timestamp layouts for JSON output. Any other non-empty value is used as a Go
+// time layout.
+const (
+	TimeLayoutRFC3339   = "rfc3339"   // Default, e.g. "2024-01-01T10:00:00Z"
+	TimeLayoutUnix      = "unix"      // Seconds since the epoch, as a number
+	TimeLayoutUnixMilli = "unixmilli" // Milliseconds since the epoch, as a number
+)
+
+// JSONTime formats t for JSON output using layout: TimeLayoutRFC3339 (or
+// empty), TimeLayoutUnix, TimeLayoutUnixMilli, or a custom Go time layout.
+// The epoch layouts yield numbers, the others strings.
+func JSONTime(t time.Time, layout string) any {
+	switch layout {
+	case TimeLayoutRFC3339, "":
+		return t.Format(time.RFC3339)
+	case TimeLayoutUnix:
+		return t.Unix()
+	case TimeLayoutUnixMilli:
+		return t.UnixMilli()
+	default:
+		return t.Format(layout)
+	}
+}
+
+// ValidJSONTimeLayout reports whether layout is one of the named layouts or
+// a Go time layout. A custom layout must contain at least one layout element
+// (e.g. "2006"), so a misspelled name like "unx" is rejected instead of being
+// printed verbatim for every timestamp.
+func ValidJSONTimeLayout(layout string) bool {
+	switch layout {
+	case TimeLayoutRFC3339, TimeLayoutUnix, TimeLayoutUnixMilli, "":
+		return true
+	}
+	ref := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	return ref.Format(layout) != layout
+}
+
+// jsonOptionalTime is JSONTime for a time that may be unset: a zero t
+// yields nil so omitempty drops it.
+func jsonOptionalTime(t time.Time, layout string) any {
+	if t.IsZero() {
+		return nil
+	}
+	return JSONTime(t, layout)
+}
+
+// JSONEntryTime formats an entry's raw timestamp for JSON output. With the
+// default layout the recorded string is kept as-is; unparseable timestamps are
+// also passed through. An empty timestamp yields nil so omitempty drops it.
+func JSONEntryTime(raw, layout string) any {
+	if raw == "" {
+		return nil
+	}
+	if layout == "" || layout == TimeLayoutRFC3339 {
+		return raw
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return raw
+	}
+	return JSONTime(t, layout)
+}
+
+// FormatTime formats t for display. format is TimeFormatRelative,
+// TimeFormatAbsolute, or a custom Go time layout (e.g. "2006-01-02 15:04").
+// An empty format is treated as absolute.
+func FormatTime(t time.Time, format string) string {
+	switch format {
+	case TimeFormatRelative:
+		return relativeTime(t, time.Now())
+	case TimeFormatAbsolute, "":
+		return t.Format(time.RFC3339)
+	default:
+		return t.Format(format)
+	}
+}
+
+// relativeTime formats t relative to now (e.g., "2h ago").
+func relativeTime(t, now time.Time) string {
+	diff := now.Sub(t)
+
+	switch {
+	case diff < time.Minute:
+		return "just now"
+	case diff < time.Hour:
+		return fmt.Sprintf("%dm ago", int(diff.Minutes()))
+	case diff < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(diff.Hours()))
+	case diff < 7*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(diff.Hours()/24))
+	default:
+		return t.Format("Jan 2")
+	}
+}
+
+// FormatDuration formats d compactly with its two largest units,
+// e.g. "45s", "12m", "2h5m", or "3d4h".
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		h, m := int(d.Hours()), int(d.Minutes())%60
+		if m == 0 {
+			return fmt.Sprintf("%dh", h)
+		}
+		return fmt.Sprintf("%dh%dm", h, m)
+	default:
+		days, h := int(d.Hours())/24, int(d.Hours())%24
+		if h == 0 {
+			return fmt.Sprintf("%dd", days)
+		}
+		return fmt.Sprintf("%dd%dh", days, h)
+	}
+}
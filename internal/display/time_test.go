@@ -0,0 +1,139 @@
+package display
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"just now", now.Add(-30 * time.Second), "just now"},
+		{"minutes", now.Add(-5 * time.Minute), "5m ago"},
+		{"hours", now.Add(-3 * time.Hour), "3h ago"},
+		{"days", now.Add(-2 * 24 * time.Hour), "2d ago"},
+		{"older", time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC), "Jan 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := relativeTime(tt.t, now); got != tt.want {
+				t.Errorf("relativeTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	ts := time.Date(2025, 1, 2, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"", "2025-01-02T09:30:00Z"},
+		{TimeFormatAbsolute, "2025-01-02T09:30:00Z"},
+		{"2006-01-02 15:04", "2025-01-02 09:30"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatTime(ts, tt.format); got != tt.want {
+			t.Errorf("FormatTime(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	if got := FormatTime(time.Now().Add(-2*time.Hour), TimeFormatRelative); got != "2h ago" {
+		t.Errorf("FormatTime(relative) = %q, want %q", got, "2h ago")
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{45 * time.Second, "45s"},
+		{12*time.Minute + 30*time.Second, "12m"},
+		{2 * time.Hour, "2h"},
+		{2*time.Hour + 5*time.Minute, "2h5m"},
+		{24 * time.Hour, "1d"},
+		{3*24*time.Hour + 4*time.Hour + 10*time.Minute, "3d4h"},
+	}
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestJSONTime(t *testing.T) {
+	ts := time.Date(2025, 1, 2, 9, 30, 0, 500_000_000, time.UTC)
+
+	tests := []struct {
+		layout string
+		want   any
+	}{
+		{"", "2025-01-02T09:30:00Z"},
+		{TimeLayoutRFC3339, "2025-01-02T09:30:00Z"},
+		{TimeLayoutUnix, int64(1735810200)},
+		{TimeLayoutUnixMilli, int64(1735810200500)},
+		{"2006-01-02", "2025-01-02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			if got := JSONTime(ts, tt.layout); got != tt.want {
+				t.Errorf("JSONTime(%q) = %v (%T), want %v (%T)", tt.layout, got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONEntryTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		layout string
+		want   any
+	}{
+		{"empty", "", TimeLayoutUnix, nil},
+		{"default keeps recorded string", "2025-01-02T09:30:00.123Z", "", "2025-01-02T09:30:00.123Z"},
+		{"unix milli", "2025-01-02T09:30:00.123Z", TimeLayoutUnixMilli, int64(1735810200123)},
+		{"unparseable", "yesterday", TimeLayoutUnix, "yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JSONEntryTime(tt.raw, tt.layout); got != tt.want {
+				t.Errorf("JSONEntryTime(%q, %q) = %v, want %v", tt.raw, tt.layout, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidJSONTimeLayout(t *testing.T) {
+	tests := []struct {
+		layout string
+		want   bool
+	}{
+		{TimeLayoutRFC3339, true},
+		{TimeLayoutUnix, true},
+		{TimeLayoutUnixMilli, true},
+		{"2006-01-02 15:04", true},
+		{time.Kitchen, true},
+		{"unx", false},
+		{"iso", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidJSONTimeLayout(tt.layout); got != tt.want {
+			t.Errorf("ValidJSONTimeLayout(%q) = %v, want %v", tt.layout, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dmora/ch/internal/jsonl"
+)
+
+// How an agent was matched to the Task call that spawned it (AgentInfo.Match),
+// most reliable first.
+const (
+	AgentMatchID     = "id"     // The Task tool_use ID contains the agent ID
+	AgentMatchPrompt = "prompt" // The Task prompt is the agent's first user message
+	AgentMatchOrder  = "order"  // The Nth Task call of the parent, for its Nth agent by start time
+)
+
+// taskCall is a Task tool call that spawned an agent.
+type taskCall struct {
+	ID        string     // tool_use block ID
+	SpawnUUID string     // UUID of the entry containing the call
+	Input     *AgentInfo // Type, prompt, and description from the call's input
+}
+
+// taskCalls collects the Task tool calls in entries, in order.
+func taskCalls(entries []*jsonl.RawEntry) []taskCall {
+	var calls []taskCall
+	for _, entry := range entries {
+		if entry.Type != jsonl.EntryTypeAssistant || entry.Message == nil {
+			continue
+		}
+		msg, err := jsonl.ParseMessage(entry)
+		if err != nil || msg == nil {
+			continue
+		}
+		for i := range msg.Content {
+			block := &msg.Content[i]
+			if block.Type != jsonl.BlockTypeToolUse || block.Name != "Task" || block.ID == "" {
+				continue
+			}
+			var input map[string]interface{}
+			if block.Input == nil || json.Unmarshal(block.Input, &input) != nil {
+				continue
+			}
+			calls = append(calls, taskCall{ID: block.ID, SpawnUUID: entry.UUID, Input: parseAgentInput("", input)})
+		}
+	}
+	return calls
+}
+
+// taskMatcher correlates agents with the Task calls of one parent
+// conversation. It is safe for concurrent use.
+type taskMatcher struct {
+	parentPath string
+	calls      []taskCall
+
+	agentsOnce sync.Once
+	agents     []*ConversationMeta // The parent's agents by start time, loaded on first use
+}
+
+// newTaskMatcher creates a matcher for the Task calls of the conversation at
+// parentPath.
+func newTaskMatcher(parentPath string, entries []*jsonl.RawEntry) *taskMatcher {
+	return &taskMatcher{parentPath: parentPath, calls: taskCalls(entries)}
+}
+
+// match returns the Task call that spawned the agent and how it was matched.
+// Tool IDs are tried first. When no ID overlaps, the agent's first user
+// message is compared with each call's prompt, and failing that the agent's
+// position among the parent's agents picks the call at the same position.
+// The call is nil if none can be correlated.
+func (m *taskMatcher) match(agentID string) (*taskCall, string) {
+	if len(m.calls) == 0 {
+		return nil, ""
+	}
+	normalizedID := strings.TrimPrefix(agentID, "agent-")
+	for i := range m.calls {
+		if strings.Contains(m.calls[i].ID, normalizedID) {
+			return &m.calls[i], AgentMatchID
+		}
+	}
+
+	agentPath := agentFilePath(filepath.Dir(m.parentPath), normalizedID)
+	if prompt := firstUserText(agentPath); prompt != "" {
+		for i := range m.calls {
+			if strings.TrimSpace(m.calls[i].Input.Prompt) == prompt {
+				return &m.calls[i], AgentMatchPrompt
+			}
+		}
+	}
+
+	for i, agent := range m.siblings() {
+		if agent.ID == normalizedID {
+			if i < len(m.calls) {
+				return &m.calls[i], AgentMatchOrder
+			}
+			break
+		}
+	}
+	return nil, ""
+}
+
+// agentFilePath returns the path of the agent's conversation file in
+// projectDir: agent-<id>.jsonl, or for sidechain agents that keep a
+// session-style filename, <id>.jsonl.
+func agentFilePath(projectDir, agentID string) string {
+	path := ConversationFilePath(projectDir, "agent-"+agentID)
+	if _, err := os.Stat(path); err != nil {
+		return ConversationFilePath(projectDir, agentID)
+	}
+	return path
+}
+
+// siblings returns the agents spawned under the parent session, oldest first.
+func (m *taskMatcher) siblings() []*ConversationMeta {
+	m.agentsOnce.Do(func() {
+		sessionID := trimConversationExt(filepath.Base(m.parentPath))
+		m.agents, _ = NewScanner(ScannerOptions{}).FindAgents(filepath.Dir(m.parentPath), sessionID)
+	})
+	return m.agents
+}
+
+// firstUserText returns the trimmed text of the first user message in the
+// conversation at path, or "" if it can't be read.
+func firstUserText(path string) string {
+	parser, err := jsonl.NewParser(path)
+	if err != nil {
+		return ""
+	}
+	defer parser.Close()
+
+	for {
+		entry, err := parser.Next()
+		if err != nil || entry == nil {
+			return ""
+		}
+		if entry.Type != jsonl.EntryTypeUser {
+			continue
+		}
+		msg, err := jsonl.ParseMessage(entry)
+		if err != nil || msg == nil {
+			continue
+		}
+		if text := strings.TrimSpace(jsonl.ExtractText(msg)); text != "" {
+			return text
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractAgentInfo_Matching(t *testing.T) {
+	projectDir := filepath.Join(t.TempDir(), "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	// Only the first call's ID references its agent
+	parent := `{"type":"assistant","uuid":"p1","sessionId":"main-123","message":{"role":"assistant","content":[` +
+		`{"type":"tool_use","id":"toolu_aaa111","name":"Task","input":{"subagent_type":"Explore","prompt":"map the repo"}},` +
+		`{"type":"tool_use","id":"toolu_x1","name":"Task","input":{"subagent_type":"Plan","prompt":"plan the fix"}},` +
+		`{"type":"tool_use","id":"toolu_x2","name":"Task","input":{"subagent_type":"Review","prompt":"review it"}}]}}`
+	files := map[string]string{
+		"main-123.jsonl":     parent,
+		"agent-aaa111.jsonl": `{"type":"user","sessionId":"main-123","timestamp":"2024-01-01T10:00:00Z","message":{"role":"user","content":"map the repo"}}`,
+		"agent-bbb222.jsonl": `{"type":"user","sessionId":"main-123","timestamp":"2024-01-01T10:01:00Z","message":{"role":"user","content":"  plan the fix\n"}}`,
+		"agent-ccc333.jsonl": `{"type":"user","sessionId":"main-123","timestamp":"2024-01-01T10:02:00Z","message":{"role":"user","content":"reworded prompt"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	parentPath := filepath.Join(projectDir, "main-123.jsonl")
+
+	tests := []struct {
+		agentID   string
+		wantType  string
+		wantMatch string
+	}{
+		{"aaa111", "Explore", AgentMatchID},
+		{"bbb222", "Plan", AgentMatchPrompt},
+		{"agent-ccc333", "Review", AgentMatchOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.agentID, func(t *testing.T) {
+			info, err := ExtractAgentInfo(parentPath, tt.agentID)
+			if err != nil {
+				t.Fatalf("ExtractAgentInfo() error = %v", err)
+			}
+			if info == nil {
+				t.Fatal("ExtractAgentInfo() = nil, want a match")
+			}
+			if info.SubagentType != tt.wantType || info.Match != tt.wantMatch {
+				t.Errorf("got %s by %s, want %s by %s", info.SubagentType, info.Match, tt.wantType, tt.wantMatch)
+			}
+			if info.AgentID != tt.agentID || info.SpawnUUID != "p1" {
+				t.Errorf("AgentID = %q, SpawnUUID = %q", info.AgentID, info.SpawnUUID)
+			}
+		})
+	}
+
+	// An agent beyond the last Task call can't be correlated
+	extra := `{"type":"user","sessionId":"main-123","timestamp":"2024-01-01T10:03:00Z","message":{"role":"user","content":"unrelated"}}`
+	if err := os.WriteFile(filepath.Join(projectDir, "agent-ddd444.jsonl"), []byte(extra), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if info, _ := ExtractAgentInfo(parentPath, "ddd444"); info != nil {
+		t.Errorf("ExtractAgentInfo(ddd444) = %+v, want nil", info)
+	}
+}
+
+func TestExtractAgentInfo_SidechainFile(t *testing.T) {
+	projectDir := filepath.Join(t.TempDir(), "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	// Sidechain agents keep a session-style filename instead of agent-*
+	parent := `{"type":"assistant","uuid":"p1","sessionId":"main-123","message":{"role":"assistant","content":[` +
+		`{"type":"tool_use","id":"toolu_x1","name":"Task","input":{"subagent_type":"Plan","prompt":"plan the fix"}},` +
+		`{"type":"tool_use","id":"toolu_x2","name":"Task","input":{"subagent_type":"Review","prompt":"review it"}}]}}`
+	files := map[string]string{
+		"main-123.jsonl":      parent,
+		"5ide0000-1111.jsonl": `{"type":"user","sessionId":"main-123","isSidechain":true,"timestamp":"2024-01-01T10:00:00Z","message":{"role":"user","content":"review it"}}`,
+		"5ide0000-2222.jsonl": `{"type":"user","sessionId":"main-123","isSidechain":true,"timestamp":"2024-01-01T10:01:00Z","message":{"role":"user","content":"reworded prompt"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	parentPath := filepath.Join(projectDir, "main-123.jsonl")
+
+	tests := []struct {
+		agentID   string
+		wantType  string
+		wantMatch string
+	}{
+		{"5ide0000-1111", "Review", AgentMatchPrompt},
+		{"agent-5ide0000-2222", "Review", AgentMatchOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.agentID, func(t *testing.T) {
+			info, err := ExtractAgentInfo(parentPath, tt.agentID)
+			if err != nil {
+				t.Fatalf("ExtractAgentInfo() error = %v", err)
+			}
+			if info == nil {
+				t.Fatal("ExtractAgentInfo() = nil, want a match")
+			}
+			if info.SubagentType != tt.wantType || info.Match != tt.wantMatch {
+				t.Errorf("got %s by %s, want %s by %s", info.SubagentType, info.Match, tt.wantType, tt.wantMatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package history
+
+import (
+	"strings"
+
+	"github.com/dmora/ch/internal/jsonl"
+)
+
+// AgentNode is a node in the hierarchy of agents spawned under a session.
+type AgentNode struct {
+	Meta     *ConversationMeta
+	Info     *AgentInfo   // Task tool details from the spawning conversation (nil if not found)
+	Children []*AgentNode // Agents spawned by this agent, ordered by timestamp
+}
+
+// BuildAgentTree builds the hierarchy of agents spawned under a session.
+// An agent is nested under another agent when that agent's conversation contains
+// the Task tool call that spawned it; otherwise it is attached to the root session.
+// Returns the top-level agents (those spawned directly by the main conversation).
+func (s *Scanner) BuildAgentTree(projectDir, sessionID string) ([]*AgentNode, error) {
+	agents, err := s.FindAgents(projectDir, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the parent conversation once (it may be missing or compacted)
+	var parentEntries []*jsonl.RawEntry
+	if parent, err := LoadConversation(ConversationFilePath(projectDir, sessionID)); err == nil {
+		parentEntries = parent.Entries
+	}
+
+	// Load each agent's entries once so they can be searched for sub-agent Task calls
+	agentEntries := make(map[string][]*jsonl.RawEntry, len(agents))
+	for _, a := range agents {
+		if conv, err := LoadConversation(a.Path); err == nil {
+			agentEntries[a.ID] = conv.Entries
+		}
+	}
+
+	nodes := make(map[string]*AgentNode, len(agents))
+	for _, a := range agents {
+		nodes[a.ID] = &AgentNode{Meta: a}
+	}
+
+	// Resolve the spawner of each agent
+	parentOf := make(map[string]string, len(agents))
+	for _, a := range agents {
+		normalizedID := strings.TrimPrefix(a.ID, "agent-")
+		if _, _, input := findTaskToolCall(parentEntries, normalizedID); input != nil {
+			nodes[a.ID].Info = parseAgentInput(a.ID, input)
+			continue
+		}
+		for _, candidate := range agents {
+			if candidate.ID == a.ID {
+				continue
+			}
+			if _, _, input := findTaskToolCall(agentEntries[candidate.ID], normalizedID); input != nil {
+				nodes[a.ID].Info = parseAgentInput(a.ID, input)
+				if !createsCycle(parentOf, a.ID, candidate.ID) {
+					parentOf[a.ID] = candidate.ID
+				}
+				break
+			}
+		}
+	}
+
+	// Attach children in timestamp order (agents are already sorted)
+	var roots []*AgentNode
+	for _, a := range agents {
+		node := nodes[a.ID]
+		if parentID, ok := parentOf[a.ID]; ok {
+			nodes[parentID].Children = append(nodes[parentID].Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	return roots, nil
+}
+
+// createsCycle reports whether making parentID the parent of childID would create a cycle.
+func createsCycle(parentOf map[string]string, childID, parentID string) bool {
+	seen := make(map[string]bool)
+	for id := parentID; id != ""; id = parentOf[id] {
+		if id == childID || seen[id] {
+			return true
+		}
+		seen[id] = true
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildAgentTree(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "ch-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	files := map[string]string{
+		// Main conversation spawns agent aaa
+		"main123.jsonl": `{"type":"user","timestamp":"2024-01-01T10:00:00Z","sessionId":"main123","message":{"role":"user","content":"Explore"}}
+{"type":"assistant","timestamp":"2024-01-01T10:00:01Z","sessionId":"main123","message":{"role":"assistant","content":[{"type":"tool_use","id":"toolu_aaa","name":"Task","input":{"subagent_type":"Explore","prompt":"look around","description":"explore"}}]}}
+`,
+		// Agent aaa spawns agent bbb
+		"agent-aaa.jsonl": `{"type":"user","timestamp":"2024-01-01T10:00:02Z","sessionId":"main123","message":{"role":"user","content":"look around"}}
+{"type":"assistant","timestamp":"2024-01-01T10:00:03Z","sessionId":"main123","message":{"role":"assistant","content":[{"type":"tool_use","id":"toolu_bbb","name":"Task","input":{"subagent_type":"Plan","prompt":"plan it"}}]}}
+`,
+		"agent-bbb.jsonl": `{"type":"user","timestamp":"2024-01-01T10:00:04Z","sessionId":"main123","message":{"role":"user","content":"plan it"}}
+`,
+		// Agent ccc has no Task call anywhere and falls back to the root
+		"agent-ccc.jsonl": `{"type":"user","timestamp":"2024-01-01T10:00:05Z","sessionId":"main123","message":{"role":"user","content":"orphan"}}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	scanner := NewScanner(ScannerOptions{ProjectsDir: tmpDir})
+	roots, err := scanner.BuildAgentTree(projectDir, "main123")
+	if err != nil {
+		t.Fatalf("BuildAgentTree() error = %v", err)
+	}
+
+	if len(roots) != 2 {
+		t.Fatalf("expected 2 root agents, got %d", len(roots))
+	}
+	if roots[0].Meta.ID != "aaa" || roots[1].Meta.ID != "ccc" {
+		t.Errorf("roots = [%s %s], want [aaa ccc]", roots[0].Meta.ID, roots[1].Meta.ID)
+	}
+	if roots[0].Info == nil || roots[0].Info.SubagentType != "Explore" {
+		t.Errorf("expected aaa to be an Explore agent, got %+v", roots[0].Info)
+	}
+	if len(roots[0].Children) != 1 || roots[0].Children[0].Meta.ID != "bbb" {
+		t.Fatalf("expected bbb nested under aaa, got %+v", roots[0].Children)
+	}
+	if roots[0].Children[0].Info == nil || roots[0].Children[0].Info.SubagentType != "Plan" {
+		t.Errorf("expected bbb to be a Plan agent, got %+v", roots[0].Children[0].Info)
+	}
+	if roots[1].Info != nil {
+		t.Errorf("expected no info for orphan agent, got %+v", roots[1].Info)
+	}
+}
+
+func TestCreatesCycle(t *testing.T) {
+	parentOf := map[string]string{"b": "a", "c": "b"}
+
+	if !createsCycle(parentOf, "a", "c") {
+		t.Error("a -> c should create a cycle (c descends from a)")
+	}
+	if createsCycle(parentOf, "d", "c") {
+		t.Error("d -> c should not create a cycle")
+	}
+	if !createsCycle(parentOf, "a", "a") {
+		t.Error("self-parenting should be a cycle")
+	}
+}
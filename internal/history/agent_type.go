@@ -0,0 +1,60 @@
+package history
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// agentTypeResolver resolves the subagent_type of agents from the Task calls
+// in their parent conversations. Each parent is loaded at most once, so
+// filtering many agents of the same conversation reads it a single time.
+// It is safe for concurrent use.
+type agentTypeResolver struct {
+	mu      sync.Mutex
+	parents map[string]*parentTasks // Keyed by parent conversation path
+}
+
+// parentTasks holds the Task call matcher of one parent conversation, loaded once.
+type parentTasks struct {
+	once    sync.Once
+	matcher *taskMatcher // Nil if the parent can't be loaded
+}
+
+// newAgentTypeResolver creates an empty resolver.
+func newAgentTypeResolver() *agentTypeResolver {
+	return &agentTypeResolver{parents: make(map[string]*parentTasks)}
+}
+
+// isOfType reports whether m is an agent spawned with the given
+// subagent_type (case-insensitive).
+func (r *agentTypeResolver) isOfType(m *ConversationMeta, agentType string) bool {
+	if !m.IsAgent || m.ParentSessionID == "" {
+		return false
+	}
+	matcher := r.matcher(ConversationFilePath(filepath.Dir(m.Path), m.ParentSessionID))
+	if matcher == nil {
+		return false
+	}
+	call, _ := matcher.match(m.ID)
+	return call != nil && strings.EqualFold(call.Input.SubagentType, agentType)
+}
+
+// matcher returns the Task call matcher for the conversation at parentPath,
+// loading it on first use. It is nil if the parent can't be loaded.
+func (r *agentTypeResolver) matcher(parentPath string) *taskMatcher {
+	r.mu.Lock()
+	p, ok := r.parents[parentPath]
+	if !ok {
+		p = &parentTasks{}
+		r.parents[parentPath] = p
+	}
+	r.mu.Unlock()
+
+	p.once.Do(func() {
+		if conv, err := LoadConversation(parentPath); err == nil {
+			p.matcher = newTaskMatcher(parentPath, conv.Entries)
+		}
+	})
+	return p.matcher
+}
@@ -0,0 +1,45 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAgentTypeResolver_LoadsParentOnce(t *testing.T) {
+	projectDir := filepath.Join(t.TempDir(), "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	parentPath := filepath.Join(projectDir, "main-123.jsonl")
+	parent := `{"type":"assistant","uuid":"u1","sessionId":"main-123","message":{"role":"assistant","content":[` +
+		`{"type":"tool_use","id":"toolu_aaa111","name":"Task","input":{"subagent_type":"Explore"}}]}}`
+	if err := os.WriteFile(parentPath, []byte(parent), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	agent := &ConversationMeta{
+		ID:              "aaa111",
+		Path:            filepath.Join(projectDir, "agent-aaa111.jsonl"),
+		IsAgent:         true,
+		ParentSessionID: "main-123",
+	}
+	resolver := newAgentTypeResolver()
+	if !resolver.isOfType(agent, "Explore") {
+		t.Error("expected aaa111 to be an Explore agent")
+	}
+
+	// Later lookups are served from the cache, even if the parent disappears
+	if err := os.Remove(parentPath); err != nil {
+		t.Fatal(err)
+	}
+	if !resolver.isOfType(agent, "explore") {
+		t.Error("expected the cached parent to still resolve aaa111")
+	}
+	if resolver.isOfType(agent, "Plan") {
+		t.Error("aaa111 is not a Plan agent")
+	}
+	if resolver.isOfType(&ConversationMeta{ID: "main-123"}, "Explore") {
+		t.Error("main conversations have no agent type")
+	}
+}
@@ -0,0 +1,107 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/dmora/ch/internal/parallel"
+)
+
+// ErrNotFound is returned when no conversation matches an ID.
+var ErrNotFound = errors.New("conversation not found")
+
+// ClientOptions configures a Client.
+type ClientOptions struct {
+	ProjectsDir string // Base projects directory (default: ~/.claude/projects)
+	Workers     int    // Number of parallel workers (default: number of CPUs)
+}
+
+// Client is a single entry point to the conversation history in one projects
+// directory. It wraps the scanner, search, and project functions so they all
+// share the same directory and worker settings.
+type Client struct {
+	opts ClientOptions
+}
+
+// NewClient creates a client for the given options.
+func NewClient(opts ClientOptions) *Client {
+	if opts.ProjectsDir == "" {
+		opts.ProjectsDir = DefaultProjectsDir()
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = parallel.DefaultWorkers()
+	}
+	return &Client{opts: opts}
+}
+
+// ProjectsDir returns the projects directory the client reads.
+func (c *Client) ProjectsDir() string {
+	return c.opts.ProjectsDir
+}
+
+// List scans conversations. The projects directory and worker count of opts
+// are set from the client.
+func (c *Client) List(ctx context.Context, opts ScannerOptions) ([]*ConversationMeta, error) {
+	opts.ProjectsDir = c.opts.ProjectsDir
+	opts.Workers = c.opts.Workers
+	return NewScanner(opts).ScanAll(ctx)
+}
+
+// Search searches conversations for query. The projects directory and worker
+// count of opts are set from the client.
+func (c *Client) Search(ctx context.Context, query string, opts SearchOptions) ([]*SearchResult, *SearchSummary, error) {
+	opts.ProjectsDir = c.opts.ProjectsDir
+	opts.Workers = c.opts.Workers
+	return SearchWithSummary(ctx, query, opts)
+}
+
+// Projects lists all projects.
+func (c *Client) Projects() ([]*Project, error) {
+	return ListProjectsWithWorkers(c.opts.ProjectsDir, c.opts.Workers)
+}
+
+// Find returns the path of the conversation file with the given full or
+// partial ID, resolved as by ResolveConversation.
+func (c *Client) Find(id string) (string, error) {
+	meta, err := c.Resolve(id)
+	if err != nil {
+		return "", err
+	}
+	return meta.Path, nil
+}
+
+// Resolve returns the metadata of the conversation with the given full or
+// partial ID, resolved as by ResolveConversation.
+func (c *Client) Resolve(id string) (*ConversationMeta, error) {
+	return resolveConversation(c.opts.ProjectsDir, id, c.opts.Workers)
+}
+
+// Get loads the conversation with the given full or partial ID.
+func (c *Client) Get(id string) (*Conversation, error) {
+	meta, err := c.Resolve(id)
+	if err != nil {
+		return nil, err
+	}
+	return LoadConversationWithMeta(meta)
+}
+
+// Agents returns the agents spawned by the conversation with the given ID.
+func (c *Client) Agents(id string) ([]*ConversationMeta, error) {
+	meta, err := c.Resolve(id)
+	if err != nil {
+		return nil, err
+	}
+	path := meta.Path
+	if meta.IsAgent {
+		return nil, fmt.Errorf("%s is an agent conversation; agents are listed for their parent", id)
+	}
+
+	sessionID := meta.SessionID
+	if sessionID == "" {
+		sessionID = meta.ID
+	}
+	scanner := NewScanner(ScannerOptions{ProjectsDir: c.opts.ProjectsDir, Workers: c.opts.Workers})
+	return scanner.FindAgents(filepath.Dir(path), sessionID)
+}
@@ -0,0 +1,107 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	files := map[string]string{
+		"abc12345-1111.jsonl": `{"type":"user","sessionId":"abc12345-1111","message":{"role":"user","content":"Deploy with docker"}}`,
+		"agent-def456.jsonl":  `{"type":"user","sessionId":"abc12345-1111","isSidechain":true,"message":{"role":"user","content":"explore"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	client := NewClient(ClientOptions{ProjectsDir: tmpDir})
+
+	t.Run("find", func(t *testing.T) {
+		for id, want := range map[string]string{
+			"abc12345-1111": "abc12345-1111.jsonl",
+			"abc123":        "abc12345-1111.jsonl",
+			"agent-def456":  "agent-def456.jsonl",
+		} {
+			path, err := client.Find(id)
+			if err != nil {
+				t.Errorf("Find(%q) error = %v", id, err)
+				continue
+			}
+			if filepath.Base(path) != want {
+				t.Errorf("Find(%q) = %s, want %s", id, path, want)
+			}
+		}
+		if _, err := client.Find("zzz"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Find(zzz) error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		meta, err := client.Resolve("agent-def456")
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if meta.ID != "def456" || !meta.IsAgent || meta.ParentSessionID != "abc12345-1111" {
+			t.Errorf("Resolve() = %+v, want agent def456 of abc12345-1111", meta)
+		}
+
+		// Loading with the resolved metadata keeps it rather than rescanning
+		meta.Preview = "resolved"
+		conv, err := LoadConversationWithMeta(meta)
+		if err != nil {
+			t.Fatalf("LoadConversationWithMeta() error = %v", err)
+		}
+		if conv.Meta.Preview != "resolved" || len(conv.Entries) != 1 {
+			t.Errorf("LoadConversationWithMeta() = %q with %d entries, want the given metadata and 1 entry", conv.Meta.Preview, len(conv.Entries))
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		conv, err := client.Get("abc123")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if conv.Meta.ID != "abc12345-1111" || len(conv.Entries) != 1 {
+			t.Errorf("Get() = %s with %d entries, want abc12345-1111 with 1", conv.Meta.ID, len(conv.Entries))
+		}
+	})
+
+	t.Run("agents", func(t *testing.T) {
+		agents, err := client.Agents("abc123")
+		if err != nil {
+			t.Fatalf("Agents() error = %v", err)
+		}
+		if len(agents) != 1 || agents[0].ID != "def456" {
+			t.Errorf("Agents() = %v, want [def456]", agents)
+		}
+		if _, err := client.Agents("agent-def456"); err == nil {
+			t.Error("Agents() of an agent should fail")
+		}
+	})
+
+	t.Run("list, search, and projects", func(t *testing.T) {
+		metas, err := client.List(context.Background(), ScannerOptions{IncludeAgents: true})
+		if err != nil || len(metas) != 2 {
+			t.Errorf("List() = %d conversations, %v; want 2", len(metas), err)
+		}
+		results, _, err := client.Search(context.Background(), "docker", SearchOptions{})
+		if err != nil || len(results) != 1 {
+			t.Errorf("Search() = %d results, %v; want 1", len(results), err)
+		}
+		projects, err := client.Projects()
+		if err != nil || len(projects) != 1 {
+			t.Errorf("Projects() = %d projects, %v; want 1", len(projects), err)
+		}
+	})
+}
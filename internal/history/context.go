@@ -0,0 +1,62 @@
+package history
+
+import (
+	"bufio"
+	"strings"
+)
+
+// SessionContext holds environment details that Claude Code embeds in the
+// opening message of a session, such as the git status and <env> blocks.
+type SessionContext struct {
+	Branch   string // Git branch the session started on
+	Platform string // Operating system platform (e.g. darwin, linux)
+}
+
+// Fields of SessionContext, as named by contextKeys.
+const (
+	contextBranch   = "branch"
+	contextPlatform = "platform"
+)
+
+// contextKeys maps the lowercased "Key:" labels found in injected context to
+// the SessionContext field they populate.
+var contextKeys = map[string]string{
+	"current branch": contextBranch,
+	"git branch":     contextBranch,
+	"gitbranch":      contextBranch,
+	"branch":         contextBranch,
+	"platform":       contextPlatform,
+	"os":             contextPlatform,
+}
+
+// ExtractSessionContext parses "Key: value" lines from the text of a
+// session's first message. The first value seen for each field wins;
+// absent fields are left empty, and values containing spaces are ignored
+// so prose lines aren't mistaken for context.
+func ExtractSessionContext(text string) SessionContext {
+	var ctx SessionContext
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(strings.TrimLeft(key, "-* ")))
+		value = strings.Trim(strings.TrimSpace(value), "`'\"")
+		if value == "" || strings.ContainsAny(value, " \t") {
+			continue
+		}
+		switch contextKeys[key] {
+		case contextBranch:
+			if ctx.Branch == "" {
+				ctx.Branch = value
+			}
+		case contextPlatform:
+			if ctx.Platform == "" {
+				ctx.Platform = value
+			}
+		}
+	}
+	return ctx
+}
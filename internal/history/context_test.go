@@ -0,0 +1,81 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractSessionContext(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want SessionContext
+	}{
+		{
+			name: "git status and env blocks",
+			text: "<system-reminder>\ngitStatus: snapshot\nCurrent branch: feature/login\n\nMain branch: main\n</system-reminder>\n<env>\nPlatform: darwin\nOS Version: Darwin 24.1.0\n</env>",
+			want: SessionContext{Branch: "feature/login", Platform: "darwin"},
+		},
+		{
+			name: "bulleted and quoted values",
+			text: "- Git branch: `main`\n- OS: linux",
+			want: SessionContext{Branch: "main", Platform: "linux"},
+		},
+		{
+			name: "first value wins",
+			text: "Branch: dev\nBranch: main",
+			want: SessionContext{Branch: "dev"},
+		},
+		{
+			name: "prose is ignored",
+			text: "Branch: the one I made yesterday\nPlease fix the build",
+			want: SessionContext{},
+		},
+		{
+			name: "absent fields",
+			text: "Fix the failing test in parser.go",
+			want: SessionContext{},
+		},
+		{name: "empty", text: "", want: SessionContext{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractSessionContext(tt.text); got != tt.want {
+				t.Errorf("ExtractSessionContext() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanConversationMeta_SessionContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abc123.jsonl")
+	content := `{"type":"user","gitBranch":"main","message":{"role":"user","content":"Current branch: feature/x\nPlatform: linux"}}
+{"type":"assistant","gitBranch":"main","message":{"role":"assistant","content":"ok"}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	meta, err := ScanConversationMeta(path)
+	if err != nil {
+		t.Fatalf("ScanConversationMeta() error = %v", err)
+	}
+	if meta.Branch != "feature/x" || meta.Platform != "linux" {
+		t.Errorf("Branch, Platform = %q, %q; want feature/x, linux", meta.Branch, meta.Platform)
+	}
+
+	// Without context in the message, fall back to the recorded gitBranch
+	content = `{"type":"user","gitBranch":"main","message":{"role":"user","content":"hello"}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	meta, err = ScanConversationMeta(path)
+	if err != nil {
+		t.Fatalf("ScanConversationMeta() error = %v", err)
+	}
+	if meta.Branch != "main" || meta.Platform != "" {
+		t.Errorf("Branch, Platform = %q, %q; want main, empty", meta.Branch, meta.Platform)
+	}
+}
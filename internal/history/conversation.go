@@ -2,8 +2,11 @@ package history
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/dmora/ch/internal/jsonl"
@@ -11,44 +14,90 @@ import (
 
 // ConversationMeta contains lightweight metadata for listing conversations.
 type ConversationMeta struct {
-	ID              string    // UUID/AgentID from filename
-	SessionID       string    // Session ID (for agents, points to parent)
-	Path            string    // Full file path
-	Project         string    // Project directory name (encoded)
-	ProjectPath     string    // Decoded project path
-	Timestamp       time.Time // From first entry or file mtime
-	Preview         string    // First ~100 chars of first user message
-	MessageCount    int       // Number of user+assistant messages
-	IsAgent         bool      // Is this an agent/sidechain conversation
-	AgentCount      int       // Number of agents spawned (for main conversations)
-	ParentSessionID string    // Parent session ID (for agents only)
-	FileSize        int64     // For stats
-	Model           string    // Model used (from first assistant message)
+	ID              string        // UUID/AgentID from filename
+	SessionID       string        // Session ID (for agents, points to parent)
+	Path            string        // Full file path
+	Project         string        // Project directory name (encoded)
+	ProjectPath     string        // Decoded project path
+	Timestamp       time.Time     // From first entry or file mtime
+	TimeSource      string        // Where Timestamp came from: TimeSourceEntry or TimeSourceMtime
+	Duration        time.Duration // First to last timestamped entry (0 if fewer than two)
+	Preview         string        // First ~100 chars of first user message
+	MessageCount    int           // Number of user+assistant messages
+	IsAgent         bool          // Is this an agent/sidechain conversation
+	AgentReason     string        // Why IsAgent is set: AgentReasonFilename or AgentReasonSidechain
+	AgentCount      int           // Number of agents spawned (for main conversations)
+	ParentSessionID string        // Parent session ID (for agents only)
+	FileSize        int64         // For stats
+	Model           string        // Model used (from first assistant message)
+	CWD             string        // Working directory recorded in the entries (first non-empty)
+	Branch          string        // Git branch from the first message's context, else the entries
+	Platform        string        // OS platform from the first message's context
 }
 
 // Conversation represents a fully loaded conversation with all messages.
 type Conversation struct {
 	Meta    ConversationMeta
 	Entries []*jsonl.RawEntry
+
+	// SkippedLines lists malformed lines that were skipped while loading
+	// (e.g. partial lines written during a crash).
+	SkippedLines []jsonl.LineError
+
+	// Offsets holds the byte offset of each entry's line in the file,
+	// parallel to Entries. Nil when loaded from a reader.
+	Offsets []int64
+}
+
+// Reasons a conversation is classified as an agent (ConversationMeta.AgentReason).
+const (
+	AgentReasonFilename  = "filename"  // File is named agent-*.jsonl
+	AgentReasonSidechain = "sidechain" // First entry has isSidechain set
+)
+
+// Sources of ConversationMeta.Timestamp (ConversationMeta.TimeSource).
+const (
+	TimeSourceEntry = "entry" // First entry with a timestamp
+	TimeSourceMtime = "mtime" // File modification time; no entry has a timestamp
+)
+
+// HasMtimeTime reports whether Timestamp is the file's modification time
+// rather than a time recorded in the conversation.
+func (m *ConversationMeta) HasMtimeTime() bool {
+	return m.TimeSource == TimeSourceMtime
+}
+
+// EndTime returns the time of the last timestamped entry, or Timestamp when
+// the conversation has fewer than two.
+func (m *ConversationMeta) EndTime() time.Time {
+	return m.Timestamp.Add(m.Duration)
 }
 
+// DefaultPreviewLen is the default maximum length of ConversationMeta.Preview.
+const DefaultPreviewLen = 100
+
 // ScanConversationMeta scans a JSONL file to extract metadata efficiently.
 // It only parses the minimum necessary to extract preview and counts.
 func ScanConversationMeta(path string) (*ConversationMeta, error) {
-	file, err := os.Open(path)
+	return scanConversationMeta(path, DefaultPreviewLen)
+}
+
+// scanConversationMeta is ScanConversationMeta with a configurable preview length.
+func scanConversationMeta(path string, previewLen int) (*ConversationMeta, error) {
+	info, err := os.Stat(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	info, err := file.Stat()
+	file, err := jsonl.Open(path)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
 	meta := initMetaFromPath(path, info)
 	parser := jsonl.NewParserFromReader(file)
-	state := &metaScanState{}
+	state := &metaScanState{previewLen: previewLen}
 
 	for {
 		entry, err := parser.Next()
@@ -72,11 +121,13 @@ func initMetaFromPath(path string, info os.FileInfo) *ConversationMeta {
 		ProjectPath: DecodeProjectPath(projectDir),
 		FileSize:    info.Size(),
 		Timestamp:   info.ModTime(),
+		TimeSource:  TimeSourceMtime,
 		IsAgent:     IsAgentFile(filename),
 	}
 
 	if meta.IsAgent {
 		meta.ID = ExtractAgentID(filename)
+		meta.AgentReason = AgentReasonFilename
 	} else {
 		meta.ID = ExtractSessionID(filename)
 		meta.SessionID = meta.ID
@@ -87,17 +138,75 @@ func initMetaFromPath(path string, info os.FileInfo) *ConversationMeta {
 
 // metaScanState tracks scanning progress across entries.
 type metaScanState struct {
-	firstUserFound   bool
-	firstTimestamp   time.Time
+	entrySeen       bool
+	previewFound    bool
+	fallbackPreview string // First user preview, used if no genuine message is found
+	firstTimestamp  time.Time
+	lastTimestamp   time.Time // Latest entry timestamp seen
+	contextScanned  bool      // First user message checked for session context
+	previewLen      int
 }
 
 // updateMetaFromEntry updates metadata from a single entry.
 func updateMetaFromEntry(meta *ConversationMeta, entry *jsonl.RawEntry, state *metaScanState) {
+	if !state.entrySeen {
+		state.entrySeen = true
+		if entry.IsSidechain && !meta.IsAgent {
+			markSidechainAgent(meta)
+		}
+	}
 	updateSessionInfo(meta, entry)
+	if meta.CWD == "" {
+		meta.CWD = entry.CWD
+	}
+	updateSessionContext(meta, entry, state)
 	updateTimestamp(meta, entry, state)
 	updateMessageStats(meta, entry, state)
 }
 
+// updateSessionContext fills Branch and Platform from the context embedded in
+// the first user message, falling back to the gitBranch recorded on entries.
+func updateSessionContext(meta *ConversationMeta, entry *jsonl.RawEntry, state *metaScanState) {
+	if entry.Type == jsonl.EntryTypeUser && !state.contextScanned {
+		state.contextScanned = true
+		if msg, err := jsonl.ParseMessage(entry); err == nil && msg != nil {
+			ctx := ExtractSessionContext(jsonl.ExtractText(msg))
+			if ctx.Branch != "" {
+				meta.Branch = ctx.Branch
+			}
+			meta.Platform = ctx.Platform
+		}
+	}
+	if meta.Branch == "" {
+		meta.Branch = entry.GitBranch
+	}
+}
+
+// markSidechainAgent classifies a conversation whose filename doesn't follow
+// the agent-* convention as an agent because its entries are on a sidechain.
+// The session ID is cleared so it is taken from the entries, which for agents
+// point at the parent session.
+func markSidechainAgent(meta *ConversationMeta) {
+	meta.IsAgent = true
+	meta.AgentReason = AgentReasonSidechain
+	meta.SessionID = ""
+}
+
+// isSidechainFile reports whether the first entry of the conversation at
+// path is on a sidechain, the same test updateMetaFromEntry uses to classify
+// agents that aren't named agent-*.
+func isSidechainFile(path string) bool {
+	parser, err := jsonl.NewParser(path)
+	if err != nil {
+		return false
+	}
+	defer parser.Close()
+	parser.SetLenient(true)
+
+	entry, err := parser.Next()
+	return err == nil && entry != nil && entry.IsSidechain
+}
+
 // updateSessionInfo extracts session info from entry.
 func updateSessionInfo(meta *ConversationMeta, entry *jsonl.RawEntry) {
 	if entry.SessionID == "" {
@@ -111,14 +220,26 @@ func updateSessionInfo(meta *ConversationMeta, entry *jsonl.RawEntry) {
 	}
 }
 
-// updateTimestamp updates timestamp from first entry with timestamp.
+// updateTimestamp sets the timestamp from the first entry with one, and the
+// duration from the first to the latest timestamp seen so far.
 func updateTimestamp(meta *ConversationMeta, entry *jsonl.RawEntry, state *metaScanState) {
-	if entry.Timestamp == "" || !state.firstTimestamp.IsZero() {
+	if entry.Timestamp == "" {
+		return
+	}
+	t, err := time.Parse(time.RFC3339, entry.Timestamp)
+	if err != nil {
 		return
 	}
-	if t, err := time.Parse(time.RFC3339, entry.Timestamp); err == nil {
+	if state.firstTimestamp.IsZero() {
 		state.firstTimestamp = t
 		meta.Timestamp = t
+		meta.TimeSource = TimeSourceEntry
+	}
+	if t.After(state.lastTimestamp) {
+		state.lastTimestamp = t
+		if d := t.Sub(state.firstTimestamp); d > 0 {
+			meta.Duration = d
+		}
 	}
 }
 
@@ -128,9 +249,8 @@ func updateMessageStats(meta *ConversationMeta, entry *jsonl.RawEntry, state *me
 		meta.MessageCount++
 	}
 
-	if entry.Type == jsonl.EntryTypeUser && !state.firstUserFound {
-		meta.Preview = jsonl.ExtractPreview(entry.Message, 100)
-		state.firstUserFound = true
+	if entry.Type == jsonl.EntryTypeUser && !state.previewFound {
+		updatePreview(meta, entry, state)
 	}
 
 	if entry.Type == jsonl.EntryTypeAssistant && meta.Model == "" && entry.Message != nil {
@@ -141,27 +261,118 @@ func updateMessageStats(meta *ConversationMeta, entry *jsonl.RawEntry, state *me
 	}
 }
 
+// updatePreview sets the preview from the first genuine user message, skipping
+// injected context and slash-command entries. Until one is found, the first
+// non-empty user preview is used as a fallback.
+func updatePreview(meta *ConversationMeta, entry *jsonl.RawEntry, state *metaScanState) {
+	preview := jsonl.ExtractPreview(entry.Message, state.previewLen)
+	if preview == "" {
+		return
+	}
+	if state.fallbackPreview == "" {
+		state.fallbackPreview = preview
+		meta.Preview = preview
+	}
+
+	var msg jsonl.Message
+	if json.Unmarshal(entry.Message, &msg) != nil || IsInjectedText(jsonl.ExtractText(&msg)) {
+		return
+	}
+	meta.Preview = preview
+	state.previewFound = true
+}
+
+// injectedPrefixes are prefixes of user entries written by Claude Code rather
+// than typed by the user.
+var injectedPrefixes = []string{
+	"<command-",
+	"<local-command-",
+	"<system-reminder>",
+	"<user-prompt-submit-hook>",
+	"Caveat:",
+}
+
+// IsInjectedText reports whether user message text looks like injected context
+// or a slash command rather than a genuine human message: it starts with a
+// known prefix, or consists only of XML-like markup.
+func IsInjectedText(text string) bool {
+	text = strings.TrimSpace(text)
+	for _, prefix := range injectedPrefixes {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return strings.HasPrefix(text, "<") && strings.HasSuffix(text, ">")
+}
+
 // LoadConversation fully loads a conversation from a JSONL file.
 func LoadConversation(path string) (*Conversation, error) {
 	meta, err := ScanConversationMeta(path)
 	if err != nil {
 		return nil, err
 	}
+	return LoadConversationWithMeta(meta)
+}
 
-	parser, err := jsonl.NewParser(path)
+// LoadConversationWithMeta loads the entries of the conversation described
+// by meta, such as the metadata ResolveConversation returns, without
+// scanning the file for its metadata again.
+func LoadConversationWithMeta(meta *ConversationMeta) (*Conversation, error) {
+	parser, err := jsonl.NewParser(meta.Path)
 	if err != nil {
 		return nil, err
 	}
 	defer parser.Close()
 
-	entries, err := parser.ParseAll()
+	parser.SetLenient(true)
+	entries, offsets, err := parser.ParseAllWithOffsets()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conversation{
+		Meta:         *meta,
+		Entries:      entries,
+		SkippedLines: parser.Errors(),
+		Offsets:      offsets,
+	}, nil
+}
+
+// LoadConversationFromReader loads a conversation from a JSONL stream such as stdin.
+// There is no filename to derive the ID from, so the ID comes from the agent or
+// session ID in the entries, falling back to name. Meta.Path is left empty.
+func LoadConversationFromReader(r io.Reader, name string) (*Conversation, error) {
+	parser := jsonl.NewParserFromReader(r)
+	entries, skipped, err := parser.ParseAllLenient()
 	if err != nil {
 		return nil, err
 	}
 
+	meta := &ConversationMeta{}
+	for _, entry := range entries {
+		if entry.AgentID != "" {
+			meta.IsAgent = true
+			meta.ID = entry.AgentID
+			break
+		}
+	}
+
+	state := &metaScanState{previewLen: DefaultPreviewLen}
+	for _, entry := range entries {
+		updateMetaFromEntry(meta, entry, state)
+	}
+
+	if meta.ID == "" {
+		meta.ID = meta.SessionID
+	}
+	if meta.ID == "" {
+		meta.ID = name
+	}
+
 	return &Conversation{
-		Meta:    *meta,
-		Entries: entries,
+		Meta:         *meta,
+		Entries:      entries,
+		SkippedLines: skipped,
 	}, nil
 }
 
@@ -209,6 +420,34 @@ func (c *Conversation) GetSummaries() []*jsonl.RawEntry {
 	return summaries
 }
 
+// FindEntryByUUID returns the position of the entry with the given UUID, or -1 if absent.
+func FindEntryByUUID(entries []*jsonl.RawEntry, uuid string) int {
+	if uuid == "" {
+		return -1
+	}
+	for i, entry := range entries {
+		if entry.UUID == uuid {
+			return i
+		}
+	}
+	return -1
+}
+
+// TrimThroughUUID drops the entries up to and including the one with the
+// given UUID, keeping Offsets aligned, so only later entries remain. It
+// returns an error if no entry in the conversation has that UUID.
+func (c *Conversation) TrimThroughUUID(uuid string) error {
+	i := FindEntryByUUID(c.Entries, uuid)
+	if i < 0 {
+		return fmt.Errorf("message not found: %s", uuid)
+	}
+	c.Entries = c.Entries[i+1:]
+	if c.Offsets != nil {
+		c.Offsets = c.Offsets[i+1:]
+	}
+	return nil
+}
+
 // ParseMessageEntry parses a raw entry into a Message struct.
 func ParseMessageEntry(entry *jsonl.RawEntry) (*jsonl.Message, error) {
 	return jsonl.ParseMessage(entry)
@@ -3,7 +3,11 @@ package history
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/dmora/ch/internal/jsonl"
 )
 
 func TestScanConversationMeta(t *testing.T) {
@@ -58,3 +62,162 @@ func TestScanConversationMeta(t *testing.T) {
 		t.Error("Expected MessageCount > 0")
 	}
 }
+
+func TestLoadConversationFromReader(t *testing.T) {
+	content := `{"type":"user","timestamp":"2024-01-01T10:00:00Z","sessionId":"sess-1","message":{"role":"user","content":"Hello"}}
+{"type":"assistant","timestamp":"2024-01-01T10:00:01Z","sessionId":"sess-1","cwd":"/home/me/app","message":{"role":"assistant","model":"claude-3","content":[{"type":"text","text":"Hi!"}]}}
+`
+	conv, err := LoadConversationFromReader(strings.NewReader(content), "stdin")
+	if err != nil {
+		t.Fatalf("LoadConversationFromReader failed: %v", err)
+	}
+	if conv.Meta.ID != "sess-1" {
+		t.Errorf("ID = %q, want sess-1", conv.Meta.ID)
+	}
+	if conv.Meta.MessageCount != 2 {
+		t.Errorf("MessageCount = %d, want 2", conv.Meta.MessageCount)
+	}
+	if conv.Meta.Model != "claude-3" {
+		t.Errorf("Model = %q, want claude-3", conv.Meta.Model)
+	}
+	if len(conv.Entries) != 2 {
+		t.Errorf("len(Entries) = %d, want 2", len(conv.Entries))
+	}
+	if conv.Meta.CWD != "/home/me/app" {
+		t.Errorf("CWD = %q, want /home/me/app", conv.Meta.CWD)
+	}
+
+	agent, err := LoadConversationFromReader(strings.NewReader(`{"type":"user","sessionId":"parent-1","agentId":"a1","isSidechain":true,"message":{"role":"user","content":"task"}}`), "stdin")
+	if err != nil {
+		t.Fatalf("LoadConversationFromReader failed: %v", err)
+	}
+	if !agent.Meta.IsAgent || agent.Meta.ID != "a1" || agent.Meta.ParentSessionID != "parent-1" {
+		t.Errorf("agent meta = %+v, want agent a1 with parent parent-1", agent.Meta)
+	}
+
+	empty, err := LoadConversationFromReader(strings.NewReader(""), "stdin")
+	if err != nil {
+		t.Fatalf("LoadConversationFromReader failed: %v", err)
+	}
+	if empty.Meta.ID != "stdin" {
+		t.Errorf("ID = %q, want fallback name stdin", empty.Meta.ID)
+	}
+}
+
+func TestIsInjectedText(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"<command-name>/clear</command-name>", true},
+		{"<local-command-stdout></local-command-stdout>", true},
+		{"Caveat: The messages below were generated by the user while running local commands.", true},
+		{"  <system-reminder>context</system-reminder>", true},
+		{"<context>\nproject notes\n</context>", true},
+		{"Fix the failing test in parser.go", false},
+		{"Why does <div> render twice?", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsInjectedText(tt.text); got != tt.want {
+			t.Errorf("IsInjectedText(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestScanConversationMeta_SkipsInjectedPreview(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abc123.jsonl")
+	content := `{"type":"user","message":{"role":"user","content":"Caveat: generated by local commands."}}
+{"type":"user","message":{"role":"user","content":"<command-name>/model</command-name>"}}
+{"type":"user","message":{"role":"user","content":"Refactor the scanner"}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	meta, err := ScanConversationMeta(path)
+	if err != nil {
+		t.Fatalf("ScanConversationMeta() error = %v", err)
+	}
+	if meta.Preview != "Refactor the scanner" {
+		t.Errorf("Preview = %q, want first genuine message", meta.Preview)
+	}
+
+	// With only injected messages, fall back to the first one
+	content = `{"type":"user","message":{"role":"user","content":"<command-name>/clear</command-name>"}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	meta, err = ScanConversationMeta(path)
+	if err != nil {
+		t.Fatalf("ScanConversationMeta() error = %v", err)
+	}
+	if meta.Preview != "<command-name>/clear</command-name>" {
+		t.Errorf("Preview = %q, want fallback to first user message", meta.Preview)
+	}
+}
+
+func TestScanConversationMeta_Duration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abc123.jsonl")
+	content := `{"type":"user","timestamp":"2024-01-01T10:00:00Z","message":{"role":"user","content":"start"}}
+{"type":"summary","summary":"no timestamp"}
+{"type":"assistant","timestamp":"2024-01-01T12:05:00Z","message":{"role":"assistant","content":"done"}}
+{"type":"user","timestamp":"2024-01-01T11:00:00Z","message":{"role":"user","content":"out of order"}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	meta, err := ScanConversationMeta(path)
+	if err != nil {
+		t.Fatalf("ScanConversationMeta() error = %v", err)
+	}
+	if want := 2*time.Hour + 5*time.Minute; meta.Duration != want {
+		t.Errorf("Duration = %v, want %v", meta.Duration, want)
+	}
+
+	// A single timestamp has no duration
+	content = `{"type":"user","timestamp":"2024-01-01T10:00:00Z","message":{"role":"user","content":"only"}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	meta, err = ScanConversationMeta(path)
+	if err != nil {
+		t.Fatalf("ScanConversationMeta() error = %v", err)
+	}
+	if meta.Duration != 0 {
+		t.Errorf("Duration = %v, want 0", meta.Duration)
+	}
+}
+
+func TestConversation_TrimThroughUUID(t *testing.T) {
+	newConv := func() *Conversation {
+		return &Conversation{
+			Entries: []*jsonl.RawEntry{{UUID: "u1"}, {UUID: "u2"}, {UUID: "u3"}},
+			Offsets: []int64{0, 10, 20},
+		}
+	}
+
+	conv := newConv()
+	if err := conv.TrimThroughUUID("u1"); err != nil {
+		t.Fatalf("TrimThroughUUID() error = %v", err)
+	}
+	if len(conv.Entries) != 2 || conv.Entries[0].UUID != "u2" || conv.Offsets[0] != 10 {
+		t.Errorf("after trim: entries = %d, first = %q, offset = %d; want 2, u2, 10", len(conv.Entries), conv.Entries[0].UUID, conv.Offsets[0])
+	}
+
+	conv = newConv()
+	if err := conv.TrimThroughUUID("u3"); err != nil || len(conv.Entries) != 0 {
+		t.Errorf("trim through last entry: err = %v, %d entries left", err, len(conv.Entries))
+	}
+
+	conv = newConv()
+	if err := conv.TrimThroughUUID("missing"); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("TrimThroughUUID(missing) error = %v, want message not found", err)
+	}
+	if len(conv.Entries) != 3 {
+		t.Error("a failed trim should leave entries unchanged")
+	}
+}
@@ -0,0 +1,89 @@
+package history
+
+import (
+	"os"
+
+	"github.com/dmora/ch/internal/jsonl"
+)
+
+// EntryCounts tallies a conversation's entries by kind.
+type EntryCounts struct {
+	Entries      int   // All parsed entries
+	User         int   // User messages
+	Assistant    int   // Assistant messages
+	System       int   // System messages
+	Summary      int   // Summary entries
+	ToolCalls    int   // tool_use blocks
+	ToolResults  int   // tool_result blocks
+	Other        int   // Other entry types (file snapshots, queue operations, ...)
+	SkippedLines int   // Malformed lines that could not be parsed
+	FileSize     int64 // Size of the file on disk
+}
+
+// Messages returns the number of user, assistant, and system messages, the
+// entries ch show numbers with [N].
+func (c *EntryCounts) Messages() int {
+	return c.User + c.Assistant + c.System
+}
+
+// CountEntries counts the entries of a conversation file in a single
+// streaming pass, without loading the conversation into memory.
+func CountEntries(path string) (*EntryCounts, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	parser, err := jsonl.NewParser(path)
+	if err != nil {
+		return nil, err
+	}
+	defer parser.Close()
+	parser.SetLenient(true)
+
+	counts := &EntryCounts{FileSize: info.Size()}
+	for {
+		entry, err := parser.Next()
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			break
+		}
+		counts.add(entry)
+	}
+	counts.SkippedLines = len(parser.Errors())
+	return counts, nil
+}
+
+// add counts one entry and the tool blocks of its message.
+func (c *EntryCounts) add(entry *jsonl.RawEntry) {
+	c.Entries++
+	switch entry.Type {
+	case jsonl.EntryTypeUser:
+		c.User++
+	case jsonl.EntryTypeAssistant:
+		c.Assistant++
+	case jsonl.EntryTypeSystem:
+		c.System++
+	case jsonl.EntryTypeSummary:
+		c.Summary++
+		return
+	default:
+		c.Other++
+		return
+	}
+
+	msg, err := jsonl.ParseMessage(entry)
+	if err != nil || msg == nil {
+		return
+	}
+	for _, block := range msg.Content {
+		switch block.Type {
+		case jsonl.BlockTypeToolUse:
+			c.ToolCalls++
+		case jsonl.BlockTypeToolResult:
+			c.ToolResults++
+		}
+	}
+}
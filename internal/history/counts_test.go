@@ -0,0 +1,56 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountEntries(t *testing.T) {
+	lines := []string{
+		`{"type":"summary","summary":"Fix the build"}`,
+		`{"type":"user","message":{"role":"user","content":"Fix the build"}}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Checking."},{"type":"tool_use","id":"t1","name":"Bash","input":{}},{"type":"tool_use","id":"t2","name":"Read","input":{}}]}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"},{"type":"tool_result","tool_use_id":"t2","content":"ok"}]}}`,
+		`{"type":"system","message":{"role":"system","content":"hook ran"}}`,
+		`{"type":"file-history-snapshot","messageId":"m1"}`,
+		`{"type":"queue-operation","operation":"enqueue","content":"next"}`,
+		`{"type":"assistant","message":`,
+	}
+	path := filepath.Join(t.TempDir(), "abc123.jsonl")
+	content := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	counts, err := CountEntries(path)
+	if err != nil {
+		t.Fatalf("CountEntries() error = %v", err)
+	}
+
+	want := EntryCounts{
+		Entries:      7,
+		User:         2,
+		Assistant:    1,
+		System:       1,
+		Summary:      1,
+		ToolCalls:    2,
+		ToolResults:  2,
+		Other:        2,
+		SkippedLines: 1,
+		FileSize:     int64(len(content)),
+	}
+	if *counts != want {
+		t.Errorf("CountEntries() = %+v, want %+v", *counts, want)
+	}
+	if counts.Messages() != 4 {
+		t.Errorf("Messages() = %d, want 4", counts.Messages())
+	}
+}
+
+func TestCountEntries_NotFound(t *testing.T) {
+	if _, err := CountEntries(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("CountEntries() should fail for a missing file")
+	}
+}
@@ -0,0 +1,68 @@
+package history
+
+import "sort"
+
+// DuplicateGroup is a conversation stored in more than one file, e.g. after
+// restoring a backup into a different project directory.
+type DuplicateGroup struct {
+	Key        string              // Session ID, or "agent-" + agent ID for agents
+	Keep       *ConversationMeta   // Copy to keep: the largest, newest on ties
+	Duplicates []*ConversationMeta // Other copies, largest first
+}
+
+// ReclaimableBytes returns the space freed by removing the duplicates.
+func (g *DuplicateGroup) ReclaimableBytes() int64 {
+	var total int64
+	for _, m := range g.Duplicates {
+		total += m.FileSize
+	}
+	return total
+}
+
+// FindDuplicates groups conversations that share a session ID (agents share
+// an agent ID) and returns the groups with more than one file, sorted by key.
+func FindDuplicates(metas []*ConversationMeta) []*DuplicateGroup {
+	byKey := make(map[string][]*ConversationMeta)
+	for _, m := range metas {
+		key := duplicateKey(m)
+		if key == "" {
+			continue
+		}
+		byKey[key] = append(byKey[key], m)
+	}
+
+	var groups []*DuplicateGroup
+	for key, copies := range byKey {
+		if len(copies) < 2 {
+			continue
+		}
+		sort.Slice(copies, func(i, j int) bool {
+			a, b := copies[i], copies[j]
+			if a.FileSize != b.FileSize {
+				return a.FileSize > b.FileSize
+			}
+			if !a.Timestamp.Equal(b.Timestamp) {
+				return a.Timestamp.After(b.Timestamp)
+			}
+			return a.Path < b.Path
+		})
+		groups = append(groups, &DuplicateGroup{Key: key, Keep: copies[0], Duplicates: copies[1:]})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+// duplicateKey identifies the conversation a file holds. Agents are keyed by
+// their own ID since their session ID points at the parent.
+func duplicateKey(m *ConversationMeta) string {
+	if m.IsAgent {
+		if m.ID == "" {
+			return ""
+		}
+		return "agent-" + m.ID
+	}
+	return m.SessionID
+}
@@ -0,0 +1,43 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	now := time.Now()
+	metas := []*ConversationMeta{
+		{ID: "s1", SessionID: "s1", Path: "/a/s1.jsonl", FileSize: 100, Timestamp: now},
+		{ID: "s1", SessionID: "s1", Path: "/b/s1.jsonl", FileSize: 300, Timestamp: now},
+		{ID: "s1", SessionID: "s1", Path: "/c/s1.jsonl", FileSize: 300, Timestamp: now.Add(time.Hour)},
+		{ID: "s2", SessionID: "s2", Path: "/a/s2.jsonl", FileSize: 50},
+		{ID: "x1", SessionID: "s2", Path: "/a/agent-x1.jsonl", FileSize: 10, IsAgent: true},
+		{ID: "x1", SessionID: "s2", Path: "/b/agent-x1.jsonl", FileSize: 20, IsAgent: true},
+	}
+
+	groups := FindDuplicates(metas)
+	if len(groups) != 2 {
+		t.Fatalf("FindDuplicates() returned %d groups, want 2", len(groups))
+	}
+
+	agent := groups[0]
+	if agent.Key != "agent-x1" || agent.Keep.Path != "/b/agent-x1.jsonl" {
+		t.Errorf("agent group = %s keep %s, want agent-x1 keep /b/agent-x1.jsonl", agent.Key, agent.Keep.Path)
+	}
+
+	session := groups[1]
+	if session.Key != "s1" {
+		t.Fatalf("Key = %s, want s1", session.Key)
+	}
+	// Largest wins; the newer of the two largest breaks the tie
+	if session.Keep.Path != "/c/s1.jsonl" {
+		t.Errorf("Keep = %s, want /c/s1.jsonl", session.Keep.Path)
+	}
+	if len(session.Duplicates) != 2 || session.Duplicates[0].Path != "/b/s1.jsonl" {
+		t.Errorf("Duplicates = %v, want /b/s1.jsonl first", session.Duplicates)
+	}
+	if got := session.ReclaimableBytes(); got != 400 {
+		t.Errorf("ReclaimableBytes() = %d, want 400", got)
+	}
+}
@@ -0,0 +1,80 @@
+package history
+
+import (
+	"time"
+
+	"github.com/dmora/ch/internal/jsonl"
+)
+
+// LabeledEntry is an entry from a merged transcript, tagged with the ID of
+// the conversation it came from.
+type LabeledEntry struct {
+	Label string // Conversation (agent) ID the entry belongs to
+	Entry *jsonl.RawEntry
+}
+
+// MergeConversations interleaves the entries of several conversations into a
+// single transcript ordered by timestamp. Entries keep their order within each
+// conversation: one without a timestamp sorts with the entry before it (or the
+// conversation's first timestamp, if none precedes it). Ties go to the
+// conversation listed first.
+func MergeConversations(convs []*Conversation) []*LabeledEntry {
+	type stream struct {
+		label   string
+		entries []*jsonl.RawEntry
+		times   []time.Time
+		next    int
+	}
+
+	streams := make([]*stream, 0, len(convs))
+	total := 0
+	for _, c := range convs {
+		if c == nil || len(c.Entries) == 0 {
+			continue
+		}
+		streams = append(streams, &stream{
+			label:   c.Meta.ID,
+			entries: c.Entries,
+			times:   effectiveTimes(c.Entries),
+		})
+		total += len(c.Entries)
+	}
+
+	merged := make([]*LabeledEntry, 0, total)
+	for len(merged) < total {
+		var pick *stream
+		for _, s := range streams {
+			if s.next == len(s.entries) {
+				continue
+			}
+			if pick == nil || s.times[s.next].Before(pick.times[pick.next]) {
+				pick = s
+			}
+		}
+		merged = append(merged, &LabeledEntry{Label: pick.label, Entry: pick.entries[pick.next]})
+		pick.next++
+	}
+	return merged
+}
+
+// effectiveTimes returns the sort time of each entry. Missing or unparseable
+// timestamps carry the previous entry's time forward; leading ones take the
+// first valid timestamp in the conversation.
+func effectiveTimes(entries []*jsonl.RawEntry) []time.Time {
+	times := make([]time.Time, len(entries))
+	var last time.Time
+	firstValid := -1
+	for i, e := range entries {
+		if t, err := time.Parse(time.RFC3339, e.Timestamp); err == nil {
+			last = t
+			if firstValid < 0 {
+				firstValid = i
+			}
+		}
+		times[i] = last
+	}
+	for i := 0; i < firstValid; i++ {
+		times[i] = times[firstValid]
+	}
+	return times
+}
@@ -0,0 +1,67 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/dmora/ch/internal/jsonl"
+)
+
+func TestMergeConversations(t *testing.T) {
+	entry := func(uuid, ts string) *jsonl.RawEntry {
+		return &jsonl.RawEntry{Type: jsonl.EntryTypeUser, UUID: uuid, Timestamp: ts}
+	}
+	a := &Conversation{
+		Meta: ConversationMeta{ID: "a"},
+		Entries: []*jsonl.RawEntry{
+			entry("a1", "2024-01-01T10:00:00Z"),
+			entry("a2", ""), // Sorts with a1
+			entry("a3", "2024-01-01T10:05:00Z"),
+		},
+	}
+	b := &Conversation{
+		Meta: ConversationMeta{ID: "b"},
+		Entries: []*jsonl.RawEntry{
+			entry("b0", ""), // Sorts with b1
+			entry("b1", "2024-01-01T10:02:00Z"),
+			entry("b2", "2024-01-01T10:05:00Z"), // Ties with a3; a is listed first
+		},
+	}
+
+	merged := MergeConversations([]*Conversation{a, nil, b})
+
+	want := []struct{ label, uuid string }{
+		{"a", "a1"}, {"a", "a2"}, {"b", "b0"}, {"b", "b1"}, {"a", "a3"}, {"b", "b2"},
+	}
+	if len(merged) != len(want) {
+		t.Fatalf("MergeConversations() returned %d entries, want %d", len(merged), len(want))
+	}
+	for i, w := range want {
+		if merged[i].Label != w.label || merged[i].Entry.UUID != w.uuid {
+			t.Errorf("merged[%d] = %s/%s, want %s/%s", i, merged[i].Label, merged[i].Entry.UUID, w.label, w.uuid)
+		}
+	}
+}
+
+func TestMergeConversations_NoTimestamps(t *testing.T) {
+	a := &Conversation{
+		Meta:    ConversationMeta{ID: "a"},
+		Entries: []*jsonl.RawEntry{{UUID: "a1"}, {UUID: "a2"}},
+	}
+	b := &Conversation{
+		Meta:    ConversationMeta{ID: "b"},
+		Entries: []*jsonl.RawEntry{{UUID: "b1"}},
+	}
+
+	merged := MergeConversations([]*Conversation{a, b})
+
+	got := ""
+	for _, e := range merged {
+		got += e.Entry.UUID + " "
+	}
+	if got != "a1 a2 b1 " {
+		t.Errorf("merged order = %q, want per-conversation order", got)
+	}
+	if len(MergeConversations(nil)) != 0 {
+		t.Error("MergeConversations(nil) should be empty")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/dmora/ch/internal/jsonl"
 )
 
 // DefaultProjectsDir returns the default Claude projects directory.
@@ -68,14 +70,42 @@ func ProjectDirExists(projectsDir, path string) bool {
 	return err == nil && info.IsDir()
 }
 
+// Conversation file extensions. Compressed files are read transparently.
+const (
+	ConversationExt           = ".jsonl"
+	CompressedConversationExt = ConversationExt + jsonl.CompressedExt
+)
+
 // IsAgentFile returns true if the filename indicates an agent conversation.
 func IsAgentFile(filename string) bool {
-	return strings.HasPrefix(filename, "agent-") && strings.HasSuffix(filename, ".jsonl")
+	return strings.HasPrefix(filename, "agent-") && IsConversationFile(filename)
 }
 
-// IsConversationFile returns true if the filename indicates a conversation file.
+// IsConversationFile returns true if the filename indicates a conversation
+// file, plain or compressed.
 func IsConversationFile(filename string) bool {
-	return strings.HasSuffix(filename, ".jsonl")
+	return strings.HasSuffix(filename, ConversationExt) || strings.HasSuffix(filename, CompressedConversationExt)
+}
+
+// trimConversationExt strips the plain or compressed conversation extension.
+func trimConversationExt(filename string) string {
+	if name, ok := strings.CutSuffix(filename, CompressedConversationExt); ok {
+		return name
+	}
+	return strings.TrimSuffix(filename, ConversationExt)
+}
+
+// ConversationFilePath returns the path of the session's conversation file in
+// projectDir, preferring a plain file and falling back to a compressed one.
+// If neither exists, the plain path is returned.
+func ConversationFilePath(projectDir, sessionID string) string {
+	path := filepath.Join(projectDir, sessionID+ConversationExt)
+	if _, err := os.Stat(path); err != nil {
+		if _, err := os.Stat(path + jsonl.CompressedExt); err == nil {
+			return path + jsonl.CompressedExt
+		}
+	}
+	return path
 }
 
 // ExtractSessionID extracts the session ID from a main conversation filename.
@@ -84,7 +114,7 @@ func ExtractSessionID(filename string) string {
 	if IsAgentFile(filename) {
 		return ""
 	}
-	return strings.TrimSuffix(filename, ".jsonl")
+	return trimConversationExt(filename)
 }
 
 // ExtractAgentID extracts the agent ID from an agent conversation filename.
@@ -94,7 +124,7 @@ func ExtractAgentID(filename string) string {
 		return ""
 	}
 	name := strings.TrimPrefix(filename, "agent-")
-	return strings.TrimSuffix(name, ".jsonl")
+	return trimConversationExt(name)
 }
 
 // ShortID returns a shortened version of a UUID for display.
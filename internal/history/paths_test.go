@@ -81,6 +81,7 @@ func TestIsAgentFile(t *testing.T) {
 		{"no extension", "agent-abc123", false},
 		{"wrong prefix", "conversation-abc123.jsonl", false},
 		{"just agent prefix", "agent-.jsonl", true},
+		{"compressed agent file", "agent-abc123.jsonl.gz", true},
 	}
 
 	for _, tt := range tests {
@@ -100,7 +101,9 @@ func TestIsConversationFile(t *testing.T) {
 	}{
 		{"jsonl file", "abc123.jsonl", true},
 		{"agent file", "agent-abc123.jsonl", true},
+		{"compressed file", "abc123.jsonl.gz", true},
 		{"json file", "abc123.json", false},
+		{"gzip of other file", "abc123.json.gz", false},
 		{"txt file", "abc123.txt", false},
 		{"no extension", "abc123", false},
 	}
@@ -123,6 +126,7 @@ func TestExtractSessionID(t *testing.T) {
 		{"uuid file", "abc123-def456.jsonl", "abc123-def456"},
 		{"simple id", "abc123.jsonl", "abc123"},
 		{"agent file returns empty", "agent-abc123.jsonl", ""},
+		{"compressed file", "abc123.jsonl.gz", "abc123"},
 	}
 
 	for _, tt := range tests {
@@ -143,6 +147,7 @@ func TestExtractAgentID(t *testing.T) {
 		{"agent file", "agent-abc123.jsonl", "abc123"},
 		{"main file returns empty", "abc123.jsonl", ""},
 		{"complex agent id", "agent-abc123-def456.jsonl", "abc123-def456"},
+		{"compressed agent file", "agent-abc123.jsonl.gz", "abc123"},
 	}
 
 	for _, tt := range tests {
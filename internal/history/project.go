@@ -1,11 +1,15 @@
 package history
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/dmora/ch/internal/parallel"
 )
 
 // Project represents a Claude Code project.
@@ -19,7 +23,14 @@ type Project struct {
 }
 
 // ListProjects lists all Claude Code projects.
+// Project directories are read in parallel.
 func ListProjects(projectsDir string) ([]*Project, error) {
+	return ListProjectsWithWorkers(projectsDir, 0)
+}
+
+// ListProjectsWithWorkers lists projects, reading up to workers project
+// directories at once (0 = number of CPUs).
+func ListProjectsWithWorkers(projectsDir string, workers int) ([]*Project, error) {
 	if projectsDir == "" {
 		projectsDir = DefaultProjectsDir()
 	}
@@ -32,50 +43,95 @@ func ListProjects(projectsDir string) ([]*Project, error) {
 		return nil, err
 	}
 
-	var projects []*Project
+	var dirs []string
 	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(projectsDir, entry.Name()))
 		}
+	}
+
+	// Empty and unreadable projects are excluded
+	projects := parallel.ProcessFiles(dirs, workers, func(dir string) (*Project, bool) {
+		project := scanProjectDir(dir)
+		return project, project != nil && (project.ConversationCount > 0 || project.AgentCount > 0)
+	})
 
-		projectDir := filepath.Join(projectsDir, entry.Name())
-		project := &Project{
-			Name: entry.Name(),
-			Path: DecodeProjectPath(entry.Name()),
-			Dir:  projectDir,
+	// Sort by path; the name breaks ties between paths that decode alike
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Path != projects[j].Path {
+			return projects[i].Path < projects[j].Path
 		}
+		return projects[i].Name < projects[j].Name
+	})
+
+	return projects, nil
+}
+
+// scanProjectDir counts the conversation files in a project directory and
+// their total size. Returns nil if the directory can't be read.
+func scanProjectDir(dir string) *Project {
+	name := filepath.Base(dir)
+	project := &Project{
+		Name: name,
+		Path: DecodeProjectPath(name),
+		Dir:  dir,
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
 
-		// Count files and calculate size
-		files, err := os.ReadDir(projectDir)
-		if err != nil {
+	for _, f := range files {
+		if f.IsDir() || !IsConversationFile(f.Name()) {
 			continue
 		}
-
-		for _, f := range files {
-			if f.IsDir() || !IsConversationFile(f.Name()) {
-				continue
-			}
-			if IsAgentFile(f.Name()) {
-				project.AgentCount++
-			} else {
-				project.ConversationCount++
-			}
-			if info, err := f.Info(); err == nil {
-				project.TotalSize += info.Size()
-			}
+		if IsAgentFile(f.Name()) {
+			project.AgentCount++
+		} else {
+			project.ConversationCount++
 		}
+		if info, err := f.Info(); err == nil {
+			project.TotalSize += info.Size()
+		}
+	}
+	return project
+}
 
-		if project.ConversationCount > 0 || project.AgentCount > 0 {
-			projects = append(projects, project)
+// NewestByProject returns the latest conversation activity in each project,
+// keyed by the encoded project directory name (Project.Name). Activity is
+// measured at the end of each conversation, so a long session that started
+// before a cutoff still counts as recent.
+func NewestByProject(conversations []*ConversationMeta) map[string]time.Time {
+	newest := make(map[string]time.Time)
+	for _, c := range conversations {
+		if end, t := c.EndTime(), newest[c.Project]; t.IsZero() || end.After(t) {
+			newest[c.Project] = end
 		}
 	}
+	return newest
+}
 
-	// Sort by path
-	sort.Slice(projects, func(i, j int) bool {
-		return projects[i].Path < projects[j].Path
-	})
+// FilterActive keeps the projects with a conversation that ended after
+// cutoff, along with their conversations.
+func FilterActive(projects []*Project, conversations []*ConversationMeta, cutoff time.Time) ([]*Project, []*ConversationMeta) {
+	newest := NewestByProject(conversations)
+	active := make(map[string]bool)
+	var keptProjects []*Project
+	for _, p := range projects {
+		if newest[p.Name].After(cutoff) {
+			active[p.Name] = true
+			keptProjects = append(keptProjects, p)
+		}
+	}
 
-	return projects, nil
+	var keptConversations []*ConversationMeta
+	for _, c := range conversations {
+		if active[c.Project] {
+			keptConversations = append(keptConversations, c)
+		}
+	}
+	return keptProjects, keptConversations
 }
 
 // FindProject finds a project by its path.
@@ -110,8 +166,8 @@ type ProjectStats struct {
 	AgentCount        int
 	MessageCount      int
 	TotalSize         int64
-	OldestTimestamp   string
-	NewestTimestamp   string
+	OldestTimestamp   time.Time // Start of the oldest conversation, zero if none
+	NewestTimestamp   time.Time // Start of the newest conversation, zero if none
 }
 
 // ResolveProjectPath resolves a project path or name to a full path.
@@ -182,18 +238,21 @@ func GetProjectStats(project *Project) (*ProjectStats, error) {
 		IncludeAgents: true,
 	})
 
-	metas, err := scanner.ScanAll()
+	metas, err := scanner.ScanAll(context.Background())
 	if err != nil {
 		return stats, nil
 	}
 
 	for _, meta := range metas {
 		stats.MessageCount += meta.MessageCount
-		if stats.OldestTimestamp == "" || meta.Timestamp.Format("2006-01-02") < stats.OldestTimestamp {
-			stats.OldestTimestamp = meta.Timestamp.Format("2006-01-02")
+		if meta.Timestamp.IsZero() {
+			continue
+		}
+		if stats.OldestTimestamp.IsZero() || meta.Timestamp.Before(stats.OldestTimestamp) {
+			stats.OldestTimestamp = meta.Timestamp
 		}
-		if stats.NewestTimestamp == "" || meta.Timestamp.Format("2006-01-02") > stats.NewestTimestamp {
-			stats.NewestTimestamp = meta.Timestamp.Format("2006-01-02")
+		if stats.NewestTimestamp.IsZero() || meta.Timestamp.After(stats.NewestTimestamp) {
+			stats.NewestTimestamp = meta.Timestamp
 		}
 	}
 
@@ -1,9 +1,12 @@
 package history
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestListProjects(t *testing.T) {
@@ -58,6 +61,17 @@ func TestListProjects(t *testing.T) {
 	if !foundProject1 {
 		t.Error("project1 not found in results")
 	}
+
+	// A single worker lists the same projects in the same order
+	serial, err := ListProjectsWithWorkers(tmpDir, 1)
+	if err != nil || len(serial) != len(projects) {
+		t.Fatalf("ListProjectsWithWorkers(1) = %d projects, %v; want %d", len(serial), err, len(projects))
+	}
+	for i := range serial {
+		if *serial[i] != *projects[i] {
+			t.Errorf("ListProjectsWithWorkers(1)[%d] = %+v, want %+v", i, serial[i], projects[i])
+		}
+	}
 }
 
 func TestListProjects_EmptyDir(t *testing.T) {
@@ -179,6 +193,61 @@ func TestGetProjectStats(t *testing.T) {
 	}
 }
 
+func TestNewestByProject(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	newest := NewestByProject([]*ConversationMeta{
+		{Project: "-a", Timestamp: day(1)},
+		{Project: "-a", Timestamp: day(5)},
+		{Project: "-a", Timestamp: day(3)},
+		{Project: "-b", Timestamp: day(2)},
+		{Project: "-c", Timestamp: day(1), Duration: 6 * 24 * time.Hour},
+		{Project: "-c", Timestamp: day(4)},
+	})
+
+	if len(newest) != 3 {
+		t.Fatalf("NewestByProject() returned %d projects, want 3", len(newest))
+	}
+	if !newest["-a"].Equal(day(5)) {
+		t.Errorf("newest[-a] = %v, want %v", newest["-a"], day(5))
+	}
+	if !newest["-b"].Equal(day(2)) {
+		t.Errorf("newest[-b] = %v, want %v", newest["-b"], day(2))
+	}
+	// A conversation counts by when it ended, not when it started
+	if !newest["-c"].Equal(day(7)) {
+		t.Errorf("newest[-c] = %v, want %v", newest["-c"], day(7))
+	}
+}
+
+func TestFilterActive(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	projects := []*Project{{Name: "-a"}, {Name: "-b"}, {Name: "-c"}, {Name: "-empty"}}
+	conversations := []*ConversationMeta{
+		{ID: "a1", Project: "-a", Timestamp: day(1)},
+		{ID: "a2", Project: "-a", Timestamp: day(5)},
+		{ID: "b1", Project: "-b", Timestamp: day(2)},
+		{ID: "c1", Project: "-c", Timestamp: day(1), Duration: 6 * 24 * time.Hour},
+	}
+
+	keptProjects, keptConversations := FilterActive(projects, conversations, day(4))
+
+	var names, ids []string
+	for _, p := range keptProjects {
+		names = append(names, p.Name)
+	}
+	for _, c := range keptConversations {
+		ids = append(ids, c.ID)
+	}
+	// -a and -c (by its end) were active after the cutoff; the old
+	// conversation of an active project is kept with it
+	if want := []string{"-a", "-c"}; !slices.Equal(names, want) {
+		t.Errorf("projects = %v, want %v", names, want)
+	}
+	if want := []string{"a1", "a2", "c1"}; !slices.Equal(ids, want) {
+		t.Errorf("conversations = %v, want %v", ids, want)
+	}
+}
+
 func TestListProjects_DefaultDir(t *testing.T) {
 	// Test with empty string (should use default)
 	projects, err := ListProjects("")
@@ -188,3 +257,36 @@ func TestListProjects_DefaultDir(t *testing.T) {
 	}
 	_ = projects // Don't fail even if projects is empty
 }
+
+func BenchmarkListProjects(b *testing.B) {
+	tmpDir := b.TempDir()
+	for i := 0; i < 200; i++ {
+		projectDir := filepath.Join(tmpDir, fmt.Sprintf("-project-%03d", i))
+		if err := os.MkdirAll(projectDir, 0755); err != nil {
+			b.Fatalf("Failed to create project dir: %v", err)
+		}
+		for j := 0; j < 20; j++ {
+			name := fmt.Sprintf("conv-%02d.jsonl", j)
+			if err := os.WriteFile(filepath.Join(projectDir, name), []byte("{}\n"), 0644); err != nil {
+				b.Fatalf("Failed to write file: %v", err)
+			}
+		}
+	}
+
+	for _, bm := range []struct {
+		name    string
+		workers int
+	}{
+		{"serial", 1},
+		{"parallel", 0},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				projects, err := ListProjectsWithWorkers(tmpDir, bm.workers)
+				if err != nil || len(projects) != 200 {
+					b.Fatalf("ListProjectsWithWorkers() = %d projects, %v", len(projects), err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AmbiguousIDError is returned when an ID prefix matches more than one
+// conversation.
+type AmbiguousIDError struct {
+	ID      string
+	Matches []string // IDs of the matching conversations, agents prefixed with "agent-"
+}
+
+func (e *AmbiguousIDError) Error() string {
+	return fmt.Sprintf("ambiguous conversation ID %q matches %d conversations: %s",
+		e.ID, len(e.Matches), strings.Join(e.Matches, ", "))
+}
+
+// ResolveConversation finds the conversation with the given ID across all
+// projects and returns its metadata. The ID may be complete or a prefix; an
+// exact match wins over prefix matches. IDs with an "agent-" prefix only match
+// agents, including sidechain agents whose files aren't named agent-*. Other
+// IDs match main conversations, falling back to agents when no main
+// conversation matches, so a bare agent ID resolves too.
+//
+// Returns an error wrapping ErrNotFound if nothing matches, or an
+// *AmbiguousIDError if the best matches aren't unique.
+func ResolveConversation(projectsDir, id string) (*ConversationMeta, error) {
+	return resolveConversation(projectsDir, id, 0)
+}
+
+// resolveConversation is ResolveConversation listing projects with up to
+// workers goroutines (0 = number of CPUs).
+func resolveConversation(projectsDir, id string, workers int) (*ConversationMeta, error) {
+	if id == "" || id == "agent-" {
+		return nil, fmt.Errorf("%w: empty ID", ErrNotFound)
+	}
+	agentOnly := strings.HasPrefix(id, "agent-")
+	bare := strings.TrimPrefix(id, "agent-")
+
+	projects, err := ListProjectsWithWorkers(projectsDir, workers)
+	if err != nil {
+		return nil, fmt.Errorf("listing projects: %w", err)
+	}
+
+	// Candidates by tier, best first
+	var mainExact, mainPrefix, agentExact, agentPrefix []string
+	for _, project := range projects {
+		entries, err := os.ReadDir(project.Dir)
+		if err != nil {
+			continue
+		}
+		names := make(map[string]bool, len(entries))
+		for _, entry := range entries {
+			names[entry.Name()] = true
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !IsConversationFile(name) {
+				continue
+			}
+			// A session with both files is one conversation; like
+			// ConversationFilePath, use the plain file
+			if plain, ok := strings.CutSuffix(name, CompressedConversationExt); ok && names[plain+ConversationExt] {
+				continue
+			}
+			path := filepath.Join(project.Dir, name)
+
+			if IsAgentFile(name) {
+				switch agentID := ExtractAgentID(name); {
+				case agentID == bare:
+					agentExact = append(agentExact, path)
+				case strings.HasPrefix(agentID, bare):
+					agentPrefix = append(agentPrefix, path)
+				}
+				continue
+			}
+			sessionID := ExtractSessionID(name)
+			if agentOnly {
+				// Sidechain agents keep their session-style filename
+				if strings.HasPrefix(sessionID, bare) && isSidechainFile(path) {
+					if sessionID == bare {
+						agentExact = append(agentExact, path)
+					} else {
+						agentPrefix = append(agentPrefix, path)
+					}
+				}
+				continue
+			}
+			switch {
+			case sessionID == bare:
+				mainExact = append(mainExact, path)
+			case strings.HasPrefix(sessionID, bare):
+				mainPrefix = append(mainPrefix, path)
+			}
+		}
+	}
+
+	for _, tier := range [][]string{mainExact, mainPrefix, agentExact, agentPrefix} {
+		switch len(tier) {
+		case 0:
+			continue
+		case 1:
+			return ScanConversationMeta(tier[0])
+		default:
+			return nil, &AmbiguousIDError{ID: id, Matches: matchIDs(tier)}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// matchIDs returns the conversation IDs of the given files, in the form
+// accepted by ResolveConversation.
+func matchIDs(paths []string) []string {
+	ids := make([]string, len(paths))
+	for i, path := range paths {
+		name := filepath.Base(path)
+		switch {
+		case IsAgentFile(name):
+			ids[i] = "agent-" + ExtractAgentID(name)
+		case isSidechainFile(path):
+			ids[i] = "agent-" + ExtractSessionID(name)
+		default:
+			ids[i] = ExtractSessionID(name)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,116 @@
+package history
+
+import (
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveConversation(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectA := filepath.Join(tmpDir, "-project-a")
+	projectB := filepath.Join(tmpDir, "-project-b")
+	for _, dir := range []string{projectA, projectB} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create project dir: %v", err)
+		}
+	}
+
+	files := map[string]string{
+		filepath.Join(projectA, "abc12345-1111.jsonl"): `{"type":"user","sessionId":"abc12345-1111","message":{"role":"user","content":"a"}}`,
+		filepath.Join(projectB, "abc12399-2222.jsonl"): `{"type":"user","sessionId":"abc12399-2222","message":{"role":"user","content":"b"}}`,
+		filepath.Join(projectA, "abc.jsonl"):           `{"type":"user","sessionId":"abc","message":{"role":"user","content":"c"}}`,
+		filepath.Join(projectA, "agent-fed987.jsonl"):  `{"type":"user","sessionId":"abc12345-1111","isSidechain":true,"message":{"role":"user","content":"d"}}`,
+		filepath.Join(projectA, "agent-abc777.jsonl"):  `{"type":"user","sessionId":"abc12345-1111","isSidechain":true,"message":{"role":"user","content":"e"}}`,
+		filepath.Join(projectB, "5ide0000-3333.jsonl"): `{"type":"user","sessionId":"abc12399-2222","isSidechain":true,"message":{"role":"user","content":"f"}}`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	// Sessions with both a plain and a compressed file
+	for path, content := range map[string]string{
+		filepath.Join(projectA, "dup00000-4444.jsonl"): `{"type":"user","sessionId":"dup00000-4444","message":{"role":"user","content":"g"}}`,
+		filepath.Join(projectA, "agent-zip555.jsonl"):  `{"type":"user","sessionId":"abc12345-1111","isSidechain":true,"message":{"role":"user","content":"h"}}`,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		f, err := os.Create(path + ".gz")
+		if err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		zw := gzip.NewWriter(f)
+		zw.Write([]byte(content))
+		zw.Close()
+		f.Close()
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		wantID  string
+		agent   bool
+		wantErr error
+	}{
+		{"full session ID", "abc12345-1111", "abc12345-1111", false, nil},
+		{"unique prefix", "abc12345", "abc12345-1111", false, nil},
+		{"prefix in another project", "abc1239", "abc12399-2222", false, nil},
+		{"exact match beats prefix", "abc", "abc", false, nil},
+		{"agent ID with prefix", "agent-fed987", "fed987", true, nil},
+		{"agent ID prefix", "agent-fed", "fed987", true, nil},
+		{"bare agent ID falls back to agents", "fed9", "fed987", true, nil},
+		{"agent prefix only matches agents", "agent-abc12345", "", false, ErrNotFound},
+		{"sidechain agent with prefix", "agent-5ide0000-3333", "5ide0000-3333", true, nil},
+		{"sidechain agent prefix", "agent-5ide", "5ide0000-3333", true, nil},
+		{"plain and compressed session", "dup00000-4444", "dup00000-4444", false, nil},
+		{"plain and compressed session prefix", "dup", "dup00000-4444", false, nil},
+		{"plain and compressed agent", "agent-zip555", "zip555", true, nil},
+		{"plain and compressed agent prefix", "zip", "zip555", true, nil},
+		{"no match", "zzz", "", false, ErrNotFound},
+		{"empty ID", "", "", false, ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := ResolveConversation(tmpDir, tt.id)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ResolveConversation(%q) error = %v, want %v", tt.id, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveConversation(%q) error = %v", tt.id, err)
+			}
+			if meta.ID != tt.wantID || meta.IsAgent != tt.agent {
+				t.Errorf("ResolveConversation(%q) = %s (agent %v), want %s (agent %v)", tt.id, meta.ID, meta.IsAgent, tt.wantID, tt.agent)
+			}
+			if filepath.Ext(meta.Path) != ConversationExt {
+				t.Errorf("ResolveConversation(%q) path = %s, want the plain file", tt.id, meta.Path)
+			}
+		})
+	}
+
+	t.Run("ambiguous prefix", func(t *testing.T) {
+		_, err := ResolveConversation(tmpDir, "abc123")
+		var ambiguous *AmbiguousIDError
+		if !errors.As(err, &ambiguous) {
+			t.Fatalf("ResolveConversation(abc123) error = %v, want *AmbiguousIDError", err)
+		}
+		if len(ambiguous.Matches) != 2 {
+			t.Errorf("Matches = %v, want 2 conversations", ambiguous.Matches)
+		}
+	})
+
+	t.Run("bare agent- prefix", func(t *testing.T) {
+		_, err := ResolveConversation(tmpDir, "agent-")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("ResolveConversation(agent-) error = %v, want ErrNotFound", err)
+		}
+	})
+}
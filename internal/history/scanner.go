@@ -1,38 +1,58 @@
 package history
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/dmora/ch/internal/jsonl"
+	"github.com/dmora/ch/internal/parallel"
 )
 
 // ScannerOptions configures the conversation scanner.
 type ScannerOptions struct {
-	ProjectsDir    string // Base projects directory (default: ~/.claude/projects)
-	ProjectPath    string // Filter to specific project path (empty = all)
-	IncludeAgents  bool   // Include agent conversations
-	Limit          int    // Maximum number of results (0 = no limit)
-	Workers        int    // Number of parallel workers (default: 4)
-	SortByTime     bool   // Sort by timestamp (newest first)
+	ProjectsDir     string                // Base projects directory (default: ~/.claude/projects)
+	ProjectPath     string                // Filter to specific project path (empty = all)
+	IncludeAgents   bool                  // Include agent conversations
+	OnlyAgents      bool                  // Only include agent conversations (requires IncludeAgents)
+	Limit           int                   // Maximum number of results (0 = no limit)
+	Workers         int                   // Number of parallel workers (default: number of CPUs)
+	SortByTime      bool                  // Sort by timestamp (newest first)
+	Model           string                // Filter by model (case-insensitive substring, empty = all)
+	AgentType       string                // Only include agents of this subagent_type (case-insensitive, empty = all)
+	Branch          string                // Filter by git branch (exact match, empty = all)
+	LimitPerProject int                   // Keep at most N newest conversations per project (0 = no limit)
+	MinMessages     int                   // Only include conversations with at least N messages (0 = no minimum)
+	MaxMessages     int                   // Only include conversations with at most N messages (0 = no maximum)
+	PreviewLen      int                   // Maximum preview length in characters (default: DefaultPreviewLen)
+	MaxFileSize     int64                 // Skip files larger than this many bytes (0 = no limit)
+	Progress        parallel.ProgressFunc // Called as each file is scanned (nil = no reporting)
 }
 
+// ErrCanceled is returned alongside partial results when a scan or search
+// is stopped by context cancellation.
+var ErrCanceled = errors.New("canceled")
+
 // DefaultScannerOptions returns default scanner options.
 func DefaultScannerOptions() ScannerOptions {
 	return ScannerOptions{
 		ProjectsDir: DefaultProjectsDir(),
-		Workers:     4,
+		Workers:     parallel.DefaultWorkers(),
 		SortByTime:  true,
 	}
 }
 
 // Scanner scans conversation files efficiently.
 type Scanner struct {
-	opts ScannerOptions
+	opts       ScannerOptions
+	skipped    int                // Files skipped by MaxFileSize in the last scan
+	agentTypes *agentTypeResolver // Caches parent lookups for the AgentType filter
 }
 
 // NewScanner creates a new conversation scanner.
@@ -41,24 +61,34 @@ func NewScanner(opts ScannerOptions) *Scanner {
 		opts.ProjectsDir = DefaultProjectsDir()
 	}
 	if opts.Workers <= 0 {
-		opts.Workers = 4
+		opts.Workers = parallel.DefaultWorkers()
+	}
+	if opts.PreviewLen <= 0 {
+		opts.PreviewLen = DefaultPreviewLen
 	}
-	return &Scanner{opts: opts}
+	return &Scanner{opts: opts, agentTypes: newAgentTypeResolver()}
 }
 
 // ScanAll scans all conversations matching the options.
-func (s *Scanner) ScanAll() ([]*ConversationMeta, error) {
+// If ctx is canceled, the conversations scanned so far are returned with ErrCanceled.
+func (s *Scanner) ScanAll(ctx context.Context) ([]*ConversationMeta, error) {
 	files, err := s.findFiles()
 	if err != nil {
 		return nil, err
 	}
 
-	results := s.scanFiles(files)
+	results := s.scanFiles(ctx, files)
+
+	results = s.filter(results)
+
+	if s.opts.LimitPerProject > 0 {
+		results = limitPerProject(results, s.opts.LimitPerProject)
+	}
 
 	// Sort by timestamp if requested
 	if s.opts.SortByTime {
 		sort.Slice(results, func(i, j int) bool {
-			return results[i].Timestamp.After(results[j].Timestamp)
+			return newerFirst(results[i], results[j])
 		})
 	}
 
@@ -67,18 +97,158 @@ func (s *Scanner) ScanAll() ([]*ConversationMeta, error) {
 		results = results[:s.opts.Limit]
 	}
 
+	if ctx.Err() != nil {
+		return results, ErrCanceled
+	}
 	return results, nil
 }
 
+// Walk scans conversations matching the options and calls fn with each one
+// as soon as it is scanned, without holding the full result set in memory.
+// fn is never called concurrently. Conversations arrive in no particular
+// order, so SortByTime and LimitPerProject don't apply; Limit stops the walk
+// after that many conversations.
+//
+// If fn returns an error, the walk stops and Walk returns that error.
+// If ctx is canceled, Walk returns ErrCanceled.
+func (s *Scanner) Walk(ctx context.Context, fn func(*ConversationMeta) error) error {
+	files, err := s.findFiles()
+	if err != nil {
+		return err
+	}
+
+	walkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	visited := 0
+	for meta := range s.streamFiles(walkCtx, files) {
+		if !s.keep(meta) {
+			continue
+		}
+		if err := fn(meta); err != nil {
+			return err
+		}
+		visited++
+		if s.opts.Limit > 0 && visited >= s.opts.Limit {
+			return nil
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ErrCanceled
+	}
+	return nil
+}
+
+// filter drops the conversations that fail the per-conversation filters.
+func (s *Scanner) filter(metas []*ConversationMeta) []*ConversationMeta {
+	filtered := make([]*ConversationMeta, 0, len(metas))
+	for _, m := range metas {
+		if s.keep(m) {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+// keep reports whether a scanned conversation passes the agent, model,
+// message count, and agent type filters. Agents not named agent-* are only
+// detected while scanning, so they are excluded here rather than by filename.
+func (s *Scanner) keep(m *ConversationMeta) bool {
+	if !s.opts.IncludeAgents && m.IsAgent {
+		return false
+	}
+	if s.opts.OnlyAgents && !m.IsAgent {
+		return false
+	}
+	// Conversations with no recorded model never match a model filter
+	if s.opts.Model != "" && (m.Model == "" || !strings.Contains(strings.ToLower(m.Model), strings.ToLower(s.opts.Model))) {
+		return false
+	}
+	if s.opts.Branch != "" && m.Branch != s.opts.Branch {
+		return false
+	}
+	if s.opts.MinMessages > 0 && m.MessageCount < s.opts.MinMessages {
+		return false
+	}
+	if s.opts.MaxMessages > 0 && m.MessageCount > s.opts.MaxMessages {
+		return false
+	}
+	// Checked last: resolving the type reads the agent's parent conversation
+	if s.opts.AgentType != "" && !s.agentTypes.isOfType(m, s.opts.AgentType) {
+		return false
+	}
+	return true
+}
+
+// timeBucket is the granularity at which conversations are considered to have
+// the same time when ordering them; it matches the resolution of list output.
+const timeBucket = time.Minute
+
+// newerFirst orders conversations newest first. Within the same minute,
+// conversations whose time was recorded in their entries sort ahead of those
+// that only have a file mtime, since an mtime can be bumped by copying or
+// touching the file.
+func newerFirst(a, b *ConversationMeta) bool {
+	ta, tb := a.Timestamp.Truncate(timeBucket), b.Timestamp.Truncate(timeBucket)
+	if !ta.Equal(tb) {
+		return ta.After(tb)
+	}
+	if am, bm := a.HasMtimeTime(), b.HasMtimeTime(); am != bm {
+		return bm
+	}
+	return a.Timestamp.After(b.Timestamp)
+}
+
+// limitPerProject keeps at most n of the newest conversations in each project,
+// preserving the original order of the kept conversations.
+func limitPerProject(metas []*ConversationMeta, n int) []*ConversationMeta {
+	byTime := make([]*ConversationMeta, len(metas))
+	copy(byTime, metas)
+	sort.SliceStable(byTime, func(i, j int) bool {
+		return newerFirst(byTime[i], byTime[j])
+	})
+
+	counts := make(map[string]int)
+	keep := make(map[*ConversationMeta]bool, len(metas))
+	for _, m := range byTime {
+		if counts[m.Project] < n {
+			counts[m.Project]++
+			keep[m] = true
+		}
+	}
+
+	filtered := make([]*ConversationMeta, 0, len(keep))
+	for _, m := range metas {
+		if keep[m] {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
 // ScanProject scans conversations for a specific project path.
-func (s *Scanner) ScanProject(projectPath string) ([]*ConversationMeta, error) {
+func (s *Scanner) ScanProject(ctx context.Context, projectPath string) ([]*ConversationMeta, error) {
 	s.opts.ProjectPath = projectPath
-	return s.ScanAll()
+	return s.ScanAll(ctx)
+}
+
+// SkippedFiles returns the number of files the last scan skipped because
+// they exceeded MaxFileSize.
+func (s *Scanner) SkippedFiles() int {
+	return s.skipped
+}
+
+// Files returns the paths of the conversation files matching the options,
+// without reading them.
+func (s *Scanner) Files() ([]string, error) {
+	return s.findFiles()
 }
 
 // findFiles finds all conversation files matching the options.
 func (s *Scanner) findFiles() ([]string, error) {
 	var files []string
+	s.skipped = 0
 
 	if s.opts.ProjectPath != "" {
 		// Scan specific project
@@ -128,63 +298,80 @@ func (s *Scanner) scanDir(dir string) ([]string, error) {
 		if !s.opts.IncludeAgents && IsAgentFile(entry.Name()) {
 			continue
 		}
+		if s.opts.MaxFileSize > 0 {
+			if info, err := entry.Info(); err == nil && info.Size() > s.opts.MaxFileSize {
+				s.skipped++
+				continue
+			}
+		}
 		files = append(files, filepath.Join(dir, entry.Name()))
 	}
 
 	return files, nil
 }
 
-// scanFiles scans multiple files in parallel.
-func (s *Scanner) scanFiles(files []string) []*ConversationMeta {
-	if len(files) == 0 {
-		return nil
+// scanFiles scans multiple files in parallel, stopping early if ctx is canceled.
+func (s *Scanner) scanFiles(ctx context.Context, files []string) []*ConversationMeta {
+	results := make([]*ConversationMeta, 0, len(files))
+	for meta := range s.streamFiles(ctx, files) {
+		results = append(results, meta)
 	}
+	return results
+}
 
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	results := make([]*ConversationMeta, 0, len(files))
+// streamFiles scans files with the worker pool and sends each parsed meta on
+// the returned channel, which is closed once all workers finish. Workers stop
+// when ctx is canceled, so callers that stop reading must cancel ctx.
+func (s *Scanner) streamFiles(ctx context.Context, files []string) <-chan *ConversationMeta {
+	out := make(chan *ConversationMeta, s.opts.Workers)
 
-	// Create worker pool
 	fileChan := make(chan string, len(files))
 	for _, f := range files {
 		fileChan <- f
 	}
 	close(fileChan)
 
+	progress := parallel.NewTracker(len(files), s.opts.Progress)
+	var wg sync.WaitGroup
 	for i := 0; i < s.opts.Workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			for path := range fileChan {
-				meta, err := ScanConversationMeta(path)
+				if ctx.Err() != nil {
+					return
+				}
+				meta, err := scanConversationMeta(path, s.opts.PreviewLen)
+				progress.Done()
 				if err != nil {
 					continue // Skip files we can't parse
 				}
-				mu.Lock()
-				results = append(results, meta)
-				mu.Unlock()
+				select {
+				case out <- meta:
+				case <-ctx.Done():
+					return
+				}
 			}
 		}()
 	}
 
-	wg.Wait()
-	return results
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
 }
 
 // CountAgents counts the number of agent files for a given session ID.
 func (s *Scanner) CountAgents(projectDir, sessionID string) int {
-	entries, err := os.ReadDir(projectDir)
+	paths, err := agentPaths(projectDir)
 	if err != nil {
 		return 0
 	}
 
 	count := 0
-	for _, entry := range entries {
-		if entry.IsDir() || !IsAgentFile(entry.Name()) {
-			continue
-		}
+	for _, path := range paths {
 		// Check if this agent belongs to the session
-		path := filepath.Join(projectDir, entry.Name())
 		meta, err := ScanConversationMeta(path)
 		if err != nil {
 			continue
@@ -196,19 +383,37 @@ func (s *Scanner) CountAgents(projectDir, sessionID string) int {
 	return count
 }
 
-// FindAgents finds all agent conversations for a given session ID.
-func (s *Scanner) FindAgents(projectDir, sessionID string) ([]*ConversationMeta, error) {
+// agentPaths returns the agent conversation files in projectDir: those named
+// agent-* and those whose entries are on a sidechain.
+func agentPaths(projectDir string) ([]string, error) {
 	entries, err := os.ReadDir(projectDir)
 	if err != nil {
 		return nil, err
 	}
 
-	var agents []*ConversationMeta
+	var paths []string
 	for _, entry := range entries {
-		if entry.IsDir() || !IsAgentFile(entry.Name()) {
+		name := entry.Name()
+		if entry.IsDir() || !IsConversationFile(name) {
 			continue
 		}
-		path := filepath.Join(projectDir, entry.Name())
+		path := filepath.Join(projectDir, name)
+		if IsAgentFile(name) || isSidechainFile(path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// FindAgents finds all agent conversations for a given session ID.
+func (s *Scanner) FindAgents(projectDir, sessionID string) ([]*ConversationMeta, error) {
+	paths, err := agentPaths(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var agents []*ConversationMeta
+	for _, path := range paths {
 		meta, err := ScanConversationMeta(path)
 		if err != nil {
 			continue
@@ -228,31 +433,41 @@ func (s *Scanner) FindAgents(projectDir, sessionID string) ([]*ConversationMeta,
 
 // AgentInfo contains detailed information about an agent extracted from parent conversation.
 type AgentInfo struct {
-	AgentID     string // Agent ID (from agent file)
+	AgentID      string // Agent ID (from agent file)
 	SubagentType string // Type of agent (e.g., "Explore", "Plan")
-	Prompt      string // The prompt passed to the Task tool
-	Description string // Short description from Task tool
+	Prompt       string // The prompt passed to the Task tool
+	Description  string // Short description from Task tool
+	ToolUseID    string // ID of the Task tool_use block that spawned the agent
+	SpawnUUID    string // UUID of the parent entry containing the Task call
+	Match        string // How the Task call was found: AgentMatchID, AgentMatchPrompt, or AgentMatchOrder
 }
 
 // ExtractAgentInfo extracts agent type and prompt from a parent conversation.
-// It finds the Task tool_use block that spawned the given agent.
+// It finds the Task tool_use block that spawned the given agent, by tool ID
+// when possible and otherwise by best effort (see AgentInfo.Match). It
+// returns nil if no Task call can be correlated with the agent.
 func ExtractAgentInfo(parentPath, agentID string) (*AgentInfo, error) {
 	conv, err := LoadConversation(parentPath)
 	if err != nil {
 		return nil, err
 	}
 
-	normalizedID := strings.TrimPrefix(agentID, "agent-")
-	block, input := findTaskToolCall(conv.Entries, normalizedID)
-	if block == nil {
+	call, match := newTaskMatcher(parentPath, conv.Entries).match(agentID)
+	if call == nil {
 		return nil, nil
 	}
 
-	return parseAgentInput(agentID, input), nil
+	info := *call.Input
+	info.AgentID = agentID
+	info.ToolUseID = call.ID
+	info.SpawnUUID = call.SpawnUUID
+	info.Match = match
+	return &info, nil
 }
 
 // findTaskToolCall searches entries for a Task tool call matching the given agent ID.
-func findTaskToolCall(entries []*jsonl.RawEntry, normalizedID string) (*jsonl.ContentBlock, map[string]interface{}) {
+// It returns the entry holding the call, the tool_use block, and its decoded input.
+func findTaskToolCall(entries []*jsonl.RawEntry, normalizedID string) (*jsonl.RawEntry, *jsonl.ContentBlock, map[string]interface{}) {
 	for _, entry := range entries {
 		if entry.Type != jsonl.EntryTypeAssistant || entry.Message == nil {
 			continue
@@ -277,11 +492,11 @@ func findTaskToolCall(entries []*jsonl.RawEntry, normalizedID string) (*jsonl.Co
 
 			var input map[string]interface{}
 			if json.Unmarshal(block.Input, &input) == nil {
-				return block, input
+				return entry, block, input
 			}
 		}
 	}
-	return nil, nil
+	return nil, nil, nil
 }
 
 // parseAgentInput extracts agent info from Task tool input.
@@ -311,7 +526,7 @@ func (s *Scanner) FindAgentsWithType(projectDir, sessionID, agentType string) ([
 	}
 
 	// Find parent conversation path
-	parentPath := filepath.Join(projectDir, sessionID+".jsonl")
+	parentPath := ConversationFilePath(projectDir, sessionID)
 
 	// Filter agents by type
 	var filtered []*ConversationMeta
@@ -335,7 +550,7 @@ func (s *Scanner) GetAgentTypes(projectDir, sessionID string) ([]string, error)
 		return nil, err
 	}
 
-	parentPath := filepath.Join(projectDir, sessionID+".jsonl")
+	parentPath := ConversationFilePath(projectDir, sessionID)
 	typeSet := make(map[string]bool)
 
 	for _, agent := range agents {
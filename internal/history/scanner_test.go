@@ -1,9 +1,16 @@
 package history
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultScannerOptions(t *testing.T) {
@@ -12,8 +19,8 @@ func TestDefaultScannerOptions(t *testing.T) {
 	if opts.ProjectsDir == "" {
 		t.Error("ProjectsDir should not be empty")
 	}
-	if opts.Workers != 4 {
-		t.Errorf("Workers = %d, want 4", opts.Workers)
+	if opts.Workers != runtime.NumCPU() {
+		t.Errorf("Workers = %d, want %d", opts.Workers, runtime.NumCPU())
 	}
 	if !opts.SortByTime {
 		t.Error("SortByTime should be true")
@@ -27,8 +34,13 @@ func TestNewScanner(t *testing.T) {
 	}
 	scanner := NewScanner(opts)
 
-	if scanner.opts.Workers != 4 {
-		t.Errorf("Workers should default to 4, got %d", scanner.opts.Workers)
+	if scanner.opts.Workers != runtime.NumCPU() {
+		t.Errorf("Workers should default to %d, got %d", runtime.NumCPU(), scanner.opts.Workers)
+	}
+
+	scanner = NewScanner(ScannerOptions{ProjectsDir: "/tmp/test", Workers: 7})
+	if scanner.opts.Workers != 7 {
+		t.Errorf("Workers = %d, want 7", scanner.opts.Workers)
 	}
 }
 
@@ -44,7 +56,7 @@ func TestScanner_ScanAll_EmptyDir(t *testing.T) {
 		ProjectsDir: tmpDir,
 	})
 
-	results, err := scanner.ScanAll()
+	results, err := scanner.ScanAll(context.Background())
 	if err != nil {
 		t.Fatalf("ScanAll() error = %v", err)
 	}
@@ -80,7 +92,7 @@ func TestScanner_ScanAll_WithConversations(t *testing.T) {
 		ProjectsDir: tmpDir,
 	})
 
-	results, err := scanner.ScanAll()
+	results, err := scanner.ScanAll(context.Background())
 	if err != nil {
 		t.Fatalf("ScanAll() error = %v", err)
 	}
@@ -119,7 +131,7 @@ func TestScanner_ScanAll_ExcludesAgents(t *testing.T) {
 		IncludeAgents: false,
 	})
 
-	results, _ := scanner.ScanAll()
+	results, _ := scanner.ScanAll(context.Background())
 	if len(results) != 1 {
 		t.Errorf("Without agents: expected 1 result, got %d", len(results))
 	}
@@ -130,12 +142,51 @@ func TestScanner_ScanAll_ExcludesAgents(t *testing.T) {
 		IncludeAgents: true,
 	})
 
-	results, _ = scanner.ScanAll()
+	results, _ = scanner.ScanAll(context.Background())
 	if len(results) != 2 {
 		t.Errorf("With agents: expected 2 results, got %d", len(results))
 	}
 }
 
+func TestScanner_OnlyAgents(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	files := map[string]string{
+		"abc123.jsonl":       `{"type":"user"}`,
+		"agent-def456.jsonl": `{"type":"assistant","isSidechain":true}`,
+		// An agent detected from its entries rather than its filename
+		"side789.jsonl": `{"type":"user","isSidechain":true,"agentId":"side789"}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	scanner := NewScanner(ScannerOptions{
+		ProjectsDir:   tmpDir,
+		IncludeAgents: true,
+		OnlyAgents:    true,
+		Limit:         2,
+	})
+	results, err := scanner.ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("ScanAll() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 agents, got %d", len(results))
+	}
+	for _, r := range results {
+		if !r.IsAgent {
+			t.Errorf("%s is not an agent", r.ID)
+		}
+	}
+}
+
 func TestScanner_Limit(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "ch-test-*")
 	if err != nil {
@@ -161,8 +212,391 @@ func TestScanner_Limit(t *testing.T) {
 		Limit:       2,
 	})
 
-	results, _ := scanner.ScanAll()
+	results, _ := scanner.ScanAll(context.Background())
 	if len(results) != 2 {
 		t.Errorf("Expected 2 results with limit, got %d", len(results))
 	}
 }
+
+func TestScanner_ModelFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	files := map[string]string{
+		"opus.jsonl":   `{"type":"assistant","message":{"role":"assistant","model":"claude-opus-4","content":"Hi"}}`,
+		"sonnet.jsonl": `{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4","content":"Hi"}}`,
+		"none.jsonl":   `{"type":"user","message":{"role":"user","content":"Hello"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	scanner := NewScanner(ScannerOptions{
+		ProjectsDir: tmpDir,
+		Model:       "OPUS",
+	})
+
+	results, err := scanner.ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("ScanAll() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	if results[0].Model != "claude-opus-4" {
+		t.Errorf("Model = %q, want claude-opus-4", results[0].Model)
+	}
+}
+
+func TestScanner_AgentTypeFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	parent := `{"type":"assistant","uuid":"u1","sessionId":"main-123","message":{"role":"assistant","content":[` +
+		`{"type":"tool_use","id":"toolu_aaa111","name":"Task","input":{"subagent_type":"Explore","prompt":"look"}},` +
+		`{"type":"tool_use","id":"toolu_bbb222","name":"Task","input":{"subagent_type":"Plan","prompt":"plan"}}]}}`
+	files := map[string]string{
+		"main-123.jsonl":     parent,
+		"agent-aaa111.jsonl": `{"type":"user","sessionId":"main-123","isSidechain":true,"message":{"role":"user","content":"look"}}`,
+		"agent-bbb222.jsonl": `{"type":"user","sessionId":"main-123","isSidechain":true,"message":{"role":"user","content":"plan"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	scanner := NewScanner(ScannerOptions{
+		ProjectsDir:   tmpDir,
+		IncludeAgents: true,
+		AgentType:     "explore",
+	})
+
+	results, err := scanner.ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("ScanAll() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	if results[0].ID != "aaa111" {
+		t.Errorf("ID = %q, want aaa111", results[0].ID)
+	}
+}
+
+func TestScanner_ScanAll_Canceled(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "abc123.jsonl"), []byte(`{"type":"user"}`), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	scanner := NewScanner(ScannerOptions{ProjectsDir: tmpDir})
+	results, err := scanner.ScanAll(ctx)
+	if !errors.Is(err, ErrCanceled) {
+		t.Fatalf("ScanAll() error = %v, want ErrCanceled", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results after cancellation, got %d", len(results))
+	}
+}
+
+func TestScanner_LimitPerProject(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Busy project with three conversations, quiet project with one
+	files := map[string]string{
+		"-busy/a.jsonl":  `{"type":"user","timestamp":"2024-01-01T10:00:00Z"}`,
+		"-busy/b.jsonl":  `{"type":"user","timestamp":"2024-01-02T10:00:00Z"}`,
+		"-busy/c.jsonl":  `{"type":"user","timestamp":"2024-01-03T10:00:00Z"}`,
+		"-quiet/d.jsonl": `{"type":"user","timestamp":"2023-06-01T10:00:00Z"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create project dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	scanner := NewScanner(ScannerOptions{
+		ProjectsDir:     tmpDir,
+		SortByTime:      true,
+		LimitPerProject: 2,
+		Limit:           3,
+	})
+
+	results, err := scanner.ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("ScanAll() error = %v", err)
+	}
+
+	var ids []string
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	if got := strings.Join(ids, ","); got != "c,b,d" {
+		t.Errorf("IDs = %s, want c,b,d", got)
+	}
+}
+
+func TestScanner_MtimeOrdering(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	recorded := time.Date(2024, 1, 1, 10, 0, 5, 0, time.UTC)
+	files := map[string]struct {
+		content string
+		mtime   time.Time
+	}{
+		// Recorded time, with an mtime from much later
+		"entry": {`{"type":"user","timestamp":"2024-01-01T10:00:05Z"}`, recorded.Add(24 * time.Hour)},
+		// No timestamps; mtime is newer than "entry" but within the same minute
+		"touched": {`{"type":"user"}`, recorded.Add(30 * time.Second)},
+		// No timestamps; mtime a minute later
+		"later": {`{"type":"user"}`, recorded.Add(2 * time.Minute)},
+	}
+	for name, f := range files {
+		path := filepath.Join(projectDir, name+".jsonl")
+		if err := os.WriteFile(path, []byte(f.content+"\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := os.Chtimes(path, f.mtime, f.mtime); err != nil {
+			t.Fatalf("Chtimes: %v", err)
+		}
+	}
+
+	scanner := NewScanner(ScannerOptions{ProjectsDir: tmpDir, SortByTime: true})
+	results, err := scanner.ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("ScanAll() error = %v", err)
+	}
+
+	var ids []string
+	for _, r := range results {
+		ids = append(ids, r.ID+":"+r.TimeSource)
+	}
+	if got, want := strings.Join(ids, ","), "later:mtime,entry:entry,touched:mtime"; got != want {
+		t.Errorf("order = %s, want %s", got, want)
+	}
+}
+
+func TestScanner_Progress(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if err := os.WriteFile(filepath.Join(projectDir, name+".jsonl"), []byte(`{"type":"user"}`+"\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	var last, calls int
+	scanner := NewScanner(ScannerOptions{
+		ProjectsDir: tmpDir,
+		Workers:     4,
+		Progress: func(done, total int) {
+			calls++
+			if done != last+1 || total != 4 {
+				t.Errorf("progress(%d, %d) after %d, want consecutive counts of 4", done, total, last)
+			}
+			last = done
+		},
+	})
+	if _, err := scanner.ScanAll(context.Background()); err != nil {
+		t.Fatalf("ScanAll() error = %v", err)
+	}
+	if calls != 4 || last != 4 {
+		t.Errorf("progress called %d times ending at %d, want 4 ending at 4", calls, last)
+	}
+}
+
+func TestScanner_MessageCountFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	msg := `{"type":"user","message":{"role":"user","content":"Hello"}}` + "\n"
+	for name, count := range map[string]int{"one": 1, "three": 3, "five": 5} {
+		content := strings.Repeat(msg, count)
+		if err := os.WriteFile(filepath.Join(projectDir, name+".jsonl"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		min, max int
+		want     int
+	}{
+		{"unbounded", 0, 0, 3},
+		{"min only", 3, 0, 2},
+		{"max only", 0, 3, 2},
+		{"range", 2, 4, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewScanner(ScannerOptions{
+				ProjectsDir: tmpDir,
+				MinMessages: tt.min,
+				MaxMessages: tt.max,
+			})
+			results, err := scanner.ScanAll(context.Background())
+			if err != nil {
+				t.Fatalf("ScanAll() error = %v", err)
+			}
+			if len(results) != tt.want {
+				t.Errorf("got %d results, want %d", len(results), tt.want)
+			}
+		})
+	}
+}
+
+func TestScanner_SidechainWithoutAgentPrefix(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	files := map[string]string{
+		"main-session.jsonl": `{"type":"user","sessionId":"main-session","message":{"role":"user","content":"Hello"}}`,
+		"sidechain.jsonl":    `{"type":"user","sessionId":"main-session","isSidechain":true,"message":{"role":"user","content":"Explore"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	meta, err := ScanConversationMeta(filepath.Join(projectDir, "sidechain.jsonl"))
+	if err != nil {
+		t.Fatalf("ScanConversationMeta() error = %v", err)
+	}
+	if !meta.IsAgent || meta.AgentReason != AgentReasonSidechain {
+		t.Errorf("IsAgent = %v, AgentReason = %q, want true/%q", meta.IsAgent, meta.AgentReason, AgentReasonSidechain)
+	}
+	if meta.ParentSessionID != "main-session" {
+		t.Errorf("ParentSessionID = %q, want main-session", meta.ParentSessionID)
+	}
+
+	results, err := NewScanner(ScannerOptions{ProjectsDir: tmpDir}).ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("ScanAll() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "main-session" {
+		t.Errorf("Without agents: expected only main-session, got %d results", len(results))
+	}
+
+	results, err = NewScanner(ScannerOptions{ProjectsDir: tmpDir, IncludeAgents: true}).ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("ScanAll() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("With agents: expected 2 results, got %d", len(results))
+	}
+
+	scanner := NewScanner(ScannerOptions{ProjectsDir: tmpDir})
+	if n := scanner.CountAgents(projectDir, "main-session"); n != 1 {
+		t.Errorf("CountAgents() = %d, want 1", n)
+	}
+	agents, err := scanner.FindAgents(projectDir, "main-session")
+	if err != nil {
+		t.Fatalf("FindAgents() error = %v", err)
+	}
+	if len(agents) != 1 || agents[0].ID != "sidechain" {
+		t.Errorf("FindAgents() = %d agents, want only sidechain", len(agents))
+	}
+}
+
+func TestScanner_Walk(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	msg := `{"type":"user","message":{"role":"user","content":"Hello"}}` + "\n"
+	for name, count := range map[string]int{"one": 1, "three": 3, "five": 5, "agent-x": 2} {
+		content := strings.Repeat(msg, count)
+		if err := os.WriteFile(filepath.Join(projectDir, name+".jsonl"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	scanner := NewScanner(ScannerOptions{ProjectsDir: tmpDir, MinMessages: 2})
+	var seen []string
+	err := scanner.Walk(context.Background(), func(m *ConversationMeta) error {
+		seen = append(seen, m.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	sort.Strings(seen)
+	if want := []string{"five", "three"}; !slices.Equal(seen, want) {
+		t.Errorf("Walk() visited %v, want %v", seen, want)
+	}
+
+	// Returning an error stops the walk and is passed through
+	errStop := errors.New("stop")
+	calls := 0
+	err = NewScanner(ScannerOptions{ProjectsDir: tmpDir, Workers: 1}).Walk(context.Background(), func(m *ConversationMeta) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Errorf("Walk() = %v after %d calls, want errStop after 1", err, calls)
+	}
+}
+
+func TestScanner_MaxFileSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	msg := `{"type":"user","message":{"role":"user","content":"Hello"}}` + "\n"
+	for name, count := range map[string]int{"small": 1, "large": 50} {
+		content := strings.Repeat(msg, count)
+		if err := os.WriteFile(filepath.Join(projectDir, name+".jsonl"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	scanner := NewScanner(ScannerOptions{ProjectsDir: tmpDir, MaxFileSize: 1024})
+	results, err := scanner.ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("ScanAll() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "small" {
+		t.Errorf("ScanAll() = %v, want only small", results)
+	}
+	if got := scanner.SkippedFiles(); got != 1 {
+		t.Errorf("SkippedFiles() = %d, want 1", got)
+	}
+}
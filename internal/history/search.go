@@ -2,11 +2,14 @@ package history
 
 import (
 	"bufio"
-	"os"
+	"context"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/dmora/ch/internal/jsonl"
+	"github.com/dmora/ch/internal/parallel"
 )
 
 // SearchResult represents a search match.
@@ -17,31 +20,74 @@ type SearchResult struct {
 	MessageIndices []int    // 1-based indices of messages containing matches
 }
 
+// SearchSummary describes the full result set of a search, independent of any limit.
+type SearchSummary struct {
+	TotalMatched int // Number of conversations that matched before the limit was applied
+	TotalMatches int // Number of matches across all matching conversations
+	FilesScanned int // Number of files searched (fewer than found if canceled)
+	FilesSkipped int // Number of files skipped by MaxFileSize
+	FilesPruned  int // Number of files the search index ruled out without reading them
+}
+
+// SearchIndex narrows down the files a search has to read.
+type SearchIndex interface {
+	// Candidates returns the subset of files that may contain query. Files
+	// the index doesn't cover, or that changed since they were indexed,
+	// must be returned so they are searched directly.
+	Candidates(query string, files []string) ([]string, error)
+}
+
+// DefaultMaxPreviews is the number of preview snippets returned per result by default.
+const DefaultMaxPreviews = 3
+
+// Search result orderings for SearchOptions.SortBy.
+const (
+	SearchSortMatches = "matches" // Most matches first, newest first on ties (default)
+	SearchSortTime    = "time"    // Newest first
+)
+
 // SearchOptions configures the search.
 type SearchOptions struct {
-	ProjectsDir   string // Base projects directory
-	ProjectPath   string // Filter to specific project (empty = all)
-	IncludeAgents bool   // Include agent conversations
-	Limit         int    // Maximum number of results (0 = no limit)
-	CaseSensitive bool   // Case-sensitive search
-	Workers       int    // Number of parallel workers
+	ProjectsDir   string                // Base projects directory
+	ProjectPath   string                // Filter to specific project (empty = all)
+	IncludeAgents bool                  // Include agent conversations
+	Limit         int                   // Maximum number of results (0 = no limit)
+	CaseSensitive bool                  // Case-sensitive search
+	Workers       int                   // Number of parallel workers (default: number of CPUs)
+	CountOnly     bool                  // Only count matches; skip preview extraction
+	MaxPreviews   int                   // Preview snippets per result (0 = none)
+	SortBy        string                // Result order: matches (default) or time
+	MaxFileSize   int64                 // Skip files larger than this many bytes (0 = no limit)
+	AgentType     string                // Only match agents of this subagent_type (case-insensitive, empty = all)
+	Index         SearchIndex           // Optional index used to skip files that can't match
+	Progress      parallel.ProgressFunc // Called as each file is searched (nil = no reporting)
 }
 
 // DefaultSearchOptions returns default search options.
 func DefaultSearchOptions() SearchOptions {
 	return SearchOptions{
 		ProjectsDir: DefaultProjectsDir(),
-		Workers:     4,
+		Workers:     parallel.DefaultWorkers(),
+		MaxPreviews: DefaultMaxPreviews,
 	}
 }
 
 // Search searches for a query across conversations.
-func Search(query string, opts SearchOptions) ([]*SearchResult, error) {
+func Search(ctx context.Context, query string, opts SearchOptions) ([]*SearchResult, error) {
+	results, _, err := SearchWithSummary(ctx, query, opts)
+	return results, err
+}
+
+// SearchWithSummary searches for a query across conversations and also reports
+// how many conversations matched in total. Every file is searched even when a
+// limit is set, so the summary reflects the full match count.
+// If ctx is canceled, the matches found so far are returned with ErrCanceled.
+func SearchWithSummary(ctx context.Context, query string, opts SearchOptions) ([]*SearchResult, *SearchSummary, error) {
 	if opts.ProjectsDir == "" {
 		opts.ProjectsDir = DefaultProjectsDir()
 	}
 	if opts.Workers <= 0 {
-		opts.Workers = 4
+		opts.Workers = parallel.DefaultWorkers()
 	}
 
 	// Prepare query for case-insensitive search
@@ -55,11 +101,21 @@ func Search(query string, opts SearchOptions) ([]*SearchResult, error) {
 		ProjectsDir:   opts.ProjectsDir,
 		ProjectPath:   opts.ProjectPath,
 		IncludeAgents: opts.IncludeAgents,
+		MaxFileSize:   opts.MaxFileSize,
 	})
 
 	files, err := scanner.findFiles()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	summary := &SearchSummary{FilesSkipped: scanner.SkippedFiles()}
+	candidates := narrowFiles(opts.Index, query, files)
+	summary.FilesPruned = len(files) - len(candidates)
+	files = filterSearchFiles(candidates, opts)
+
+	maxPreviews := opts.MaxPreviews
+	if opts.CountOnly {
+		maxPreviews = 0
 	}
 
 	// Search files in parallel
@@ -73,91 +129,152 @@ func Search(query string, opts SearchOptions) ([]*SearchResult, error) {
 	}
 	close(fileChan)
 
+	progress := parallel.NewTracker(len(files), opts.Progress)
 	for i := 0; i < opts.Workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			for path := range fileChan {
-				result := searchFile(path, searchQuery, opts.CaseSensitive)
+				if ctx.Err() != nil {
+					return
+				}
+				result := searchFile(path, searchQuery, opts.CaseSensitive, maxPreviews)
+				mu.Lock()
+				summary.FilesScanned++
 				if result != nil {
-					mu.Lock()
-					// Check limit
-					if opts.Limit > 0 && len(results) >= opts.Limit {
-						mu.Unlock()
-						return
-					}
 					results = append(results, result)
-					mu.Unlock()
+					summary.TotalMatches += result.MatchCount
 				}
+				mu.Unlock()
+				progress.Done()
 			}
 		}()
 	}
 
 	wg.Wait()
 
+	summary.TotalMatched = len(results)
+
+	sortSearchResults(results, opts.SortBy)
+
 	// Apply limit
 	if opts.Limit > 0 && len(results) > opts.Limit {
 		results = results[:opts.Limit]
 	}
 
-	return results, nil
+	if ctx.Err() != nil {
+		return results, summary, ErrCanceled
+	}
+	return results, summary, nil
 }
 
-// searchFile searches a single file for the query in message content.
-func searchFile(path string, query string, caseSensitive bool) *SearchResult {
-	file, err := os.Open(path)
+// filterSearchFiles drops the files that can't match opts before any are
+// searched. With an agent type, main conversations are dropped by filename or
+// first entry, and only agents of that type are kept. Each parent
+// conversation is loaded once for the whole search.
+func filterSearchFiles(files []string, opts SearchOptions) []string {
+	if opts.AgentType == "" {
+		return files
+	}
+	resolver := newAgentTypeResolver()
+	return parallel.ProcessFiles(files, opts.Workers, func(path string) (string, bool) {
+		if !IsAgentFile(filepath.Base(path)) && !isSidechainFile(path) {
+			return "", false
+		}
+		meta, err := ScanConversationMeta(path)
+		if err != nil {
+			return "", false
+		}
+		return path, resolver.isOfType(meta, opts.AgentType)
+	})
+}
+
+// narrowFiles returns the files that may contain query according to the
+// index. Without an index, or if the index fails, every file is searched.
+func narrowFiles(index SearchIndex, query string, files []string) []string {
+	if index == nil {
+		return files
+	}
+	candidates, err := index.Candidates(query, files)
 	if err != nil {
-		return nil
+		return files
 	}
-	defer file.Close()
+	return candidates
+}
 
-	var matchCount int
-	var previews []string
-	var messageIndices []int
-	const maxPreviews = 3
-	const previewLen = 150
+// sortSearchResults orders results by sortBy. Ties fall back to newest first,
+// then ID, so the order is deterministic regardless of worker scheduling.
+func sortSearchResults(results []*SearchResult, sortBy string) {
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if sortBy != SearchSortTime && a.MatchCount != b.MatchCount {
+			return a.MatchCount > b.MatchCount
+		}
+		if !a.Meta.Timestamp.Equal(b.Meta.Timestamp) {
+			return a.Meta.Timestamp.After(b.Meta.Timestamp)
+		}
+		return a.Meta.ID < b.Meta.ID
+	})
+}
 
-	msgIndex := 0 // Track message index (1-based)
+// ForEachMessageText calls fn with the 1-based index and text of each message
+// in the conversation at path, in order, until fn returns false. Messages
+// without text are counted but not passed to fn. This is the text search
+// matches against, so everything that searches or indexes conversations must
+// read it through here. It returns the number of messages seen.
+func ForEachMessageText(path string, fn func(index int, text string) bool) (int, error) {
+	file, err := jsonl.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
 
+	msgIndex := 0
 	scanner := bufio.NewScanner(file)
 	scanner.Buffer(make([]byte, 64*1024), jsonl.MaxScannerBuffer)
 
 	for scanner.Scan() {
-		line := scanner.Bytes()
-
 		// Parse entry to check if it's a message
-		entry, err := jsonl.ParseEntry(line)
+		entry, err := jsonl.ParseEntry(scanner.Bytes())
 		if err != nil || !entry.Type.IsMessage() {
 			continue
 		}
+		msgIndex++
 
-		msgIndex++ // Increment for each message entry
-
-		// Parse message and search in content
 		msg, err := jsonl.ParseMessage(entry)
 		if err != nil || msg == nil {
 			continue
 		}
-
-		text := jsonl.ExtractText(msg)
-		if text == "" {
-			continue
+		if text := jsonl.ExtractText(msg); text != "" && !fn(msgIndex, text) {
+			return msgIndex, nil
 		}
+	}
+	return msgIndex, scanner.Err()
+}
 
-		// Search in message text
-		searchText := text
-		searchQuery := query
-		if !caseSensitive {
-			searchText = strings.ToLower(text)
-			searchQuery = strings.ToLower(query)
-		}
+// containsQuery reports whether text contains query, ignoring case unless
+// caseSensitive.
+func containsQuery(text, query string, caseSensitive bool) bool {
+	if !caseSensitive {
+		text, query = strings.ToLower(text), strings.ToLower(query)
+	}
+	return strings.Contains(text, query)
+}
 
-		if !strings.Contains(searchText, searchQuery) {
-			continue
-		}
+// searchFile searches a single file for the query in message content.
+// At most maxPreviews previews are extracted; with 0, none are.
+func searchFile(path string, query string, caseSensitive bool, maxPreviews int) *SearchResult {
+	var matchCount int
+	var previews []string
+	var messageIndices []int
+	const previewLen = 150
 
+	_, err := ForEachMessageText(path, func(index int, text string) bool {
+		if !containsQuery(text, query, caseSensitive) {
+			return true
+		}
 		matchCount++
-		messageIndices = append(messageIndices, msgIndex)
+		messageIndices = append(messageIndices, index)
 
 		// Extract preview if we need more
 		if len(previews) < maxPreviews {
@@ -166,9 +283,9 @@ func searchFile(path string, query string, caseSensitive bool) *SearchResult {
 				previews = append(previews, preview)
 			}
 		}
-	}
-
-	if matchCount == 0 {
+		return true
+	})
+	if err != nil || matchCount == 0 {
 		return nil
 	}
 
@@ -290,12 +407,13 @@ func extractSearchPreview(line []byte, query string, caseSensitive bool, maxLen
 
 // QuickSearch does a fast search that only checks if a file contains the query.
 // It doesn't extract previews or count matches.
-func QuickSearch(query string, opts SearchOptions) ([]*ConversationMeta, error) {
+// If ctx is canceled, the matches found so far are returned with ErrCanceled.
+func QuickSearch(ctx context.Context, query string, opts SearchOptions) ([]*ConversationMeta, error) {
 	if opts.ProjectsDir == "" {
 		opts.ProjectsDir = DefaultProjectsDir()
 	}
 	if opts.Workers <= 0 {
-		opts.Workers = 4
+		opts.Workers = parallel.DefaultWorkers()
 	}
 
 	searchQuery := query
@@ -307,12 +425,14 @@ func QuickSearch(query string, opts SearchOptions) ([]*ConversationMeta, error)
 		ProjectsDir:   opts.ProjectsDir,
 		ProjectPath:   opts.ProjectPath,
 		IncludeAgents: opts.IncludeAgents,
+		MaxFileSize:   opts.MaxFileSize,
 	})
 
 	files, err := scanner.findFiles()
 	if err != nil {
 		return nil, err
 	}
+	files = filterSearchFiles(narrowFiles(opts.Index, query, files), opts)
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
@@ -329,6 +449,9 @@ func QuickSearch(query string, opts SearchOptions) ([]*ConversationMeta, error)
 		go func() {
 			defer wg.Done()
 			for path := range fileChan {
+				if ctx.Err() != nil {
+					return
+				}
 				if quickSearchFile(path, searchQuery, opts.CaseSensitive) {
 					meta, err := ScanConversationMeta(path)
 					if err != nil {
@@ -352,51 +475,18 @@ func QuickSearch(query string, opts SearchOptions) ([]*ConversationMeta, error)
 		results = results[:opts.Limit]
 	}
 
+	if ctx.Err() != nil {
+		return results, ErrCanceled
+	}
 	return results, nil
 }
 
 // quickSearchFile checks if a file contains the query in message content.
 func quickSearchFile(path string, query string, caseSensitive bool) bool {
-	file, err := os.Open(path)
-	if err != nil {
-		return false
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	scanner.Buffer(make([]byte, 64*1024), jsonl.MaxScannerBuffer)
-
-	for scanner.Scan() {
-		line := scanner.Bytes()
-
-		// Parse entry to check if it's a message
-		entry, err := jsonl.ParseEntry(line)
-		if err != nil || !entry.Type.IsMessage() {
-			continue
-		}
-
-		// Parse message and search in content
-		msg, err := jsonl.ParseMessage(entry)
-		if err != nil || msg == nil {
-			continue
-		}
-
-		text := jsonl.ExtractText(msg)
-		if text == "" {
-			continue
-		}
-
-		// Search in message text
-		searchText := text
-		searchQuery := query
-		if !caseSensitive {
-			searchText = strings.ToLower(text)
-			searchQuery = strings.ToLower(query)
-		}
-
-		if strings.Contains(searchText, searchQuery) {
-			return true
-		}
-	}
-	return false
+	found := false
+	ForEachMessageText(path, func(_ int, text string) bool {
+		found = containsQuery(text, query, caseSensitive)
+		return !found
+	})
+	return found
 }
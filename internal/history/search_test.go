@@ -1,8 +1,13 @@
 package history
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -12,8 +17,8 @@ func TestDefaultSearchOptions(t *testing.T) {
 	if opts.ProjectsDir == "" {
 		t.Error("ProjectsDir should not be empty")
 	}
-	if opts.Workers != 4 {
-		t.Errorf("Workers = %d, want 4", opts.Workers)
+	if opts.Workers != runtime.NumCPU() {
+		t.Errorf("Workers = %d, want %d", opts.Workers, runtime.NumCPU())
 	}
 }
 
@@ -37,7 +42,7 @@ func TestSearch_NoMatches(t *testing.T) {
 		t.Fatalf("Failed to write conversation file: %v", err)
 	}
 
-	results, err := Search("notfound", SearchOptions{
+	results, err := Search(context.Background(), "notfound", SearchOptions{
 		ProjectsDir: tmpDir,
 	})
 	if err != nil {
@@ -69,7 +74,7 @@ func TestSearch_WithMatches(t *testing.T) {
 		t.Fatalf("Failed to write conversation file: %v", err)
 	}
 
-	results, err := Search("docker", SearchOptions{
+	results, err := Search(context.Background(), "docker", SearchOptions{
 		ProjectsDir: tmpDir,
 	})
 	if err != nil {
@@ -81,6 +86,90 @@ func TestSearch_WithMatches(t *testing.T) {
 	if results[0].MatchCount != 2 {
 		t.Errorf("Expected 2 matches, got %d", results[0].MatchCount)
 	}
+	if len(results[0].Previews) != 0 {
+		t.Errorf("Expected no previews with MaxPreviews 0, got %v", results[0].Previews)
+	}
+
+	results, _ = Search(context.Background(), "docker", SearchOptions{
+		ProjectsDir: tmpDir,
+		MaxPreviews: 1,
+	})
+	if len(results) != 1 || len(results[0].Previews) != 1 {
+		t.Errorf("Expected 1 preview with MaxPreviews 1, got %v", results)
+	}
+}
+
+func TestSearch_MessageIndices(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	// Non-message entries don't take an index, matching ch show's [N] numbering
+	lines := []string{
+		`{"type":"summary","summary":"deploy notes"}`,
+		`{"type":"user","message":{"role":"user","content":"How do I deploy?"}}`,
+		`{"type":"file-history-snapshot","messageId":"deploy"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Let me check."}]}}`,
+		`{"type":"system","message":{"role":"system","content":"deploy hook ran"}}`,
+		`{"type":"queue-operation","operation":"enqueue","content":"deploy again"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Run make deploy."}]}}`,
+		`{"type":"user","message":{"role":"user","content":"Thanks"}}`,
+	}
+	convFile := filepath.Join(projectDir, "abc123.jsonl")
+	if err := os.WriteFile(convFile, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write conversation file: %v", err)
+	}
+
+	results, err := Search(context.Background(), "DEPLOY", SearchOptions{ProjectsDir: tmpDir})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+
+	got := results[0].MessageIndices
+	want := []int{1, 3, 4}
+	if len(got) != len(want) {
+		t.Fatalf("MessageIndices = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("MessageIndices = %v, want %v", got, want)
+			break
+		}
+	}
+	if results[0].MatchCount != len(want) {
+		t.Errorf("MatchCount = %d, want %d", results[0].MatchCount, len(want))
+	}
+}
+
+func TestSearch_Progress(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		content := `{"type":"user","message":{"role":"user","content":"Hello"}}` + "\n"
+		if err := os.WriteFile(filepath.Join(projectDir, name+".jsonl"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	var done, total int
+	_, err := Search(context.Background(), "hello", SearchOptions{
+		ProjectsDir: tmpDir,
+		Progress:    func(d, t int) { done, total = d, t },
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if done != 3 || total != 3 {
+		t.Errorf("last progress = %d/%d, want 3/3", done, total)
+	}
 }
 
 func TestSearch_CaseInsensitive(t *testing.T) {
@@ -103,7 +192,7 @@ func TestSearch_CaseInsensitive(t *testing.T) {
 	}
 
 	// Case insensitive search
-	results, _ := Search("docker", SearchOptions{
+	results, _ := Search(context.Background(), "docker", SearchOptions{
 		ProjectsDir:   tmpDir,
 		CaseSensitive: false,
 	})
@@ -112,7 +201,7 @@ func TestSearch_CaseInsensitive(t *testing.T) {
 	}
 
 	// Case sensitive search
-	results, _ = Search("docker", SearchOptions{
+	results, _ = Search(context.Background(), "docker", SearchOptions{
 		ProjectsDir:   tmpDir,
 		CaseSensitive: true,
 	})
@@ -143,13 +232,33 @@ func TestSearch_Limit(t *testing.T) {
 		}
 	}
 
-	results, _ := Search("docker", SearchOptions{
+	results, _ := Search(context.Background(), "docker", SearchOptions{
 		ProjectsDir: tmpDir,
 		Limit:       2,
 	})
 	if len(results) > 2 {
 		t.Errorf("Expected max 2 results with limit, got %d", len(results))
 	}
+
+	results, summary, err := SearchWithSummary(context.Background(), "docker", SearchOptions{
+		ProjectsDir: tmpDir,
+		Limit:       2,
+	})
+	if err != nil {
+		t.Fatalf("SearchWithSummary() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 results with limit, got %d", len(results))
+	}
+	if summary.TotalMatched != 5 {
+		t.Errorf("TotalMatched = %d, want 5", summary.TotalMatched)
+	}
+	if summary.TotalMatches != 5 {
+		t.Errorf("TotalMatches = %d, want 5", summary.TotalMatches)
+	}
+	if summary.FilesScanned != 5 {
+		t.Errorf("FilesScanned = %d, want 5", summary.FilesScanned)
+	}
 }
 
 func TestQuickSearch(t *testing.T) {
@@ -171,7 +280,7 @@ func TestQuickSearch(t *testing.T) {
 		t.Fatalf("Failed to write conversation file: %v", err)
 	}
 
-	results, err := QuickSearch("docker", SearchOptions{
+	results, err := QuickSearch(context.Background(), "docker", SearchOptions{
 		ProjectsDir: tmpDir,
 	})
 	if err != nil {
@@ -237,3 +346,172 @@ func TestExtractPreviewFromText(t *testing.T) {
 		})
 	}
 }
+
+func TestSearch_Canceled(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	content := `{"type":"user","message":{"role":"user","content":"Hello docker world"}}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, "abc.jsonl"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	opts := SearchOptions{ProjectsDir: tmpDir}
+	if _, err := Search(ctx, "docker", opts); !errors.Is(err, ErrCanceled) {
+		t.Errorf("Search() error = %v, want ErrCanceled", err)
+	}
+	if _, err := QuickSearch(ctx, "docker", opts); !errors.Is(err, ErrCanceled) {
+		t.Errorf("QuickSearch() error = %v, want ErrCanceled", err)
+	}
+}
+
+func TestSearch_SortBy(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	// name -> (timestamp, number of matching messages)
+	fixtures := []struct {
+		name    string
+		ts      string
+		matches int
+	}{
+		{"old-many", "2024-01-01T10:00:00Z", 3},
+		{"new-one", "2024-03-01T10:00:00Z", 1},
+		{"mid-one", "2024-02-01T10:00:00Z", 1},
+		{"mid-many", "2024-02-01T10:00:00Z", 3},
+	}
+	for _, f := range fixtures {
+		line := `{"type":"user","timestamp":"` + f.ts + `","message":{"role":"user","content":"docker"}}` + "\n"
+		path := filepath.Join(projectDir, f.name+".jsonl")
+		if err := os.WriteFile(path, []byte(strings.Repeat(line, f.matches)), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		sortBy string
+		want   string
+	}{
+		{"", "mid-many,old-many,new-one,mid-one"},
+		{SearchSortMatches, "mid-many,old-many,new-one,mid-one"},
+		{SearchSortTime, "new-one,mid-many,mid-one,old-many"},
+	}
+	for _, tt := range tests {
+		t.Run("sort="+tt.sortBy, func(t *testing.T) {
+			// Repeat to catch nondeterministic worker ordering
+			for i := 0; i < 5; i++ {
+				results, err := Search(context.Background(), "docker", SearchOptions{
+					ProjectsDir: tmpDir,
+					Workers:     4,
+					SortBy:      tt.sortBy,
+				})
+				if err != nil {
+					t.Fatalf("Search() error = %v", err)
+				}
+				ids := make([]string, len(results))
+				for j, r := range results {
+					ids[j] = r.Meta.ID
+				}
+				if got := strings.Join(ids, ","); got != tt.want {
+					t.Fatalf("order = %s, want %s", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSearch_AgentType(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	parent := `{"type":"assistant","uuid":"u1","sessionId":"main-123","message":{"role":"assistant","content":[` +
+		`{"type":"tool_use","id":"toolu_aaa111","name":"Task","input":{"subagent_type":"Explore","prompt":"find docker"}},` +
+		`{"type":"tool_use","id":"toolu_bbb222","name":"Task","input":{"subagent_type":"Plan","prompt":"plan docker"}}]}}`
+	files := map[string]string{
+		"main-123.jsonl":     parent,
+		"agent-aaa111.jsonl": `{"type":"user","sessionId":"main-123","isSidechain":true,"message":{"role":"user","content":"docker compose"}}`,
+		"agent-bbb222.jsonl": `{"type":"user","sessionId":"main-123","isSidechain":true,"message":{"role":"user","content":"docker plan"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	results, summary, err := SearchWithSummary(context.Background(), "docker", SearchOptions{
+		ProjectsDir:   tmpDir,
+		IncludeAgents: true,
+		AgentType:     "explore",
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Meta.ID != "aaa111" {
+		t.Fatalf("Expected only agent aaa111, got %d results", len(results))
+	}
+	if summary.TotalMatched != 1 {
+		t.Errorf("TotalMatched = %d, want 1", summary.TotalMatched)
+	}
+	// Conversations of other types aren't searched at all
+	if summary.FilesScanned != 1 {
+		t.Errorf("FilesScanned = %d, want 1", summary.FilesScanned)
+	}
+
+	metas, err := QuickSearch(context.Background(), "docker", SearchOptions{
+		ProjectsDir:   tmpDir,
+		IncludeAgents: true,
+		AgentType:     "explore",
+	})
+	if err != nil {
+		t.Fatalf("QuickSearch() error = %v", err)
+	}
+	if len(metas) != 1 || metas[0].ID != "aaa111" {
+		t.Errorf("QuickSearch() = %d results, want only agent aaa111", len(metas))
+	}
+}
+
+func TestForEachMessageText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conv.jsonl")
+	content := `{"type":"user","message":{"role":"user","content":"first"}}
+{"type":"summary","summary":"not a message"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{}}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"third"}]}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	var got []string
+	n, err := ForEachMessageText(path, func(index int, text string) bool {
+		got = append(got, fmt.Sprintf("%d:%s", index, text))
+		return true
+	})
+	if err != nil {
+		t.Fatalf("ForEachMessageText() error = %v", err)
+	}
+	// Messages without text keep their index but aren't passed on
+	if n != 3 || strings.Join(got, ",") != "1:first,3:third" {
+		t.Errorf("ForEachMessageText() = %d messages, %v; want 3 and [1:first 3:third]", n, got)
+	}
+
+	calls := 0
+	ForEachMessageText(path, func(int, string) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("calls after returning false = %d, want 1", calls)
+	}
+}
@@ -0,0 +1,136 @@
+package history
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/dmora/ch/internal/jsonl"
+)
+
+// MergeStreamingEntries collapses runs of consecutive assistant entries that are
+// streaming chunks of the same message into a single entry.
+//
+// Two adjacent assistant entries belong to the same run when they share the
+// same message ID, or when the second is a child of the first (ParentUUID == UUID)
+// and its text extends the first's text. The merged entry keeps the metadata of
+// the final chunk; its content blocks are the union of the run's blocks with
+// duplicates and superseded partial text removed. Other entries pass through unchanged.
+func MergeStreamingEntries(entries []*jsonl.RawEntry) []*jsonl.RawEntry {
+	merged := make([]*jsonl.RawEntry, 0, len(entries))
+
+	for i := 0; i < len(entries); {
+		end := i + 1
+		if entries[i].Type == jsonl.EntryTypeAssistant {
+			for end < len(entries) && isStreamingContinuation(entries[end-1], entries[end]) {
+				end++
+			}
+		}
+
+		if end-i > 1 {
+			merged = append(merged, mergeStreamingRun(entries[i:end]))
+		} else {
+			merged = append(merged, entries[i])
+		}
+		i = end
+	}
+
+	return merged
+}
+
+// streamingMessage holds the fields needed to detect streaming chunks.
+type streamingMessage struct {
+	ID string `json:"id"`
+}
+
+// isStreamingContinuation reports whether next continues the streamed message in prev.
+func isStreamingContinuation(prev, next *jsonl.RawEntry) bool {
+	if prev.Type != jsonl.EntryTypeAssistant || next.Type != jsonl.EntryTypeAssistant {
+		return false
+	}
+
+	var prevMsg, nextMsg streamingMessage
+	if json.Unmarshal(prev.Message, &prevMsg) == nil && json.Unmarshal(next.Message, &nextMsg) == nil {
+		if prevMsg.ID != "" && prevMsg.ID == nextMsg.ID {
+			return true
+		}
+	}
+
+	if prev.UUID == "" || next.ParentUUID != prev.UUID {
+		return false
+	}
+	prevText := entryText(prev)
+	return prevText != "" && strings.HasPrefix(entryText(next), prevText)
+}
+
+// entryText returns the text content of an entry's message.
+func entryText(entry *jsonl.RawEntry) string {
+	msg, err := jsonl.ParseMessage(entry)
+	if err != nil {
+		return ""
+	}
+	return jsonl.ExtractText(msg)
+}
+
+// mergeStreamingRun combines a run of streaming chunks into one entry.
+func mergeStreamingRun(run []*jsonl.RawEntry) *jsonl.RawEntry {
+	var blocks []jsonl.ContentBlock
+	for _, entry := range run {
+		msg, err := jsonl.ParseMessage(entry)
+		if err != nil || msg == nil {
+			continue
+		}
+		for _, block := range msg.Content {
+			blocks = appendStreamingBlock(blocks, block)
+		}
+	}
+
+	last := *run[len(run)-1]
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(last.Message, &fields) != nil {
+		return &last
+	}
+	content, err := json.Marshal(blocks)
+	if err != nil {
+		return &last
+	}
+	fields["content"] = content
+	if message, err := json.Marshal(fields); err == nil {
+		last.Message = message
+	}
+	return &last
+}
+
+// appendStreamingBlock appends a block, replacing a previous partial version of it.
+func appendStreamingBlock(blocks []jsonl.ContentBlock, block jsonl.ContentBlock) []jsonl.ContentBlock {
+	for i := len(blocks) - 1; i >= 0; i-- {
+		prev := blocks[i]
+		if prev.Type != block.Type {
+			continue
+		}
+		switch block.Type {
+		case jsonl.BlockTypeText:
+			if strings.HasPrefix(block.Text, prev.Text) {
+				blocks[i] = block
+				return blocks
+			}
+			if strings.HasPrefix(prev.Text, block.Text) {
+				return blocks
+			}
+		case jsonl.BlockTypeThinking:
+			if strings.HasPrefix(block.Thinking, prev.Thinking) {
+				blocks[i] = block
+				return blocks
+			}
+			if strings.HasPrefix(prev.Thinking, block.Thinking) {
+				return blocks
+			}
+		case jsonl.BlockTypeToolUse:
+			if block.ID != "" && block.ID == prev.ID {
+				blocks[i] = block
+				return blocks
+			}
+		}
+	}
+	return append(blocks, block)
+}
@@ -0,0 +1,66 @@
+package history
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dmora/ch/internal/jsonl"
+)
+
+func TestMergeStreamingEntries(t *testing.T) {
+	t.Run("same message id", func(t *testing.T) {
+		entries := []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeUser, UUID: "u1", Message: json.RawMessage(`{"role":"user","content":"hi"}`)},
+			{Type: jsonl.EntryTypeAssistant, UUID: "a1", ParentUUID: "u1", Message: json.RawMessage(`{"id":"msg_1","role":"assistant","model":"m","content":[{"type":"thinking","thinking":"hmm"}]}`)},
+			{Type: jsonl.EntryTypeAssistant, UUID: "a2", ParentUUID: "a1", Message: json.RawMessage(`{"id":"msg_1","role":"assistant","model":"m","content":[{"type":"text","text":"Hello"}]}`)},
+			{Type: jsonl.EntryTypeAssistant, UUID: "a3", ParentUUID: "a2", Message: json.RawMessage(`{"id":"msg_1","role":"assistant","model":"m","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{}}]}`)},
+		}
+
+		merged := MergeStreamingEntries(entries)
+		if len(merged) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(merged))
+		}
+		if merged[1].UUID != "a3" {
+			t.Errorf("merged entry should keep final chunk metadata, got UUID %q", merged[1].UUID)
+		}
+		msg, err := jsonl.ParseMessage(merged[1])
+		if err != nil {
+			t.Fatalf("ParseMessage() error = %v", err)
+		}
+		if len(msg.Content) != 3 {
+			t.Errorf("expected 3 blocks, got %d", len(msg.Content))
+		}
+		if msg.Model != "m" {
+			t.Errorf("Model = %q, want m", msg.Model)
+		}
+	})
+
+	t.Run("cumulative prefix chunks", func(t *testing.T) {
+		entries := []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeAssistant, UUID: "a1", Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Hel"}]}`)},
+			{Type: jsonl.EntryTypeAssistant, UUID: "a2", ParentUUID: "a1", Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Hello wor"}]}`)},
+			{Type: jsonl.EntryTypeAssistant, UUID: "a3", ParentUUID: "a2", Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Hello world"}]}`)},
+		}
+
+		merged := MergeStreamingEntries(entries)
+		if len(merged) != 1 {
+			t.Fatalf("expected 1 entry, got %d", len(merged))
+		}
+		msg, _ := jsonl.ParseMessage(merged[0])
+		if text := jsonl.ExtractText(msg); text != "Hello world" {
+			t.Errorf("text = %q, want %q", text, "Hello world")
+		}
+	})
+
+	t.Run("distinct messages are kept", func(t *testing.T) {
+		entries := []*jsonl.RawEntry{
+			{Type: jsonl.EntryTypeAssistant, UUID: "a1", Message: json.RawMessage(`{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"First"}]}`)},
+			{Type: jsonl.EntryTypeAssistant, UUID: "a2", ParentUUID: "a1", Message: json.RawMessage(`{"id":"msg_2","role":"assistant","content":[{"type":"text","text":"Second"}]}`)},
+		}
+
+		merged := MergeStreamingEntries(entries)
+		if len(merged) != 2 {
+			t.Errorf("expected 2 entries, got %d", len(merged))
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package history
+
+import (
+	"github.com/dmora/ch/internal/jsonl"
+	"github.com/dmora/ch/internal/parallel"
+)
+
+// CountToolCalls counts tool_use invocations by tool name in a single conversation file.
+// Only assistant messages are fully parsed; other entries are skipped cheaply.
+func CountToolCalls(path string) (map[string]int, error) {
+	parser, err := jsonl.NewParser(path)
+	if err != nil {
+		return nil, err
+	}
+	defer parser.Close()
+
+	counts := make(map[string]int)
+	for {
+		entry, err := parser.Next()
+		if err != nil || entry == nil {
+			break
+		}
+		if entry.Type != jsonl.EntryTypeAssistant || entry.Message == nil {
+			continue
+		}
+		msg, err := jsonl.ParseMessage(entry)
+		if err != nil || msg == nil {
+			continue
+		}
+		for _, name := range jsonl.ExtractToolCalls(msg) {
+			counts[name]++
+		}
+	}
+	return counts, nil
+}
+
+// HasToolError reports whether an entry carries a tool_result that failed.
+func HasToolError(entry *jsonl.RawEntry) bool {
+	if entry.Type != jsonl.EntryTypeUser || entry.Message == nil {
+		return false
+	}
+	msg, err := jsonl.ParseMessage(entry)
+	if err != nil {
+		return false
+	}
+	for _, result := range jsonl.ExtractToolResults(msg) {
+		if result.IsError {
+			return true
+		}
+	}
+	return false
+}
+
+// ToolUsage aggregates tool call counts across files using a bounded worker pool.
+func ToolUsage(files []string, workers int) map[string]int {
+	perFile := parallel.ProcessFiles(files, workers, func(path string) (map[string]int, bool) {
+		counts, err := CountToolCalls(path)
+		if err != nil || len(counts) == 0 {
+			return nil, false
+		}
+		return counts, true
+	})
+
+	total := make(map[string]int)
+	for _, counts := range perFile {
+		for name, n := range counts {
+			total[name] += n
+		}
+	}
+	return total
+}
@@ -0,0 +1,73 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dmora/ch/internal/jsonl"
+)
+
+func TestToolUsage(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "ch-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	file1 := filepath.Join(tmpDir, "abc.jsonl")
+	content1 := `{"type":"user","message":{"role":"user","content":"List files"}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{}},{"type":"tool_use","id":"t2","name":"Read","input":{}}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Bash","input":{}}]}}
+`
+	file2 := filepath.Join(tmpDir, "def.jsonl")
+	content2 := `{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t4","name":"Bash","input":{}}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"done"}]}}
+`
+	if err := os.WriteFile(file1, []byte(content1), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(file2, []byte(content2), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	counts, err := CountToolCalls(file1)
+	if err != nil {
+		t.Fatalf("CountToolCalls() error = %v", err)
+	}
+	if counts["Bash"] != 2 || counts["Read"] != 1 {
+		t.Errorf("CountToolCalls() = %v, want Bash:2 Read:1", counts)
+	}
+
+	usage := ToolUsage([]string{file1, file2}, 2)
+	if usage["Bash"] != 3 {
+		t.Errorf("Bash = %d, want 3", usage["Bash"])
+	}
+	if usage["Read"] != 1 {
+		t.Errorf("Read = %d, want 1", usage["Read"])
+	}
+	if len(usage) != 2 {
+		t.Errorf("expected 2 tools, got %d: %v", len(usage), usage)
+	}
+}
+
+func TestHasToolError(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry *jsonl.RawEntry
+		want  bool
+	}{
+		{"failed result", &jsonl.RawEntry{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"boom","is_error":true}]}`)}, true},
+		{"ok result", &jsonl.RawEntry{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"fine"}]}`)}, false},
+		{"plain text", &jsonl.RawEntry{Type: jsonl.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":"is_error"}`)}, false},
+		{"assistant", &jsonl.RawEntry{Type: jsonl.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":"hi"}`)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasToolError(tt.entry); got != tt.want {
+				t.Errorf("HasToolError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
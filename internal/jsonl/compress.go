@@ -0,0 +1,51 @@
+package jsonl
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// CompressedExt is the extension of gzip-compressed JSONL files.
+const CompressedExt = ".gz"
+
+// IsCompressed returns true if path names a gzip-compressed file.
+func IsCompressed(path string) bool {
+	return strings.HasSuffix(path, CompressedExt)
+}
+
+// Open opens a JSONL file for reading. Compressed files are transparently
+// decompressed; closing the returned reader closes the file.
+func Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !IsCompressed(path) {
+		return file, nil
+	}
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("reading gzip header: %w", err)
+	}
+	return &gzipFile{Reader: zr, file: file}, nil
+}
+
+// gzipFile is a decompressing reader that owns its underlying file.
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+// Close closes the decompressor and the file.
+func (g *gzipFile) Close() error {
+	err := g.Reader.Close()
+	if cerr := g.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -2,9 +2,51 @@ package jsonl
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// SanitizeForTerminal replaces what could garble a terminal with visible
+// markers: control characters other than newline and tab, including the ESC
+// that starts escape sequences, and any invalid UTF-8 bytes. A carriage
+// return is kept when it ends a CRLF line. Strings with nothing to replace
+// are returned unchanged.
+//
+// Content decoded from JSON is always valid UTF-8 (encoding/json substitutes
+// U+FFFD for invalid bytes), so in practice only control characters change.
+func SanitizeForTerminal(s string) string {
+	if utf8.ValidString(s) && strings.IndexFunc(s, isUnsafeControl) < 0 {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, "\\x%02x", s[i])
+		case r == '\r' && strings.HasPrefix(s[i+1:], "\n"):
+			b.WriteByte('\r')
+		case isUnsafeControl(r) && r < utf8.RuneSelf:
+			fmt.Fprintf(&b, "\\x%02x", r)
+		case isUnsafeControl(r):
+			fmt.Fprintf(&b, "\\u%04x", r)
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// isUnsafeControl reports whether r is a control character other than
+// newline and tab.
+func isUnsafeControl(r rune) bool {
+	return unicode.IsControl(r) && r != '\n' && r != '\t'
+}
+
 // ExtractText extracts all text content from a message.
 func ExtractText(msg *Message) string {
 	if msg == nil {
@@ -158,27 +200,35 @@ func ExtractToolResults(msg *Message) []ToolResult {
 				ToolUseID: block.ToolUseID,
 				IsError:   block.IsError,
 			}
-			// Content can be a string or array
-			if block.Content != nil {
-				var str string
-				if err := json.Unmarshal(block.Content, &str); err == nil {
-					result.Content = str
-				} else {
-					// Try as array of content blocks
-					var blocks []ContentBlock
-					if err := json.Unmarshal(block.Content, &blocks); err == nil {
-						var texts []string
-						for _, b := range blocks {
-							if b.Text != "" {
-								texts = append(texts, b.Text)
-							}
-						}
-						result.Content = strings.Join(texts, "\n")
-					}
-				}
-			}
+			result.Content = ToolResultText(&block)
 			results = append(results, result)
 		}
 	}
 	return results
 }
+
+// ToolResultText returns the text of a tool_result block.
+// Content can be a plain string or an array of content blocks.
+func ToolResultText(block *ContentBlock) string {
+	if block.Content == nil {
+		return ""
+	}
+
+	var str string
+	if err := json.Unmarshal(block.Content, &str); err == nil {
+		return str
+	}
+
+	// Try as array of content blocks
+	var blocks []ContentBlock
+	if err := json.Unmarshal(block.Content, &blocks); err != nil {
+		return ""
+	}
+	var texts []string
+	for _, b := range blocks {
+		if b.Text != "" {
+			texts = append(texts, b.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
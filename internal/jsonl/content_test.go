@@ -2,6 +2,7 @@ package jsonl
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -27,6 +28,51 @@ func TestExtractText_Nil(t *testing.T) {
 	}
 }
 
+func TestSanitizeForTerminal(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain ascii", "plain ascii"},
+		{"héllo → ✓", "héllo → ✓"},
+		{"tabs\tand\nnewlines", "tabs\tand\nnewlines"},
+		{"crlf\r\nline", "crlf\r\nline"},
+		{"\x1b[31mred\x1b[0m", "\\x1b[31mred\\x1b[0m"},
+		{"\x00\x01 ok", "\\x00\\x01 ok"},
+		{"over\rwrite", "over\\x0dwrite"},
+		{"c1 \u009b", "c1 \\u009b"},
+		{"bad\xffbyte", "bad\\xffbyte"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := SanitizeForTerminal(tt.in); got != tt.want {
+			t.Errorf("SanitizeForTerminal(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeForTerminal_ParsedLine(t *testing.T) {
+	// JSON escapes a tool's raw escape sequence as \u001b; invalid bytes
+	// decode to U+FFFD, so they can't reach the terminal as-is
+	line := `{"type":"user","message":{"role":"user","content":"\u001b]0;title\u0007ls \u001b[2J` + "\xff" + `"}}`
+	parser := NewParserFromReader(strings.NewReader(line))
+	entry, err := parser.Next()
+	if err != nil || entry == nil {
+		t.Fatalf("Next() = %v, %v", entry, err)
+	}
+	msg, err := ParseMessage(entry)
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+
+	text := ExtractText(msg)
+	if text != "\x1b]0;title\x07ls \x1b[2J\ufffd" {
+		t.Fatalf("ExtractText() = %q, want content unchanged", text)
+	}
+	if got := SanitizeForTerminal(text); got != "\\x1b]0;title\\x07ls \\x1b[2J\ufffd" {
+		t.Errorf("SanitizeForTerminal() = %q, want control characters marked", got)
+	}
+}
+
 func TestExtractThinking(t *testing.T) {
 	msg := &Message{
 		Content: []ContentBlock{
@@ -2,46 +2,84 @@ package jsonl
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 )
 
 // MaxScannerBuffer is the maximum buffer size for the scanner (100MB).
 // Large conversations with many tool calls can exceed 10MB per line.
 const MaxScannerBuffer = 100 * 1024 * 1024
 
+// utf8BOM is the UTF-8 byte order mark some Windows tools write at the start
+// of a file.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// trimBOM strips a leading UTF-8 byte order mark, which json.Unmarshal
+// rejects as invalid input.
+func trimBOM(line []byte) []byte {
+	return bytes.TrimPrefix(line, utf8BOM)
+}
+
+// LineError records a line that could not be parsed.
+type LineError struct {
+	Line int   // 1-based line number
+	Err  error // Parse error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
 // Parser provides streaming parsing of JSONL files.
 type Parser struct {
-	scanner *bufio.Scanner
-	file    *os.File
+	scanner    *bufio.Scanner
+	file       io.Closer
+	lenient    bool
+	lineNum    int
+	lineErrors []LineError
+	consumed   int64 // Bytes consumed by the scanner so far
+	lineOffset int64 // Byte offset of the start of the last line scanned
 }
 
-// NewParser creates a new parser for the given file path.
+// NewParser creates a new parser for the given file path. Files ending in
+// CompressedExt are decompressed as they are read.
 func NewParser(path string) (*Parser, error) {
-	file, err := os.Open(path)
+	file, err := Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("opening file: %w", err)
 	}
 
-	scanner := bufio.NewScanner(file)
-	scanner.Buffer(make([]byte, 64*1024), MaxScannerBuffer)
-
-	return &Parser{
-		scanner: scanner,
-		file:    file,
-	}, nil
+	p := &Parser{file: file}
+	p.initScanner(file)
+	return p, nil
 }
 
 // NewParserFromReader creates a new parser from an io.Reader.
 func NewParserFromReader(r io.Reader) *Parser {
-	scanner := bufio.NewScanner(r)
-	scanner.Buffer(make([]byte, 64*1024), MaxScannerBuffer)
+	p := &Parser{}
+	p.initScanner(r)
+	return p
+}
+
+// initScanner sets up a line scanner on r that tracks byte offsets.
+func (p *Parser) initScanner(r io.Reader) {
+	p.scanner = bufio.NewScanner(r)
+	p.scanner.Buffer(make([]byte, 64*1024), MaxScannerBuffer)
+	p.scanner.Split(p.scanLines)
+}
 
-	return &Parser{
-		scanner: scanner,
+// scanLines is bufio.ScanLines, recording where each line starts. Every
+// token is returned with a non-zero advance, so the offset before advancing
+// is the start of the token.
+func (p *Parser) scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	if token != nil {
+		p.lineOffset = p.consumed
 	}
+	p.consumed += int64(advance)
+	return advance, token, err
 }
 
 // Close closes the underlying file if one was opened.
@@ -52,26 +90,42 @@ func (p *Parser) Close() error {
 	return nil
 }
 
+// SetLenient controls how malformed lines are handled. In lenient mode they
+// are recorded (see Errors) and skipped instead of aborting the parse.
+func (p *Parser) SetLenient(lenient bool) {
+	p.lenient = lenient
+}
+
+// Errors returns the lines skipped in lenient mode.
+func (p *Parser) Errors() []LineError {
+	return p.lineErrors
+}
+
 // Next returns the next raw entry, or nil if there are no more entries.
 func (p *Parser) Next() (*RawEntry, error) {
-	if !p.scanner.Scan() {
-		if err := p.scanner.Err(); err != nil {
-			return nil, fmt.Errorf("scanning: %w", err)
+	for p.scanner.Scan() {
+		p.lineNum++
+		line := trimBOM(p.scanner.Bytes())
+		if len(line) == 0 {
+			continue // Skip empty lines
 		}
-		return nil, nil // EOF
-	}
 
-	line := p.scanner.Bytes()
-	if len(line) == 0 {
-		return p.Next() // Skip empty lines
-	}
+		var entry RawEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			if p.lenient {
+				p.lineErrors = append(p.lineErrors, LineError{Line: p.lineNum, Err: err})
+				continue
+			}
+			return nil, fmt.Errorf("parsing JSON: %w", err)
+		}
 
-	var entry RawEntry
-	if err := json.Unmarshal(line, &entry); err != nil {
-		return nil, fmt.Errorf("parsing JSON: %w", err)
+		return &entry, nil
 	}
 
-	return &entry, nil
+	if err := p.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanning: %w", err)
+	}
+	return nil, nil // EOF
 }
 
 // NextRaw returns the next line as raw bytes without parsing.
@@ -82,7 +136,8 @@ func (p *Parser) NextRaw() ([]byte, error) {
 		}
 		return nil, nil // EOF
 	}
-	return p.scanner.Bytes(), nil
+	p.lineNum++
+	return trimBOM(p.scanner.Bytes()), nil
 }
 
 // ParseAll parses all entries from the file.
@@ -101,10 +156,38 @@ func (p *Parser) ParseAll() ([]*RawEntry, error) {
 	return entries, nil
 }
 
+// ParseAllWithOffsets parses all entries like ParseAll and also returns the
+// byte offset at which each entry's line starts, parallel to the entries.
+// Offsets let callers seek straight to an entry, e.g. to open an editor at it.
+func (p *Parser) ParseAllWithOffsets() ([]*RawEntry, []int64, error) {
+	var entries []*RawEntry
+	var offsets []int64
+	for {
+		entry, err := p.Next()
+		if err != nil {
+			return entries, offsets, err
+		}
+		if entry == nil {
+			break
+		}
+		entries = append(entries, entry)
+		offsets = append(offsets, p.lineOffset)
+	}
+	return entries, offsets, nil
+}
+
+// ParseAllLenient parses all entries, skipping malformed lines.
+// It returns the good entries and the lines that were skipped.
+func (p *Parser) ParseAllLenient() ([]*RawEntry, []LineError, error) {
+	p.SetLenient(true)
+	entries, err := p.ParseAll()
+	return entries, p.Errors(), err
+}
+
 // ParseEntry parses a single JSON line into a RawEntry.
 func ParseEntry(line []byte) (*RawEntry, error) {
 	var entry RawEntry
-	if err := json.Unmarshal(line, &entry); err != nil {
+	if err := json.Unmarshal(trimBOM(line), &entry); err != nil {
 		return nil, fmt.Errorf("parsing JSON: %w", err)
 	}
 	return &entry, nil
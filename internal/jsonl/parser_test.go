@@ -1,6 +1,9 @@
 package jsonl
 
 import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -91,6 +94,46 @@ func TestParseEntry_Invalid(t *testing.T) {
 	}
 }
 
+func TestParser_BOM(t *testing.T) {
+	content := "\xEF\xBB\xBF" + `{"type":"user","message":{"role":"user","content":"Hello"}}
+{"type":"assistant","message":{"role":"assistant","content":"Hi!"}}
+`
+	path := filepath.Join(t.TempDir(), "bom.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	parser, err := NewParser(path)
+	if err != nil {
+		t.Fatalf("NewParser() error = %v", err)
+	}
+	defer parser.Close()
+
+	entries, err := parser.ParseAll()
+	if err != nil {
+		t.Fatalf("ParseAll() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Type != EntryTypeUser {
+		t.Errorf("ParseAll() = %d entries, want 2 starting with a user entry", len(entries))
+	}
+
+	raw, err := NewParserFromReader(strings.NewReader(content)).NextRaw()
+	if err != nil {
+		t.Fatalf("NextRaw() error = %v", err)
+	}
+	if !strings.HasPrefix(string(raw), "{") {
+		t.Errorf("NextRaw() = %q, want the BOM stripped", raw)
+	}
+
+	entry, err := ParseEntry([]byte("\xEF\xBB\xBF" + `{"type":"summary"}`))
+	if err != nil {
+		t.Fatalf("ParseEntry() error = %v", err)
+	}
+	if entry.Type != EntryTypeSummary {
+		t.Errorf("Type = %q, want %q", entry.Type, EntryTypeSummary)
+	}
+}
+
 func TestParseMessage(t *testing.T) {
 	entry := &RawEntry{
 		Type:    EntryTypeUser,
@@ -149,3 +192,98 @@ func TestParser_NextRaw(t *testing.T) {
 		t.Errorf("line2 = %q, want %q", string(line2), `{"type":"assistant"}`)
 	}
 }
+
+func TestParser_ParseAllLenient(t *testing.T) {
+	input := `{"type":"user"}
+{"type":"assist
+not json at all
+
+{"type":"assistant"}
+`
+	parser := NewParserFromReader(strings.NewReader(input))
+	entries, skipped, err := parser.ParseAllLenient()
+	if err != nil {
+		t.Fatalf("ParseAllLenient() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("len(entries) = %d, want 2", len(entries))
+	}
+	if len(skipped) != 2 {
+		t.Fatalf("len(skipped) = %d, want 2", len(skipped))
+	}
+	if skipped[0].Line != 2 || skipped[1].Line != 3 {
+		t.Errorf("skipped lines = %d, %d, want 2, 3", skipped[0].Line, skipped[1].Line)
+	}
+
+	// Strict mode still fails on the first malformed line
+	parser = NewParserFromReader(strings.NewReader(input))
+	if _, err := parser.ParseAll(); err == nil {
+		t.Error("ParseAll() expected error for malformed line")
+	}
+}
+
+func TestParser_ParseAllWithOffsets(t *testing.T) {
+	lines := []string{
+		`{"type":"user"}` + "\n",
+		"\n",
+		`{"type":"assistant"}` + "\r\n",
+		"not json\n",
+		`{"type":"system"}`,
+	}
+	input := strings.Join(lines, "")
+
+	parser := NewParserFromReader(strings.NewReader(input))
+	parser.SetLenient(true)
+	entries, offsets, err := parser.ParseAllWithOffsets()
+	if err != nil {
+		t.Fatalf("ParseAllWithOffsets() error = %v", err)
+	}
+	if len(entries) != 3 || len(offsets) != 3 {
+		t.Fatalf("got %d entries and %d offsets, want 3 each", len(entries), len(offsets))
+	}
+
+	for i, entry := range entries {
+		rest := input[offsets[i]:]
+		if !strings.HasPrefix(rest, `{"type":"`+string(entry.Type)+`"}`) {
+			t.Errorf("offset %d for %s points at %q", offsets[i], entry.Type, rest)
+		}
+	}
+}
+
+func TestNewParser_Compressed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abc123.jsonl.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(file)
+	zw.Write([]byte(`{"type":"user","uuid":"u1"}` + "\n" + `{"type":"assistant","uuid":"u2"}` + "\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	parser, err := NewParser(path)
+	if err != nil {
+		t.Fatalf("NewParser() error = %v", err)
+	}
+	defer parser.Close()
+
+	entries, err := parser.ParseAll()
+	if err != nil {
+		t.Fatalf("ParseAll() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].UUID != "u1" || entries[1].UUID != "u2" {
+		t.Errorf("ParseAll() = %+v, want u1 and u2", entries)
+	}
+}
+
+func TestNewParser_CorruptCompressed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abc123.jsonl.gz")
+	if err := os.WriteFile(path, []byte(`{"type":"user"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewParser(path); err == nil {
+		t.Error("expected error for a .gz file that is not gzip")
+	}
+}
@@ -1,7 +1,10 @@
 // Package jsonl provides types and utilities for parsing Claude Code JSONL conversation files.
 package jsonl
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/json"
+)
 
 // EntryType represents the type of a JSONL entry.
 type EntryType string
@@ -24,8 +27,19 @@ const (
 	BlockTypeToolUse    ContentBlockType = "tool_use"
 	BlockTypeToolResult ContentBlockType = "tool_result"
 	BlockTypeImage      ContentBlockType = "image"
+
+	BlockTypeRedactedThinking ContentBlockType = "redacted_thinking"
 )
 
+// IsKnown returns true if ch knows how to handle the block type.
+func (t ContentBlockType) IsKnown() bool {
+	switch t {
+	case BlockTypeText, BlockTypeThinking, BlockTypeToolUse, BlockTypeToolResult, BlockTypeImage, BlockTypeRedactedThinking:
+		return true
+	}
+	return false
+}
+
 // RawEntry represents a raw JSON entry with minimal parsing.
 // The Message field is kept as json.RawMessage for deferred parsing.
 type RawEntry struct {
@@ -37,18 +51,57 @@ type RawEntry struct {
 	IsSidechain bool            `json:"isSidechain,omitempty"`
 	AgentID     string          `json:"agentId,omitempty"`
 	CWD         string          `json:"cwd,omitempty"`
+	GitBranch   string          `json:"gitBranch,omitempty"`
 	Message     json.RawMessage `json:"message,omitempty"`
 	Summary     string          `json:"summary,omitempty"`
+
+	// Queue-operation entries record Claude Code's input queue: prompts typed
+	// while the assistant is busy are enqueued and later dequeued or removed.
+	Operation string          `json:"operation,omitempty"` // enqueue, dequeue, remove, popAll
+	Content   json.RawMessage `json:"content,omitempty"`   // Queued prompt, usually a string
+}
+
+// QueueContent returns a queue-operation entry's content as text: strings
+// are unquoted and anything else is returned as compact JSON.
+func (e *RawEntry) QueueContent() string {
+	if len(e.Content) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(e.Content, &s) == nil {
+		return s
+	}
+	var buf bytes.Buffer
+	if json.Compact(&buf, e.Content) != nil {
+		return string(e.Content)
+	}
+	return buf.String()
 }
 
 // Message represents a fully parsed message with role and content blocks.
 type Message struct {
 	Role    string         `json:"role"`
 	Model   string         `json:"model,omitempty"`
-	Content []ContentBlock `json:"-"` // Custom unmarshaling
+	Usage   *Usage         `json:"usage,omitempty"` // Assistant messages only, when recorded
+	Content []ContentBlock `json:"-"`               // Custom unmarshaling
+}
+
+// Usage is the token usage the API reported for an assistant message.
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+}
+
+// TotalInputTokens returns the full prompt size: uncached input plus tokens
+// written to and read from the prompt cache.
+func (u *Usage) TotalInputTokens() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
 }
 
-// UnmarshalJSON implements custom JSON unmarshaling to handle content as string or array.
+// UnmarshalJSON implements custom JSON unmarshaling to handle content as a
+// string, an array of blocks, or an object (see objectContent).
 func (m *Message) UnmarshalJSON(data []byte) error {
 	// Use an alias to avoid recursion
 	type Alias Message
@@ -78,11 +131,52 @@ func (m *Message) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
-	// If neither works, leave content empty
+	// Object content is converted rather than dropped
+	if blocks, ok := objectContent(aux.Content); ok {
+		m.Content = blocks
+		return nil
+	}
+
+	// Anything else (e.g. a number) leaves content empty
 	m.Content = nil
 	return nil
 }
 
+// objectContent converts object-shaped message content into blocks so no
+// data is lost. An object with a known block type is used as that block;
+// otherwise its "text" field, or its "content" string or block array, is
+// used; failing that, the object's JSON becomes a text block.
+// Returns false if raw is not a JSON object.
+func objectContent(raw json.RawMessage) ([]ContentBlock, bool) {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(raw, &obj) != nil || obj == nil {
+		return nil, false
+	}
+
+	var block ContentBlock
+	if json.Unmarshal(raw, &block) == nil && block.Type.IsKnown() {
+		return []ContentBlock{block}, true
+	}
+
+	var text string
+	if json.Unmarshal(obj["text"], &text) == nil && text != "" {
+		return []ContentBlock{{Type: BlockTypeText, Text: text}}, true
+	}
+	if json.Unmarshal(obj["content"], &text) == nil && text != "" {
+		return []ContentBlock{{Type: BlockTypeText, Text: text}}, true
+	}
+	var blocks []ContentBlock
+	if json.Unmarshal(obj["content"], &blocks) == nil && len(blocks) > 0 {
+		return blocks, true
+	}
+
+	var compact bytes.Buffer
+	if json.Compact(&compact, raw) != nil {
+		return nil, false
+	}
+	return []ContentBlock{{Type: BlockTypeText, Text: compact.String()}}, true
+}
+
 // ContentBlock represents a single content block within a message.
 type ContentBlock struct {
 	Type      ContentBlockType `json:"type"`
@@ -94,6 +188,23 @@ type ContentBlock struct {
 	ToolUseID string           `json:"tool_use_id,omitempty"`
 	Content   json.RawMessage  `json:"content,omitempty"`
 	IsError   bool             `json:"is_error,omitempty"`
+
+	// Raw holds the original JSON of blocks with an unknown type, so they
+	// can still be inspected.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes a content block, keeping the raw JSON of unknown types.
+func (b *ContentBlock) UnmarshalJSON(data []byte) error {
+	// Use an alias to avoid recursion
+	type Alias ContentBlock
+	if err := json.Unmarshal(data, (*Alias)(b)); err != nil {
+		return err
+	}
+	if !b.Type.IsKnown() {
+		b.Raw = append(json.RawMessage(nil), data...)
+	}
+	return nil
 }
 
 // IsUserOrAssistant returns true if the entry type is user or assistant.
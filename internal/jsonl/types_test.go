@@ -98,6 +98,24 @@ func TestMessage_UnmarshalJSON_ArrayContent(t *testing.T) {
 	}
 }
 
+func TestMessage_UnmarshalJSON_UnknownBlock(t *testing.T) {
+	data := `{"role":"assistant","content":[{"type":"text","text":"Hi"},{"type":"server_tool_use","id":"srv_1","name":"web_search"}]}`
+
+	var msg Message
+	if err := json.Unmarshal([]byte(data), &msg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(msg.Content) != 2 {
+		t.Fatalf("Content length = %d, want 2", len(msg.Content))
+	}
+	if msg.Content[0].Raw != nil {
+		t.Errorf("Content[0].Raw = %s, want nil for known block", msg.Content[0].Raw)
+	}
+	if want := `{"type":"server_tool_use","id":"srv_1","name":"web_search"}`; string(msg.Content[1].Raw) != want {
+		t.Errorf("Content[1].Raw = %s, want %s", msg.Content[1].Raw, want)
+	}
+}
+
 func TestMessage_UnmarshalJSON_EmptyContent(t *testing.T) {
 	data := `{"role":"user"}`
 
@@ -114,6 +132,35 @@ func TestMessage_UnmarshalJSON_EmptyContent(t *testing.T) {
 	}
 }
 
+func TestMessage_UnmarshalJSON_ObjectContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantType ContentBlockType
+		wantText string
+	}{
+		{"single block", `{"role":"assistant","content":{"type":"text","text":"hello"}}`, BlockTypeText, "hello"},
+		{"text field", `{"role":"user","content":{"text":"from text"}}`, BlockTypeText, "from text"},
+		{"content string", `{"role":"user","content":{"content":"from content"}}`, BlockTypeText, "from content"},
+		{"content blocks", `{"role":"user","content":{"content":[{"type":"text","text":"nested"}]}}`, BlockTypeText, "nested"},
+		{"fallback to JSON", `{"role":"user","content":{"kind":"note", "value": 3}}`, BlockTypeText, `{"kind":"note","value":3}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg Message
+			if err := json.Unmarshal([]byte(tt.data), &msg); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if len(msg.Content) != 1 {
+				t.Fatalf("len(Content) = %d, want 1", len(msg.Content))
+			}
+			if msg.Content[0].Type != tt.wantType || msg.Content[0].Text != tt.wantText {
+				t.Errorf("Content[0] = {%s %q}, want {%s %q}", msg.Content[0].Type, msg.Content[0].Text, tt.wantType, tt.wantText)
+			}
+		})
+	}
+}
+
 func TestRawEntry_Unmarshal(t *testing.T) {
 	data := `{"type":"user","timestamp":"2024-01-01T00:00:00Z","sessionId":"abc123","message":{"role":"user","content":"test"}}`
 
@@ -157,3 +204,53 @@ func TestRawEntry_Agent(t *testing.T) {
 		t.Error("IsSidechain should be true")
 	}
 }
+
+func TestRawEntry_QueueOp(t *testing.T) {
+	data := `{"type":"queue-operation","operation":"enqueue","timestamp":"2024-01-01T00:00:00Z","content":"also fix the lint"}`
+
+	var entry RawEntry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if entry.Type != EntryTypeQueueOp {
+		t.Errorf("Type = %q, want %q", entry.Type, EntryTypeQueueOp)
+	}
+	if entry.Operation != "enqueue" {
+		t.Errorf("Operation = %q, want %q", entry.Operation, "enqueue")
+	}
+	if got := entry.QueueContent(); got != "also fix the lint" {
+		t.Errorf("QueueContent() = %q, want %q", got, "also fix the lint")
+	}
+
+	entry.Content = json.RawMessage(`[ {"type": "text"} ]`)
+	if got := entry.QueueContent(); got != `[{"type":"text"}]` {
+		t.Errorf("QueueContent() = %q, want compact JSON", got)
+	}
+}
+
+func TestMessage_UnmarshalJSON_Usage(t *testing.T) {
+	data := `{"role":"assistant","content":"hi","usage":{"input_tokens":10,"cache_creation_input_tokens":200,"cache_read_input_tokens":1024,"output_tokens":567}}`
+
+	var msg Message
+	if err := json.Unmarshal([]byte(data), &msg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if msg.Usage == nil {
+		t.Fatal("Usage should not be nil")
+	}
+	if got := msg.Usage.TotalInputTokens(); got != 1234 {
+		t.Errorf("TotalInputTokens() = %d, want 1234", got)
+	}
+	if msg.Usage.OutputTokens != 567 {
+		t.Errorf("OutputTokens = %d, want 567", msg.Usage.OutputTokens)
+	}
+
+	var plain Message
+	if err := json.Unmarshal([]byte(`{"role":"assistant","content":"hi"}`), &plain); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if plain.Usage != nil {
+		t.Errorf("Usage = %+v, want nil when absent", plain.Usage)
+	}
+}
@@ -1,6 +1,14 @@
 package parallel
 
-import "sync"
+import (
+	"runtime"
+	"sync"
+)
+
+// DefaultWorkers returns the worker count used when none is configured.
+func DefaultWorkers() int {
+	return runtime.NumCPU()
+}
 
 // ProcessFiles runs a function on files in parallel with a worker pool.
 // The function fn should return (result, include) where include indicates
@@ -10,7 +18,7 @@ func ProcessFiles[T any](files []string, workers int, fn func(path string) (T, b
 		return nil
 	}
 	if workers <= 0 {
-		workers = 4
+		workers = DefaultWorkers()
 	}
 
 	var wg sync.WaitGroup
@@ -48,7 +56,7 @@ func ProcessFilesWithLimit[T any](files []string, workers, limit int, fn func(pa
 		return nil
 	}
 	if workers <= 0 {
-		workers = 4
+		workers = DefaultWorkers()
 	}
 
 	var wg sync.WaitGroup
@@ -0,0 +1,32 @@
+package parallel
+
+import "sync"
+
+// ProgressFunc reports that done of total items have been processed.
+type ProgressFunc func(done, total int)
+
+// Tracker counts processed items and reports each one to a ProgressFunc.
+// It is safe for concurrent use, and the ProgressFunc is never called
+// concurrently. A nil Tracker, or one without a ProgressFunc, does nothing.
+type Tracker struct {
+	mu    sync.Mutex
+	fn    ProgressFunc
+	done  int
+	total int
+}
+
+// NewTracker returns a tracker for total items reporting to fn, which may be nil.
+func NewTracker(total int, fn ProgressFunc) *Tracker {
+	return &Tracker{fn: fn, total: total}
+}
+
+// Done records one processed item.
+func (t *Tracker) Done() {
+	if t == nil || t.fn == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.done++
+	t.fn(t.done, t.total)
+}
@@ -0,0 +1,223 @@
+// Package searchindex maintains a full-text index of conversation messages in
+// the ch database, so searches only read files that can contain the query.
+package searchindex
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dmora/ch/internal/history"
+	"github.com/dmora/ch/internal/parallel"
+	"github.com/dmora/ch/internal/syncdb"
+)
+
+// minQueryLen is the shortest query the trigram index can answer.
+const minQueryLen = 3
+
+// Index is the search index stored in the ch database.
+type Index struct {
+	db *syncdb.DB
+}
+
+// New returns the search index stored in db.
+func New(db *syncdb.DB) *Index {
+	return &Index{db: db}
+}
+
+// BuildOptions configures an index build.
+type BuildOptions struct {
+	ProjectsDir string
+	Workers     int // Number of parallel workers (default: number of CPUs)
+}
+
+// BuildResult holds the result of an index build.
+type BuildResult struct {
+	FilesScanned int // Conversation files found
+	FilesIndexed int // Files (re)indexed because they were new or changed
+	FilesRemoved int // Index entries dropped because their file is gone
+	Errors       []error
+	Duration     time.Duration
+}
+
+// Build brings the index up to date with the conversation files. Like sync,
+// it only reads files whose size or modification time changed since they
+// were last indexed.
+func (ix *Index) Build(ctx context.Context, opts BuildOptions) (*BuildResult, error) {
+	start := time.Now()
+	result := &BuildResult{}
+
+	files, err := history.NewScanner(history.ScannerOptions{
+		ProjectsDir:   opts.ProjectsDir,
+		IncludeAgents: true,
+	}).Files()
+	if err != nil {
+		return nil, fmt.Errorf("finding files: %w", err)
+	}
+	result.FilesScanned = len(files)
+
+	indexed, err := ix.db.IndexedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+
+	// Only files that were reindexed or failed are collected
+	type outcome struct {
+		path string
+		err  error
+	}
+	outcomes := parallel.ProcessFiles(files, opts.Workers, func(path string) (outcome, bool) {
+		if ctx.Err() != nil {
+			return outcome{}, false
+		}
+		changed, err := ix.indexFile(path, indexed[path])
+		return outcome{path: path, err: err}, changed || err != nil
+	})
+	for _, o := range outcomes {
+		if o.err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", o.path, o.err))
+		} else {
+			result.FilesIndexed++
+		}
+	}
+	if ctx.Err() != nil {
+		result.Duration = time.Since(start)
+		return result, history.ErrCanceled
+	}
+
+	// Drop files that no longer exist
+	present := make(map[string]bool, len(files))
+	for _, f := range files {
+		present[f] = true
+	}
+	for path := range indexed {
+		if present[path] {
+			continue
+		}
+		if err := ix.db.RemoveIndexedFile(path); err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		result.FilesRemoved++
+	}
+
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// indexFile reindexes a file if it changed since state was recorded.
+// Returns true if the file was reindexed.
+func (ix *Index) indexFile(path string, state *syncdb.IndexedFile) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if !isStale(state, info) {
+		return false, nil
+	}
+
+	text, messages, err := messageText(path)
+	if err != nil {
+		return false, err
+	}
+	err = ix.db.IndexFile(&syncdb.IndexedFile{
+		FilePath:     path,
+		Size:         info.Size(),
+		Mtime:        info.ModTime().Unix(),
+		MessageCount: messages,
+		IndexedAt:    time.Now().Unix(),
+	}, text)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// isStale reports whether a file is missing from the index or changed since
+// it was indexed.
+func isStale(state *syncdb.IndexedFile, info os.FileInfo) bool {
+	return state == nil || state.Size != info.Size() || state.Mtime != info.ModTime().Unix()
+}
+
+// messageText returns the searchable text of a file's messages, one message
+// per line, and the number of messages.
+func messageText(path string) (string, int, error) {
+	var b strings.Builder
+	messages, err := history.ForEachMessageText(path, func(_ int, text string) bool {
+		b.WriteString(text)
+		b.WriteByte('\n')
+		return true
+	})
+	return b.String(), messages, err
+}
+
+// Candidates returns the files that may contain query: indexed files whose
+// text matches, plus every file that isn't indexed or changed since it was.
+// Queries too short for the index return all files. Matching ignores case,
+// so case-sensitive searches still get a superset to check.
+func (ix *Index) Candidates(query string, files []string) ([]string, error) {
+	if utf8.RuneCountInString(query) < minQueryLen {
+		return files, nil
+	}
+
+	indexed, err := ix.db.IndexedFiles()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := ix.db.MatchIndex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for _, path := range files {
+		if matches[path] {
+			candidates = append(candidates, path)
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || isStale(indexed[path], info) {
+			candidates = append(candidates, path)
+		}
+	}
+	return candidates, nil
+}
+
+// Status describes how current the index is.
+type Status struct {
+	*syncdb.IndexStats
+	Stale int // Conversation files new or changed since they were indexed
+}
+
+// Status returns index statistics and the number of files a build would
+// reindex.
+func (ix *Index) Status(projectsDir string) (*Status, error) {
+	stats, err := ix.db.IndexStats()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := history.NewScanner(history.ScannerOptions{
+		ProjectsDir:   projectsDir,
+		IncludeAgents: true,
+	}).Files()
+	if err != nil {
+		return nil, fmt.Errorf("finding files: %w", err)
+	}
+	indexed, err := ix.db.IndexedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{IndexStats: stats}
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err == nil && isStale(indexed[path], info) {
+			status.Stale++
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,98 @@
+package searchindex
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dmora/ch/internal/syncdb"
+)
+
+func TestIndex_BuildAndCandidates(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	write := func(name, text string) string {
+		path := filepath.Join(projectDir, name)
+		line := `{"type":"user","sessionId":"s","message":{"role":"user","content":"` + text + `"}}` + "\n"
+		if err := os.WriteFile(path, []byte(line), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		return path
+	}
+	alpha := write("aaaa1111-0000.jsonl", "Deploy the Kubernetes cluster")
+	beta := write("bbbb2222-0000.jsonl", "Fix the flaky test")
+
+	db, err := syncdb.Open(filepath.Join(tmpDir, "ch.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+	ix := New(db)
+
+	result, err := ix.Build(context.Background(), BuildOptions{ProjectsDir: tmpDir})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.FilesScanned != 2 || result.FilesIndexed != 2 || len(result.Errors) != 0 {
+		t.Errorf("first build = %+v, want 2 scanned and 2 indexed", result)
+	}
+
+	// Unchanged files are not reindexed
+	result, err = ix.Build(context.Background(), BuildOptions{ProjectsDir: tmpDir})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.FilesIndexed != 0 {
+		t.Errorf("rebuild indexed %d files, want 0", result.FilesIndexed)
+	}
+
+	files := []string{alpha, beta}
+	if got, _ := ix.Candidates("kubernetes", files); !slices.Equal(got, []string{alpha}) {
+		t.Errorf("Candidates(kubernetes) = %v, want [%s]", got, alpha)
+	}
+	if got, _ := ix.Candidates("xyz", files); len(got) != 0 {
+		t.Errorf("Candidates(xyz) = %v, want none", got)
+	}
+	if got, _ := ix.Candidates("te", files); len(got) != 2 {
+		t.Errorf("Candidates for a short query = %v, want all files", got)
+	}
+
+	// A changed file is a candidate until it is reindexed
+	write("bbbb2222-0000.jsonl", "Kubernetes again, with a longer message")
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(beta, future, future); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+	if got, _ := ix.Candidates("xyz", files); !slices.Equal(got, []string{beta}) {
+		t.Errorf("Candidates with a stale file = %v, want [%s]", got, beta)
+	}
+	status, err := ix.Status(tmpDir)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Files != 2 || status.Stale != 1 {
+		t.Errorf("status = %d files, %d stale, want 2 and 1", status.Files, status.Stale)
+	}
+
+	// Deleted files are dropped from the index
+	if err := os.Remove(alpha); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	result, err = ix.Build(context.Background(), BuildOptions{ProjectsDir: tmpDir})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.FilesIndexed != 1 || result.FilesRemoved != 1 {
+		t.Errorf("build after changes = %+v, want 1 indexed and 1 removed", result)
+	}
+	if got, _ := ix.Candidates("kubernetes", []string{beta}); !slices.Equal(got, []string{beta}) {
+		t.Errorf("Candidates after reindex = %v, want [%s]", got, beta)
+	}
+}
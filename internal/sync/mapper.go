@@ -9,38 +9,118 @@ import (
 	"github.com/dmora/ch/internal/jsonl"
 )
 
+// ToolResultSpanName is the name of spans created for tool_result blocks.
+const ToolResultSpanName = "tool-result"
+
 // Mapper converts JSONL entries to spans.
 type Mapper struct {
-	filePath string
-	lineNum  int
+	filePath  string
+	lineNum   int
+	toolNames map[string]string // tool_use ID -> tool name, from assistant messages seen so far
+
+	// Set for agent files linked to the Task call that spawned them
+	agentTraceID  string
+	agentParentID string
+
+	// Line of the most recent summary entry (0 = none yet). Entries between
+	// it and the next summary are the ones that summary compacted.
+	lastSummaryLine int
 }
 
 // NewMapper creates a new span mapper for a file.
 func NewMapper(filePath string) *Mapper {
 	return &Mapper{
-		filePath: filePath,
-		lineNum:  0,
+		filePath:  filePath,
+		lineNum:   0,
+		toolNames: make(map[string]string),
 	}
 }
 
 // MapEntry converts a JSONL entry to a span.
+// prevUserTime is the timestamp of the preceding user message (zero if
+// unknown); it marks the start of an assistant generation so the span
+// covers the model's latency.
 // Returns nil if the entry should not produce a span.
-func (m *Mapper) MapEntry(entry *jsonl.RawEntry, lineNum int) (*Span, error) {
+func (m *Mapper) MapEntry(entry *jsonl.RawEntry, lineNum int, prevUserTime time.Time) (*Span, error) {
 	m.lineNum = lineNum
 
+	var span *Span
+	var err error
 	switch entry.Type {
 	case jsonl.EntryTypeUser:
-		return m.mapUserMessage(entry)
+		span, err = m.mapUserMessage(entry)
 	case jsonl.EntryTypeAssistant:
-		return m.mapAssistantMessage(entry)
+		span, err = m.mapAssistantMessage(entry, prevUserTime)
 	case jsonl.EntryTypeSummary:
-		return m.mapSummary(entry)
+		span, err = m.mapSummary(entry)
 	case jsonl.EntryTypeSystem:
-		return m.mapSystemMessage(entry)
+		span, err = m.mapSystemMessage(entry)
 	default:
 		// Skip file-history-snapshot, queue-operation, etc.
 		return nil, nil
 	}
+
+	if span == nil {
+		return nil, err
+	}
+
+	// Agent spans nest under the spawning call in the parent's trace;
+	// other message spans hang off the conversation's root trace span
+	if m.agentParentID != "" {
+		span.TraceID = m.agentTraceID
+		span.ParentID = m.agentParentID
+	} else if span.ParentID == "" {
+		span.ParentID = span.TraceID
+	}
+	return span, err
+}
+
+// SetAgentParent nests the spans of an agent conversation under parentSpanID
+// (the span of the entry that spawned it) within the parent's trace.
+func (m *Mapper) SetAgentParent(traceID, parentSpanID string) {
+	m.agentTraceID = traceID
+	m.agentParentID = parentSpanID
+}
+
+// MapEntrySpans converts a JSONL entry to its message span followed by one
+// span per tool_result block it carries. Returns nil if the entry should not
+// produce spans.
+func (m *Mapper) MapEntrySpans(entry *jsonl.RawEntry, lineNum int, prevUserTime time.Time) ([]*Span, error) {
+	span, err := m.MapEntry(entry, lineNum, prevUserTime)
+	if err != nil || span == nil {
+		return nil, err
+	}
+
+	spans := []*Span{span}
+	if entry.Type == jsonl.EntryTypeUser {
+		spans = append(spans, m.mapToolResults(entry, span)...)
+	}
+	return spans, nil
+}
+
+// MapTrace creates the root trace span for a conversation.
+// Its ID equals the trace ID so message spans can reference it as their parent.
+func (m *Mapper) MapTrace(sessionID string, start, end time.Time) *Span {
+	return &Span{
+		ID:         sessionID,
+		TraceID:    sessionID,
+		Kind:       SpanKindTrace,
+		Name:       "conversation",
+		StartTime:  start,
+		EndTime:    end,
+		SourceFile: m.filePath,
+		Metadata: map[string]interface{}{
+			"session_id": sessionID,
+		},
+	}
+}
+
+// TraceHash returns the deduplication hash for a conversation's trace span.
+func TraceHash(traceID string) string {
+	h := sha256.New()
+	h.Write([]byte("trace"))
+	h.Write([]byte(traceID))
+	return hex.EncodeToString(h.Sum(nil))[:32]
 }
 
 // mapUserMessage maps a user message entry to a span.
@@ -73,8 +153,51 @@ func (m *Mapper) mapUserMessage(entry *jsonl.RawEntry) (*Span, error) {
 	}, nil
 }
 
+// mapToolResults maps the tool_result blocks of a user message to spans.
+// Each span is a child of the user message span and carries the tool_use ID
+// so it can be matched with the assistant's tool call.
+func (m *Mapper) mapToolResults(entry *jsonl.RawEntry, parent *Span) []*Span {
+	msg, err := jsonl.ParseMessage(entry)
+	if err != nil || msg == nil {
+		return nil
+	}
+
+	var spans []*Span
+	for _, result := range jsonl.ExtractToolResults(msg) {
+		spans = append(spans, &Span{
+			ID:         m.toolResultSpanID(result.ToolUseID),
+			TraceID:    parent.TraceID,
+			ParentID:   parent.ID,
+			Kind:       SpanKindSpan,
+			Name:       ToolResultSpanName,
+			StartTime:  parent.StartTime,
+			EndTime:    parent.EndTime,
+			Output:     result.Content,
+			ToolName:   m.toolNames[result.ToolUseID],
+			ToolResult: result.Content,
+			IsError:    result.IsError,
+			SourceFile: m.filePath,
+			SourceLine: m.lineNum,
+			Metadata: map[string]interface{}{
+				"tool_use_id": result.ToolUseID,
+			},
+		})
+	}
+	return spans
+}
+
+// toolResultSpanID derives a stable span ID from a tool_use ID.
+func (m *Mapper) toolResultSpanID(toolUseID string) string {
+	h := sha256.New()
+	h.Write([]byte(m.filePath))
+	h.Write([]byte("tool_result"))
+	h.Write([]byte(toolUseID))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
 // mapAssistantMessage maps an assistant message to a generation span.
-func (m *Mapper) mapAssistantMessage(entry *jsonl.RawEntry) (*Span, error) {
+// The span starts at prevUserTime when it precedes the assistant timestamp.
+func (m *Mapper) mapAssistantMessage(entry *jsonl.RawEntry, prevUserTime time.Time) (*Span, error) {
 	msg, err := jsonl.ParseMessage(entry)
 	if err != nil {
 		return nil, fmt.Errorf("parsing assistant message: %w", err)
@@ -86,13 +209,17 @@ func (m *Mapper) mapAssistantMessage(entry *jsonl.RawEntry) (*Span, error) {
 		model = msg.Model
 	}
 	timestamp := m.parseTimestamp(entry.Timestamp)
+	start := timestamp
+	if !prevUserTime.IsZero() && prevUserTime.Before(timestamp) {
+		start = prevUserTime
+	}
 
 	span := &Span{
 		ID:         m.generateSpanID(entry),
 		TraceID:    entry.SessionID,
 		Kind:       SpanKindGeneration,
 		Name:       "assistant-generation",
-		StartTime:  timestamp,
+		StartTime:  start,
 		EndTime:    timestamp,
 		Output:     text,
 		Model:      model,
@@ -111,6 +238,8 @@ func (m *Mapper) mapAssistantMessage(entry *jsonl.RawEntry) (*Span, error) {
 		if tools := jsonl.ExtractToolCalls(msg); len(tools) > 0 {
 			span.Metadata["tool_calls"] = tools
 		}
+
+		m.recordToolNames(msg)
 	}
 
 	if entry.UUID != "" {
@@ -120,10 +249,45 @@ func (m *Mapper) mapAssistantMessage(entry *jsonl.RawEntry) (*Span, error) {
 	return span, nil
 }
 
+// recordToolNames remembers the names of the tools msg calls so later
+// tool_result spans can be labeled.
+func (m *Mapper) recordToolNames(msg *jsonl.Message) {
+	for _, call := range jsonl.ExtractToolCallDetails(msg) {
+		m.toolNames[call.ID] = call.Name
+	}
+}
+
+// Replay restores the state carried between entries from an entry an
+// earlier sync already mapped at lineNum, without mapping it again.
+// Incremental syncs replay the entries before their resume offset so tool
+// results past it can still be labeled with the calls before it, and the
+// next summary's compacted range starts after the last summary before it.
+func (m *Mapper) Replay(entry *jsonl.RawEntry, lineNum int) {
+	switch entry.Type {
+	case jsonl.EntryTypeSummary:
+		m.lastSummaryLine = lineNum
+	case jsonl.EntryTypeAssistant:
+		if msg, err := jsonl.ParseMessage(entry); err == nil && msg != nil {
+			m.recordToolNames(msg)
+		}
+	}
+}
+
 // mapSummary maps a summary entry to a span.
+// The span is marked as a compaction boundary, with the range of source lines
+// since the previous summary that it replaced.
 func (m *Mapper) mapSummary(entry *jsonl.RawEntry) (*Span, error) {
 	timestamp := m.parseTimestamp(entry.Timestamp)
 
+	metadata := map[string]interface{}{
+		"compaction": true,
+	}
+	if from, to := m.lastSummaryLine+1, m.lineNum-1; from <= to {
+		metadata["compacted_from_line"] = from
+		metadata["compacted_to_line"] = to
+	}
+	m.lastSummaryLine = m.lineNum
+
 	return &Span{
 		ID:         m.generateSpanID(entry),
 		TraceID:    entry.SessionID,
@@ -132,6 +296,7 @@ func (m *Mapper) mapSummary(entry *jsonl.RawEntry) (*Span, error) {
 		StartTime:  timestamp,
 		EndTime:    timestamp,
 		Output:     entry.Summary,
+		Metadata:   metadata,
 		SourceFile: m.filePath,
 		SourceLine: m.lineNum,
 	}, nil
@@ -192,6 +357,18 @@ func (m *Mapper) parseTimestamp(ts string) time.Time {
 	return t
 }
 
+// SpanHash returns the deduplication hash for a span mapped from entry.
+// Tool result spans share their entry, so they are keyed by span ID instead.
+func SpanHash(entry *jsonl.RawEntry, span *Span) string {
+	if span.Name != ToolResultSpanName {
+		return GenerateMessageHash(entry)
+	}
+	h := sha256.New()
+	h.Write([]byte(ToolResultSpanName))
+	h.Write([]byte(span.ID))
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
 // GenerateMessageHash creates a hash for deduplication.
 func GenerateMessageHash(entry *jsonl.RawEntry) string {
 	h := sha256.New()
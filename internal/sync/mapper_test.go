@@ -2,6 +2,7 @@ package sync
 
 import (
 	"testing"
+	"time"
 
 	"github.com/dmora/ch/internal/jsonl"
 )
@@ -15,7 +16,7 @@ func TestMapperUserMessage(t *testing.T) {
 		Timestamp: "2025-01-01T12:00:00Z",
 	}
 
-	span, err := mapper.MapEntry(entry, 1)
+	span, err := mapper.MapEntry(entry, 1, time.Time{})
 	if err != nil {
 		t.Fatalf("MapEntry failed: %v", err)
 	}
@@ -32,6 +33,9 @@ func TestMapperUserMessage(t *testing.T) {
 	if span.TraceID != "session-123" {
 		t.Errorf("TraceID = %s, want session-123", span.TraceID)
 	}
+	if span.ParentID != "session-123" {
+		t.Errorf("ParentID = %s, want session-123 (root trace)", span.ParentID)
+	}
 	if span.SourceFile != "/test/file.jsonl" {
 		t.Errorf("SourceFile = %s, want /test/file.jsonl", span.SourceFile)
 	}
@@ -49,7 +53,7 @@ func TestMapperAssistantMessage(t *testing.T) {
 		Timestamp: "2025-01-01T12:00:00Z",
 	}
 
-	span, err := mapper.MapEntry(entry, 2)
+	span, err := mapper.MapEntry(entry, 2, time.Time{})
 	if err != nil {
 		t.Fatalf("MapEntry failed: %v", err)
 	}
@@ -65,6 +69,37 @@ func TestMapperAssistantMessage(t *testing.T) {
 	}
 }
 
+func TestMapperGenerationDuration(t *testing.T) {
+	mapper := NewMapper("/test/file.jsonl")
+
+	entry := &jsonl.RawEntry{
+		Type:      "assistant",
+		SessionID: "session-456",
+		Timestamp: "2025-01-01T12:00:05Z",
+	}
+	prevUserTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	span, err := mapper.MapEntry(entry, 2, prevUserTime)
+	if err != nil {
+		t.Fatalf("MapEntry failed: %v", err)
+	}
+	if !span.StartTime.Equal(prevUserTime) {
+		t.Errorf("StartTime = %v, want %v", span.StartTime, prevUserTime)
+	}
+	if got := span.EndTime.Sub(span.StartTime); got != 5*time.Second {
+		t.Errorf("duration = %v, want 5s", got)
+	}
+
+	// Without a preceding user message the generation has no duration
+	span, err = mapper.MapEntry(entry, 3, time.Time{})
+	if err != nil {
+		t.Fatalf("MapEntry failed: %v", err)
+	}
+	if !span.StartTime.Equal(span.EndTime) {
+		t.Errorf("StartTime = %v, want EndTime %v", span.StartTime, span.EndTime)
+	}
+}
+
 func TestMapperSystemMessage(t *testing.T) {
 	mapper := NewMapper("/test/file.jsonl")
 
@@ -74,7 +109,7 @@ func TestMapperSystemMessage(t *testing.T) {
 		Timestamp: "2025-01-01T12:00:00Z",
 	}
 
-	span, err := mapper.MapEntry(entry, 3)
+	span, err := mapper.MapEntry(entry, 3, time.Time{})
 	if err != nil {
 		t.Fatalf("MapEntry failed: %v", err)
 	}
@@ -99,7 +134,7 @@ func TestMapperSummary(t *testing.T) {
 		Timestamp: "2025-01-01T12:00:00Z",
 	}
 
-	span, err := mapper.MapEntry(entry, 4)
+	span, err := mapper.MapEntry(entry, 4, time.Time{})
 	if err != nil {
 		t.Fatalf("MapEntry failed: %v", err)
 	}
@@ -113,6 +148,21 @@ func TestMapperSummary(t *testing.T) {
 	if span.Name != "context-summary" {
 		t.Errorf("Name = %s, want context-summary", span.Name)
 	}
+	if span.Metadata["compaction"] != true {
+		t.Errorf("compaction = %v, want true", span.Metadata["compaction"])
+	}
+	if span.Metadata["compacted_from_line"] != 1 || span.Metadata["compacted_to_line"] != 3 {
+		t.Errorf("compacted lines = %v-%v, want 1-3", span.Metadata["compacted_from_line"], span.Metadata["compacted_to_line"])
+	}
+
+	// A second summary covers only the lines since the first one
+	span, err = mapper.MapEntry(entry, 9, time.Time{})
+	if err != nil {
+		t.Fatalf("MapEntry failed: %v", err)
+	}
+	if span.Metadata["compacted_from_line"] != 5 || span.Metadata["compacted_to_line"] != 8 {
+		t.Errorf("compacted lines = %v-%v, want 5-8", span.Metadata["compacted_from_line"], span.Metadata["compacted_to_line"])
+	}
 }
 
 func TestMapperUnknownType(t *testing.T) {
@@ -124,7 +174,7 @@ func TestMapperUnknownType(t *testing.T) {
 		Timestamp: "2025-01-01T12:00:00Z",
 	}
 
-	span, err := mapper.MapEntry(entry, 5)
+	span, err := mapper.MapEntry(entry, 5, time.Time{})
 	if err != nil {
 		t.Fatalf("MapEntry failed: %v", err)
 	}
@@ -134,6 +184,31 @@ func TestMapperUnknownType(t *testing.T) {
 	}
 }
 
+func TestMapperTrace(t *testing.T) {
+	mapper := NewMapper("/test/file.jsonl")
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Minute)
+
+	span := mapper.MapTrace("session-123", start, end)
+
+	if span.Kind != SpanKindTrace {
+		t.Errorf("Kind = %s, want trace", span.Kind)
+	}
+	if span.ID != "session-123" || span.TraceID != "session-123" {
+		t.Errorf("ID/TraceID = %s/%s, want session-123", span.ID, span.TraceID)
+	}
+	if span.ParentID != "" {
+		t.Errorf("ParentID = %s, want empty for root span", span.ParentID)
+	}
+	if !span.StartTime.Equal(start) || !span.EndTime.Equal(end) {
+		t.Errorf("time range = %v-%v, want %v-%v", span.StartTime, span.EndTime, start, end)
+	}
+
+	if TraceHash("session-123") == TraceHash("session-456") {
+		t.Error("TraceHash should differ per trace")
+	}
+}
+
 func TestGenerateMessageHash(t *testing.T) {
 	entry1 := &jsonl.RawEntry{
 		Type:      "user",
@@ -181,16 +256,116 @@ func TestSpanIDDeterminism(t *testing.T) {
 		Timestamp: "2025-01-01T12:00:00Z",
 	}
 
-	span1, _ := mapper.MapEntry(entry, 1)
-	span2, _ := mapper.MapEntry(entry, 1)
+	span1, _ := mapper.MapEntry(entry, 1, time.Time{})
+	span2, _ := mapper.MapEntry(entry, 1, time.Time{})
 
 	if span1.ID != span2.ID {
 		t.Error("Same entry should produce same span ID")
 	}
 
 	// Different line number should produce different ID
-	span3, _ := mapper.MapEntry(entry, 2)
+	span3, _ := mapper.MapEntry(entry, 2, time.Time{})
 	if span1.ID == span3.ID {
 		t.Error("Different line numbers should produce different span IDs")
 	}
 }
+
+func TestMapperToolResults(t *testing.T) {
+	mapper := NewMapper("/test/file.jsonl")
+
+	call := &jsonl.RawEntry{
+		Type:      "assistant",
+		UUID:      "a1",
+		SessionID: "session-789",
+		Timestamp: "2025-01-01T12:00:00Z",
+		Message:   []byte(`{"role":"assistant","content":[{"type":"tool_use","id":"toolu_ok","name":"Read","input":{}},{"type":"tool_use","id":"toolu_err","name":"Bash","input":{}}]}`),
+	}
+	if _, err := mapper.MapEntrySpans(call, 1, time.Time{}); err != nil {
+		t.Fatalf("MapEntrySpans failed: %v", err)
+	}
+
+	result := &jsonl.RawEntry{
+		Type:      "user",
+		UUID:      "u1",
+		SessionID: "session-789",
+		Timestamp: "2025-01-01T12:00:01Z",
+		Message:   []byte(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_ok","content":"file contents"},{"type":"tool_result","tool_use_id":"toolu_err","content":"command not found","is_error":true}]}`),
+	}
+	spans, err := mapper.MapEntrySpans(result, 2, time.Time{})
+	if err != nil {
+		t.Fatalf("MapEntrySpans failed: %v", err)
+	}
+	if len(spans) != 3 {
+		t.Fatalf("len(spans) = %d, want 3 (user message + 2 tool results)", len(spans))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		span := spans[1]
+		if span.Name != ToolResultSpanName {
+			t.Errorf("Name = %s, want %s", span.Name, ToolResultSpanName)
+		}
+		if span.ParentID != "u1" {
+			t.Errorf("ParentID = %s, want u1 (user message span)", span.ParentID)
+		}
+		if span.ToolName != "Read" {
+			t.Errorf("ToolName = %s, want Read", span.ToolName)
+		}
+		if span.ToolResult != "file contents" {
+			t.Errorf("ToolResult = %q, want %q", span.ToolResult, "file contents")
+		}
+		if span.IsError {
+			t.Error("IsError = true, want false")
+		}
+		if span.Metadata["tool_use_id"] != "toolu_ok" {
+			t.Errorf("tool_use_id = %v, want toolu_ok", span.Metadata["tool_use_id"])
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		span := spans[2]
+		if span.ToolName != "Bash" {
+			t.Errorf("ToolName = %s, want Bash", span.ToolName)
+		}
+		if !span.IsError {
+			t.Error("IsError = false, want true")
+		}
+		if span.ToolResult != "command not found" {
+			t.Errorf("ToolResult = %q, want %q", span.ToolResult, "command not found")
+		}
+	})
+
+	t.Run("distinct hashes", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for _, span := range spans {
+			hash := SpanHash(result, span)
+			if seen[hash] {
+				t.Errorf("duplicate hash for span %s", span.ID)
+			}
+			seen[hash] = true
+		}
+	})
+}
+
+func TestMapperAgentParent(t *testing.T) {
+	mapper := NewMapper("/test/agent-abc.jsonl")
+	mapper.SetAgentParent("parent-session", "spawn-uuid")
+
+	entry := &jsonl.RawEntry{
+		Type:      "user",
+		UUID:      "a1",
+		SessionID: "parent-session",
+		Timestamp: "2025-01-01T12:00:00Z",
+		Message:   []byte(`{"role":"user","content":"explore"}`),
+	}
+
+	span, err := mapper.MapEntry(entry, 1, time.Time{})
+	if err != nil {
+		t.Fatalf("MapEntry failed: %v", err)
+	}
+	if span.TraceID != "parent-session" {
+		t.Errorf("TraceID = %s, want parent-session", span.TraceID)
+	}
+	if span.ParentID != "spawn-uuid" {
+		t.Errorf("ParentID = %s, want spawn-uuid (spawning entry)", span.ParentID)
+	}
+}
@@ -0,0 +1,91 @@
+package sync
+
+import (
+	"context"
+	"sort"
+	gosync "sync"
+)
+
+// PreviewSampleSize is the number of span names kept per file in a preview.
+const PreviewSampleSize = 5
+
+// FilePreview summarizes the spans that would be sent for one file.
+type FilePreview struct {
+	Path        string   `json:"path"`
+	Spans       int      `json:"spans"`
+	SampleNames []string `json:"sample_names"`
+}
+
+// PreviewBackend counts spans per file instead of sending them anywhere.
+// It is used for dry runs so no real backend is contacted.
+type PreviewBackend struct {
+	mu    gosync.Mutex
+	files map[string]*FilePreview
+}
+
+// NewPreviewBackend creates a new preview backend.
+func NewPreviewBackend() *PreviewBackend {
+	return &PreviewBackend{files: make(map[string]*FilePreview)}
+}
+
+// Name returns "preview".
+func (p *PreviewBackend) Name() string {
+	return "preview"
+}
+
+// SendSpan records the span in the per-file preview.
+func (p *PreviewBackend) SendSpan(ctx context.Context, span *Span) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fp, ok := p.files[span.SourceFile]
+	if !ok {
+		fp = &FilePreview{Path: span.SourceFile}
+		p.files[span.SourceFile] = fp
+	}
+	fp.Spans++
+	if len(fp.SampleNames) < PreviewSampleSize {
+		fp.SampleNames = append(fp.SampleNames, span.Name)
+	}
+	return nil
+}
+
+// PrefersBatch returns false; spans are counted as they arrive.
+func (p *PreviewBackend) PrefersBatch() bool {
+	return false
+}
+
+// SendBatch records every span in the batch.
+func (p *PreviewBackend) SendBatch(ctx context.Context, batch *SpanBatch) error {
+	for _, span := range batch.Spans {
+		if err := p.SendSpan(ctx, span); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush is a no-op for the preview backend.
+func (p *PreviewBackend) Flush(ctx context.Context) error {
+	return nil
+}
+
+// Close is a no-op for the preview backend.
+func (p *PreviewBackend) Close() error {
+	return nil
+}
+
+// Files returns the per-file previews sorted by path.
+func (p *PreviewBackend) Files() []*FilePreview {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	files := make([]*FilePreview, 0, len(p.files))
+	for _, fp := range p.files {
+		files = append(files, fp)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
@@ -0,0 +1,31 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestPreviewBackend(t *testing.T) {
+	p := NewPreviewBackend()
+	ctx := context.Background()
+
+	for i := 0; i < PreviewSampleSize+2; i++ {
+		p.SendSpan(ctx, &Span{SourceFile: "/b.jsonl", Name: fmt.Sprintf("span-%d", i)})
+	}
+	p.SendBatch(ctx, &SpanBatch{Spans: []*Span{{SourceFile: "/a.jsonl", Name: "user-message"}}})
+
+	files := p.Files()
+	if len(files) != 2 {
+		t.Fatalf("Files() returned %d files, want 2", len(files))
+	}
+	if files[0].Path != "/a.jsonl" || files[0].Spans != 1 {
+		t.Errorf("files[0] = %+v, want /a.jsonl with 1 span", files[0])
+	}
+	if files[1].Spans != PreviewSampleSize+2 {
+		t.Errorf("files[1].Spans = %d, want %d", files[1].Spans, PreviewSampleSize+2)
+	}
+	if len(files[1].SampleNames) != PreviewSampleSize {
+		t.Errorf("len(SampleNames) = %d, want %d", len(files[1].SampleNames), PreviewSampleSize)
+	}
+}
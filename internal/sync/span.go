@@ -67,12 +67,19 @@ type Backend interface {
 	// Name returns the backend identifier.
 	Name() string
 
-	// SendSpan sends a single span to the backend.
+	// SendSpan sends a single span to the backend. The root trace span
+	// is resent with the same ID when a conversation grows, and should
+	// replace the earlier one.
 	SendSpan(ctx context.Context, span *Span) error
 
 	// SendBatch sends a batch of spans to the backend.
 	SendBatch(ctx context.Context, batch *SpanBatch) error
 
+	// PrefersBatch reports whether the syncer should collect each
+	// conversation's spans into one SendBatch call instead of calling
+	// SendSpan per span.
+	PrefersBatch() bool
+
 	// Flush ensures all pending spans are sent.
 	Flush(ctx context.Context) error
 
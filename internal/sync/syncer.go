@@ -2,6 +2,8 @@ package sync
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/dmora/ch/internal/history"
 	"github.com/dmora/ch/internal/jsonl"
+	"github.com/dmora/ch/internal/parallel"
 	"github.com/dmora/ch/internal/syncdb"
 )
 
@@ -21,6 +24,10 @@ type Syncer struct {
 	projectsDir string
 	workers     int
 	dryRun      bool
+	since       time.Duration   // Only sync files modified within this window
+	checksum    bool            // Detect changes by content hash instead of mtime
+	preview     *PreviewBackend // Replaces the backend during dry runs
+	progress    parallel.ProgressFunc
 }
 
 // shouldRecord returns true if database operations should be performed.
@@ -28,6 +35,15 @@ func (s *Syncer) shouldRecord() bool {
 	return !s.dryRun && s.db != nil
 }
 
+// Preview returns the spans that a dry run would have sent, grouped by file.
+// Returns nil if the syncer is not in dry-run mode.
+func (s *Syncer) Preview() []*FilePreview {
+	if s.preview == nil {
+		return nil
+	}
+	return s.preview.Files()
+}
+
 // SyncerOptions configures the syncer.
 type SyncerOptions struct {
 	DBPath      string
@@ -35,12 +51,23 @@ type SyncerOptions struct {
 	ProjectsDir string
 	Workers     int
 	DryRun      bool
+	Since       time.Duration         // Only sync files modified within this window (0 = all files)
+	Checksum    bool                  // Detect changes by content hash instead of mtime
+	Progress    parallel.ProgressFunc // Called as each file is synced by SyncAll (nil = no reporting)
 }
 
 // NewSyncer creates a new syncer.
+// In dry-run mode the configured backend is ignored and spans are only
+// counted, so no remote backend is ever contacted.
 func NewSyncer(opts SyncerOptions) (*Syncer, error) {
 	if opts.Workers <= 0 {
-		opts.Workers = 4
+		opts.Workers = parallel.DefaultWorkers()
+	}
+
+	var preview *PreviewBackend
+	if opts.DryRun {
+		preview = NewPreviewBackend()
+		opts.Backend = preview
 	}
 
 	var db *syncdb.DB
@@ -59,6 +86,10 @@ func NewSyncer(opts SyncerOptions) (*Syncer, error) {
 		projectsDir: opts.ProjectsDir,
 		workers:     opts.Workers,
 		dryRun:      opts.DryRun,
+		since:       opts.Since,
+		checksum:    opts.Checksum,
+		preview:     preview,
+		progress:    opts.Progress,
 	}, nil
 }
 
@@ -75,11 +106,27 @@ func (s *Syncer) Close() error {
 
 // SyncResult holds the result of a sync operation.
 type SyncResult struct {
-	FilesScanned int
-	FilesUpdated int
-	SpansSynced  int
-	Errors       []error
-	Duration     time.Duration
+	FilesScanned  int
+	FilesUpdated  int
+	FilesResynced int      // Files fully resynced because compaction was detected
+	ResyncedFiles []string // Paths of the resynced files
+	SpansSynced   int
+	Errors        []error // *FileError for per-file failures
+	Duration      time.Duration
+}
+
+// FileError is a failure to sync a single file.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
 }
 
 // SyncAll syncs all conversation files.
@@ -96,10 +143,9 @@ func (s *Syncer) SyncAll(ctx context.Context) (*SyncResult, error) {
 
 	// Process files with worker pool
 	type workItem struct {
-		path    string
-		err     error
-		spans   int
-		updated bool
+		path string
+		err  error
+		fileResult
 	}
 
 	fileChan := make(chan string, len(files))
@@ -111,8 +157,12 @@ func (s *Syncer) SyncAll(ctx context.Context) (*SyncResult, error) {
 		go func() {
 			defer wg.Done()
 			for path := range fileChan {
-				spans, updated, err := s.syncFile(ctx, path)
-				resultChan <- workItem{path: path, spans: spans, updated: updated, err: err}
+				// Once interrupted, drain the queue without starting new files
+				if ctx.Err() != nil {
+					continue
+				}
+				res, err := s.syncFile(ctx, path)
+				resultChan <- workItem{path: path, err: err, fileResult: res}
 			}
 		}()
 	}
@@ -129,25 +179,65 @@ func (s *Syncer) SyncAll(ctx context.Context) (*SyncResult, error) {
 		close(resultChan)
 	}()
 
+	progress := parallel.NewTracker(len(files), s.progress)
 	for item := range resultChan {
+		progress.Done()
 		if item.err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", item.path, item.err))
+			result.Errors = append(result.Errors, &FileError{Path: item.path, Err: item.err})
 		} else {
 			result.SpansSynced += item.spans
 			if item.updated {
 				result.FilesUpdated++
 			}
 		}
+		if item.resynced {
+			result.FilesResynced++
+			result.ResyncedFiles = append(result.ResyncedFiles, item.path)
+		}
 	}
 
 	result.Duration = time.Since(start)
+
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	if s.shouldRecord() {
+		if err := s.db.RecordRun(s.runSummary(start, result)); err != nil {
+			return result, fmt.Errorf("recording run: %w", err)
+		}
+	}
+
 	return result, nil
 }
 
+// runSummary converts a sync result into a persisted run summary.
+func (s *Syncer) runSummary(start time.Time, result *SyncResult) *syncdb.RunSummary {
+	run := &syncdb.RunSummary{
+		StartedAt:    start.Unix(),
+		FinishedAt:   start.Add(result.Duration).Unix(),
+		FilesScanned: result.FilesScanned,
+		FilesUpdated: result.FilesUpdated,
+		SpansSynced:  result.SpansSynced,
+		Backend:      s.backend.Name(),
+	}
+	for _, err := range result.Errors {
+		run.Errors = append(run.Errors, err.Error())
+	}
+	return run
+}
+
 // SyncFile syncs a single file.
 func (s *Syncer) SyncFile(ctx context.Context, path string) (int, error) {
-	spans, _, err := s.syncFile(ctx, path)
-	return spans, err
+	res, err := s.syncFile(ctx, path)
+	return res.spans, err
+}
+
+// fileResult holds the outcome of syncing a single file.
+type fileResult struct {
+	spans    int  // Spans sent
+	updated  bool // File had new spans
+	resynced bool // File was fully resynced after compaction
 }
 
 // syncStrategy holds the determined sync approach for a file.
@@ -155,10 +245,12 @@ type syncStrategy struct {
 	offset      int64
 	lineNum     int
 	needsResync bool
+	compacted   bool // File shrank since the last sync
 }
 
 // determineSyncStrategy decides how to sync a file based on its state.
-func (s *Syncer) determineSyncStrategy(path string, currentSize, currentMtime int64) (*syncStrategy, error) {
+// With checksum change detection, currentHash replaces the mtime comparison.
+func (s *Syncer) determineSyncStrategy(path string, currentSize, currentMtime int64, currentHash string) (*syncStrategy, error) {
 	strategy := &syncStrategy{}
 
 	if !s.shouldRecord() {
@@ -181,95 +273,200 @@ func (s *Syncer) determineSyncStrategy(path string, currentSize, currentMtime in
 	if currentSize < state.LastSize {
 		// File shrunk: compaction detected, full resync
 		strategy.needsResync = true
+		strategy.compacted = true
 		s.db.ClearFileMessages(path)
 		s.db.DeleteState(path)
 		return strategy, nil
 	}
 
-	if currentMtime == state.LastMtime && currentSize == state.LastSize {
+	unchanged := currentMtime == state.LastMtime
+	if s.checksum {
+		unchanged = currentHash == state.ContentHash
+	}
+	if unchanged && currentSize == state.LastSize {
 		// No changes
 		return nil, nil
 	}
 
+	if jsonl.IsCompressed(path) {
+		// Compressed files can't be resumed at a byte offset: reread them in
+		// full and let span deduplication skip what was already sent
+		strategy.needsResync = true
+		return strategy, nil
+	}
+
 	// Incremental sync from last offset
 	strategy.offset = state.LastOffset
 	strategy.lineNum = state.MessageCount
 	return strategy, nil
 }
 
+// spanSender delivers one file's spans to the backend. When the backend
+// prefers batching, spans are held until flush and sent as a single
+// SpanBatch; otherwise each span is sent as soon as it is queued. Spans are
+// recorded as synced only once the backend has accepted them.
+type spanSender struct {
+	s       *Syncer
+	path    string
+	batch   bool
+	pending []pendingSpan
+}
+
+// pendingSpan is a span waiting to be sent, with its deduplication hash.
+type pendingSpan struct {
+	span *Span
+	hash string
+}
+
+// newSpanSender creates a sender for the spans of the file at path.
+func (s *Syncer) newSpanSender(path string) *spanSender {
+	return &spanSender{s: s, path: path, batch: s.backend.PrefersBatch()}
+}
+
+// send queues a span, sending it immediately unless batching.
+func (o *spanSender) send(ctx context.Context, span *Span, hash string) error {
+	if o.batch {
+		o.pending = append(o.pending, pendingSpan{span: span, hash: hash})
+		return nil
+	}
+	if err := o.s.backend.SendSpan(ctx, span); err != nil {
+		return err
+	}
+	o.record(span, hash)
+	return nil
+}
+
+// flush sends the queued spans as one batch for the conversation sessionID.
+func (o *spanSender) flush(ctx context.Context, sessionID string) error {
+	if len(o.pending) == 0 {
+		return nil
+	}
+	batch := &SpanBatch{
+		TraceID:   o.pending[0].span.TraceID,
+		SessionID: sessionID,
+		Project:   history.DecodeProjectPath(filepath.Base(filepath.Dir(o.path))),
+		Spans:     make([]*Span, len(o.pending)),
+		CreatedAt: time.Now(),
+	}
+	for i, p := range o.pending {
+		batch.Spans[i] = p.span
+	}
+	if err := o.s.backend.SendBatch(ctx, batch); err != nil {
+		return fmt.Errorf("sending batch: %w", err)
+	}
+	for _, p := range o.pending {
+		o.record(p.span, p.hash)
+	}
+	o.pending = nil
+	return nil
+}
+
+// record marks a span as synced in the database.
+func (o *spanSender) record(span *Span, hash string) {
+	if o.s.shouldRecord() {
+		o.s.db.RecordSyncedMessage(o.path, hash, span.ID)
+	}
+}
+
 // processAndSendEntry processes a single entry, checking deduplication and sending to backend.
 // Returns true if the entry was sent (not skipped due to deduplication).
-func (s *Syncer) processAndSendEntry(ctx context.Context, entry *jsonl.RawEntry, span *Span, path string) (bool, error) {
+func (s *Syncer) processAndSendEntry(ctx context.Context, out *spanSender, entry *jsonl.RawEntry, span *Span) (bool, error) {
+	hash := SpanHash(entry, span)
 	if s.shouldRecord() {
-		hash := GenerateMessageHash(entry)
-		synced, _ := s.db.IsSynced(path, hash)
+		synced, _ := s.db.IsSynced(out.path, hash)
 		if synced {
 			return false, nil
 		}
 	}
 
-	if err := s.backend.SendSpan(ctx, span); err != nil {
+	if err := out.send(ctx, span, hash); err != nil {
 		return false, fmt.Errorf("sending span: %w", err)
 	}
-
-	if s.shouldRecord() {
-		hash := GenerateMessageHash(entry)
-		s.db.RecordSyncedMessage(path, hash, span.ID)
-	}
-
 	return true, nil
 }
 
-// syncFile syncs a single file and returns (spans synced, was updated, error).
-func (s *Syncer) syncFile(ctx context.Context, path string) (int, bool, error) {
+// syncFile syncs a single file.
+func (s *Syncer) syncFile(ctx context.Context, path string) (fileResult, error) {
+	var res fileResult
+
 	info, err := os.Stat(path)
 	if err != nil {
-		return 0, false, fmt.Errorf("stat file: %w", err)
+		return res, fmt.Errorf("stat file: %w", err)
 	}
 
 	currentSize := info.Size()
 	currentMtime := info.ModTime().Unix()
 
-	strategy, err := s.determineSyncStrategy(path, currentSize, currentMtime)
+	var currentHash string
+	if s.checksum && s.shouldRecord() {
+		if currentHash, err = contentHash(path, currentSize); err != nil {
+			return res, fmt.Errorf("hashing file: %w", err)
+		}
+	}
+
+	strategy, err := s.determineSyncStrategy(path, currentSize, currentMtime, currentHash)
 	if err != nil {
-		return 0, false, err
+		return res, err
 	}
 	if strategy == nil {
-		return 0, false, nil // No changes
+		return res, nil // No changes
 	}
+	res.resynced = strategy.compacted
 
-	file, err := os.Open(path)
+	file, err := jsonl.Open(path)
 	if err != nil {
-		return 0, false, fmt.Errorf("opening file: %w", err)
+		return res, fmt.Errorf("opening file: %w", err)
 	}
 	defer file.Close()
 
 	if strategy.offset > 0 {
-		if _, err := file.Seek(strategy.offset, io.SeekStart); err != nil {
-			return 0, false, fmt.Errorf("seeking to offset: %w", err)
+		if _, err := file.(io.Seeker).Seek(strategy.offset, io.SeekStart); err != nil {
+			return res, fmt.Errorf("seeking to offset: %w", err)
 		}
 	}
 
 	spansProcessed, traceID, lineNum, err := s.processEntries(ctx, file, path, strategy.lineNum)
+	res.spans = spansProcessed
+	res.updated = spansProcessed > 0
 	if err != nil {
-		return spansProcessed, spansProcessed > 0, err
+		return res, err
 	}
 
-	if err := s.saveState(file, path, currentSize, currentMtime, traceID, lineNum); err != nil {
-		return spansProcessed, true, err
+	if err := s.saveState(file, path, currentSize, currentMtime, currentHash, traceID, lineNum); err != nil {
+		res.updated = true
+		return res, err
 	}
 
-	return spansProcessed, spansProcessed > 0, nil
+	return res, nil
 }
 
-// processEntries reads and processes all entries from the file.
-func (s *Syncer) processEntries(ctx context.Context, file *os.File, path string, startLineNum int) (int, string, int, error) {
+// processEntries reads and processes all entries from the file. Spans held
+// for a batching backend are flushed before returning, even on error, so
+// everything processed so far is delivered.
+func (s *Syncer) processEntries(ctx context.Context, file io.Reader, path string, startLineNum int) (count int, traceID string, lineNum int, err error) {
+	out := s.newSpanSender(path)
+	defer func() {
+		if ferr := out.flush(ctx, traceID); err == nil {
+			err = ferr
+		}
+	}()
+
 	parser := jsonl.NewParserFromReader(file)
 	mapper := NewMapper(path)
 
-	lineNum := startLineNum
+	// Agents share the parent session's ID, so their spans land in the
+	// parent's trace whether or not the spawning call is found
+	if traceID, parentID, ok := resolveAgentParent(path); ok {
+		mapper.SetAgentParent(traceID, parentID)
+	}
+	if startLineNum > 0 {
+		replayEntries(mapper, path, startLineNum)
+	}
+	agent := history.IsAgentFile(filepath.Base(path))
+
+	lineNum = startLineNum
 	spansProcessed := 0
-	var traceID string
+	var prevUserTime time.Time // timestamp of the last user message, for generation start times
 
 	for {
 		entry, err := parser.Next()
@@ -283,38 +480,185 @@ func (s *Syncer) processEntries(ctx context.Context, file *os.File, path string,
 
 		if traceID == "" && entry.SessionID != "" {
 			traceID = entry.SessionID
+
+			// Only the parent emits the root trace span; an agent's would
+			// reuse the parent session's ID and overwrite it. Incremental syncs
+			// resend it so its end time follows the conversation
+			if !agent && !entry.IsSidechain {
+				sent, err := s.sendTraceSpan(ctx, out, mapper, entry, startLineNum > 0)
+				if err != nil {
+					return spansProcessed, traceID, lineNum, err
+				}
+				if sent {
+					spansProcessed++
+				}
+			}
 		}
 
-		span, err := mapper.MapEntry(entry, lineNum)
+		spans, err := mapper.MapEntrySpans(entry, lineNum, prevUserTime)
+		if entry.Type == jsonl.EntryTypeUser {
+			if ts, perr := time.Parse(time.RFC3339Nano, entry.Timestamp); perr == nil {
+				prevUserTime = ts
+			}
+		}
 		if err != nil {
 			if s.db != nil {
 				s.db.RecordError(path, err.Error())
 			}
 			continue
 		}
-		if span == nil {
-			continue
+
+		for _, span := range spans {
+			sent, err := s.processAndSendEntry(ctx, out, entry, span)
+			if err != nil {
+				return spansProcessed, traceID, lineNum, err
+			}
+			if sent {
+				spansProcessed++
+			}
+		}
+	}
+
+	return spansProcessed, traceID, lineNum, nil
+}
+
+// replayEntries feeds mapper the first n entries of the file at path, the
+// ones synced before an incremental sync's resume offset. It is best effort:
+// an unreadable prefix only loses the state it would have restored.
+func replayEntries(mapper *Mapper, path string, n int) {
+	parser, err := jsonl.NewParser(path)
+	if err != nil {
+		return
+	}
+	defer parser.Close()
+
+	for lineNum := 1; lineNum <= n; lineNum++ {
+		entry, err := parser.Next()
+		if err != nil || entry == nil {
+			return
+		}
+		mapper.Replay(entry, lineNum)
+	}
+}
+
+// resolveAgentParent locates the parent session and spawning entry of an
+// agent conversation, whether named agent-* or classified by its sidechain
+// entries. ok is false for non-agent files and when the parent conversation
+// or its Task call can't be reliably found, in which case the agent syncs on
+// its own.
+func resolveAgentParent(path string) (traceID, parentSpanID string, ok bool) {
+	meta, err := history.ScanConversationMeta(path)
+	if err != nil || !meta.IsAgent || meta.ParentSessionID == "" {
+		return "", "", false
+	}
+
+	parentPath := history.ConversationFilePath(filepath.Dir(path), meta.ParentSessionID)
+	info, err := history.ExtractAgentInfo(parentPath, meta.ID)
+	// An order match is only a guess; better to sync on its own than nest wrongly
+	if err != nil || info == nil || info.SpawnUUID == "" || info.Match == history.AgentMatchOrder {
+		return "", "", false
+	}
+	return meta.ParentSessionID, info.SpawnUUID, true
+}
+
+// sendTraceSpan sends the root trace span for a conversation, spanning its
+// earliest to latest message timestamp. An already synced span is skipped
+// unless update is set, which resends it with the same ID so the backend
+// replaces it. Returns true if the span was sent.
+func (s *Syncer) sendTraceSpan(ctx context.Context, out *spanSender, mapper *Mapper, first *jsonl.RawEntry, update bool) (bool, error) {
+	path := out.path
+	hash := TraceHash(first.SessionID)
+	if !update && s.shouldRecord() {
+		synced, _ := s.db.IsSynced(path, hash)
+		if synced {
+			return false, nil
 		}
+	}
 
-		sent, err := s.processAndSendEntry(ctx, entry, span, path)
+	start := mapper.parseTimestamp(first.Timestamp)
+	end := start
+	if earliest, latest, ok := scanTimeRange(path); ok {
+		if earliest.Before(start) {
+			start = earliest
+		}
+		end = latest
+	}
+
+	span := mapper.MapTrace(first.SessionID, start, end)
+	if err := out.send(ctx, span, hash); err != nil {
+		return false, fmt.Errorf("sending trace span: %w", err)
+	}
+	return true, nil
+}
+
+// scanTimeRange returns the earliest and latest entry timestamps in a file.
+func scanTimeRange(path string) (earliest, latest time.Time, ok bool) {
+	parser, err := jsonl.NewParser(path)
+	if err != nil {
+		return earliest, latest, false
+	}
+	defer parser.Close()
+
+	for {
+		entry, err := parser.Next()
+		if err != nil || entry == nil {
+			break
+		}
+		if entry.Timestamp == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
 		if err != nil {
-			return spansProcessed, traceID, lineNum, err
+			continue
+		}
+		if !ok || t.Before(earliest) {
+			earliest = t
 		}
-		if sent {
-			spansProcessed++
+		if !ok || t.After(latest) {
+			latest = t
 		}
+		ok = true
 	}
+	return earliest, latest, ok
+}
 
-	return spansProcessed, traceID, lineNum, nil
+// hashTailSize is how much of the end of a file contentHash reads. Smaller
+// files are hashed in full.
+const hashTailSize = 1 << 20
+
+// contentHash hashes the last hashTailSize bytes of a file. Conversation
+// files are append-only, so any change that keeps the size the same while
+// the mtime is unreliable shows up at the tail.
+func contentHash(path string, size int64) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if size > hashTailSize {
+		if _, err := file.Seek(size-hashTailSize, io.SeekStart); err != nil {
+			return "", err
+		}
+	}
+	h := sha256.New()
+	if _, err := io.CopyN(h, file, min(size, hashTailSize)); err != nil && err != io.EOF {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
-// saveState persists the sync state to the database.
-func (s *Syncer) saveState(file *os.File, path string, currentSize, currentMtime int64, traceID string, lineNum int) error {
+// saveState persists the sync state to the database. The resume offset is
+// only meaningful for plain files; compressed files are always reread.
+func (s *Syncer) saveState(file io.Reader, path string, currentSize, currentMtime int64, currentHash, traceID string, lineNum int) error {
 	if !s.shouldRecord() {
 		return nil
 	}
 
-	newOffset, _ := file.Seek(0, io.SeekCurrent)
+	var newOffset int64
+	if seeker, ok := file.(io.Seeker); ok {
+		newOffset, _ = seeker.Seek(0, io.SeekCurrent)
+	}
 	newState := &syncdb.SyncState{
 		FilePath:     path,
 		LastOffset:   newOffset,
@@ -324,6 +668,7 @@ func (s *Syncer) saveState(file *os.File, path string, currentSize, currentMtime
 		MessageCount: lineNum,
 		LastSyncAt:   time.Now().Unix(),
 		Backend:      s.backend.Name(),
+		ContentHash:  currentHash,
 	}
 	if err := s.db.SaveState(newState); err != nil {
 		return fmt.Errorf("saving state: %w", err)
@@ -332,9 +677,16 @@ func (s *Syncer) saveState(file *os.File, path string, currentSize, currentMtime
 }
 
 // findFiles finds all JSONL files in the projects directory.
+// When a since window is set, files not modified within it are skipped
+// before any per-file sync state is consulted.
 func (s *Syncer) findFiles() ([]string, error) {
 	var files []string
 
+	var cutoff time.Time
+	if s.since > 0 {
+		cutoff = time.Now().Add(-s.since)
+	}
+
 	entries, err := os.ReadDir(s.projectsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -358,6 +710,12 @@ func (s *Syncer) findFiles() ([]string, error) {
 			if f.IsDir() || !history.IsConversationFile(f.Name()) {
 				continue
 			}
+			if !cutoff.IsZero() {
+				info, err := f.Info()
+				if err != nil || info.ModTime().Before(cutoff) {
+					continue
+				}
+			}
 			files = append(files, filepath.Join(projectDir, f.Name()))
 		}
 	}
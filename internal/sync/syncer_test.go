@@ -0,0 +1,457 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dmora/ch/internal/syncdb"
+)
+
+func TestSyncerFindFilesSince(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	recent := filepath.Join(projectDir, "recent.jsonl")
+	old := filepath.Join(projectDir, "old.jsonl")
+	for _, path := range []string{recent, old} {
+		if err := os.WriteFile(path, []byte(`{"type":"user"}`), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	twoDaysAgo := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(old, twoDaysAgo, twoDaysAgo); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+
+	syncer, err := NewSyncer(SyncerOptions{ProjectsDir: tmpDir, DryRun: true, Since: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("NewSyncer failed: %v", err)
+	}
+	defer syncer.Close()
+
+	files, err := syncer.findFiles()
+	if err != nil {
+		t.Fatalf("findFiles failed: %v", err)
+	}
+	if len(files) != 1 || files[0] != recent {
+		t.Errorf("findFiles() = %v, want [%s]", files, recent)
+	}
+
+	syncer.since = 0
+	files, err = syncer.findFiles()
+	if err != nil {
+		t.Fatalf("findFiles failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("findFiles() without --since returned %d files, want 2", len(files))
+	}
+}
+
+func TestSyncAllProgress(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		content := `{"type":"user","uuid":"` + name + `","timestamp":"2024-01-01T10:00:00Z","message":{"role":"user","content":"hi"}}` + "\n"
+		if err := os.WriteFile(filepath.Join(projectDir, name+".jsonl"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	var calls [][2]int
+	syncer, err := NewSyncer(SyncerOptions{
+		ProjectsDir: tmpDir,
+		DryRun:      true,
+		Progress:    func(done, total int) { calls = append(calls, [2]int{done, total}) },
+	})
+	if err != nil {
+		t.Fatalf("NewSyncer failed: %v", err)
+	}
+	defer syncer.Close()
+
+	if _, err := syncer.SyncAll(context.Background()); err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+	if len(calls) != 3 || calls[2] != [2]int{3, 3} {
+		t.Errorf("progress calls = %v, want 3 ending at 3/3", calls)
+	}
+}
+
+func TestResolveAgentParent(t *testing.T) {
+	projectDir := t.TempDir()
+
+	parent := `{"type":"assistant","uuid":"spawn-1","sessionId":"main-123","message":{"role":"assistant","content":[{"type":"tool_use","id":"toolu_abc123","name":"Task","input":{"prompt":"explore"}}]}}
+{"type":"assistant","uuid":"spawn-2","sessionId":"main-123","message":{"role":"assistant","content":[{"type":"tool_use","id":"toolu_def456","name":"Task","input":{"prompt":"review"}}]}}`
+	agent := `{"type":"user","sessionId":"main-123","isSidechain":true,"message":{"role":"user","content":"explore"}}`
+	// A sidechain agent that keeps a session-style filename
+	sidechain := `{"type":"user","sessionId":"main-123","isSidechain":true,"message":{"role":"user","content":"review"}}`
+	orphan := `{"type":"user","sessionId":"missing-456","isSidechain":true,"message":{"role":"user","content":"hi"}}`
+
+	files := map[string]string{
+		"main-123.jsonl":     parent,
+		"agent-abc123.jsonl": agent,
+		"agent-zzz999.jsonl": orphan,
+		"side-789.jsonl":     sidechain,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	traceID, parentID, ok := resolveAgentParent(filepath.Join(projectDir, "agent-abc123.jsonl"))
+	if !ok || traceID != "main-123" || parentID != "spawn-1" {
+		t.Errorf("resolveAgentParent() = %q, %q, %v; want main-123, spawn-1, true", traceID, parentID, ok)
+	}
+
+	traceID, parentID, ok = resolveAgentParent(filepath.Join(projectDir, "side-789.jsonl"))
+	if !ok || traceID != "main-123" || parentID != "spawn-2" {
+		t.Errorf("resolveAgentParent(sidechain) = %q, %q, %v; want main-123, spawn-2, true", traceID, parentID, ok)
+	}
+
+	if _, _, ok := resolveAgentParent(filepath.Join(projectDir, "agent-zzz999.jsonl")); ok {
+		t.Error("expected unresolved parent for orphan agent")
+	}
+	if _, _, ok := resolveAgentParent(filepath.Join(projectDir, "main-123.jsonl")); ok {
+		t.Error("expected main conversation not to resolve a parent")
+	}
+}
+
+func TestDetermineSyncStrategyChecksum(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "conv.jsonl")
+	if err := os.WriteFile(path, []byte(`{"type":"user"}`+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	db, err := syncdb.Open(filepath.Join(tmpDir, "sync.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	hash, err := contentHash(path, 16)
+	if err != nil {
+		t.Fatalf("contentHash failed: %v", err)
+	}
+	if err := db.SaveState(&syncdb.SyncState{
+		FilePath: path, LastOffset: 16, LastSize: 16, LastMtime: 100, ContentHash: hash, Backend: "preview",
+	}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	syncer := &Syncer{db: db, backend: NewPreviewBackend(), checksum: true}
+
+	// A different mtime alone is not a change
+	strategy, err := syncer.determineSyncStrategy(path, 16, 999, hash)
+	if err != nil {
+		t.Fatalf("determineSyncStrategy failed: %v", err)
+	}
+	if strategy != nil {
+		t.Errorf("expected no change for a matching hash, got %+v", strategy)
+	}
+
+	// A different hash is, even with the same mtime and size
+	strategy, err = syncer.determineSyncStrategy(path, 16, 100, "other")
+	if err != nil {
+		t.Fatalf("determineSyncStrategy failed: %v", err)
+	}
+	if strategy == nil || strategy.offset != 16 {
+		t.Errorf("expected an incremental sync from offset 16, got %+v", strategy)
+	}
+
+	// Without checksums the mtime decides
+	syncer.checksum = false
+	if strategy, _ := syncer.determineSyncStrategy(path, 16, 999, ""); strategy == nil {
+		t.Error("expected a changed mtime to be a change without checksums")
+	}
+}
+
+func TestDetermineSyncStrategyCompressed(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "conv.jsonl.gz")
+
+	db, err := syncdb.Open(filepath.Join(tmpDir, "sync.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.SaveState(&syncdb.SyncState{
+		FilePath: path, LastOffset: 16, LastSize: 16, LastMtime: 100, MessageCount: 1, Backend: "preview",
+	}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	syncer := &Syncer{db: db, backend: NewPreviewBackend()}
+
+	// A grown compressed file is reread from the start, not resumed
+	strategy, err := syncer.determineSyncStrategy(path, 32, 200, "")
+	if err != nil {
+		t.Fatalf("determineSyncStrategy failed: %v", err)
+	}
+	if strategy == nil || strategy.offset != 0 || strategy.lineNum != 0 {
+		t.Errorf("expected a full reread, got %+v", strategy)
+	}
+}
+
+// batchBackend records what it receives, preferring batches.
+type batchBackend struct {
+	*PreviewBackend
+	spans   int
+	batches []*SpanBatch
+}
+
+func (b *batchBackend) PrefersBatch() bool { return true }
+
+func (b *batchBackend) SendSpan(ctx context.Context, span *Span) error {
+	b.spans++
+	return nil
+}
+
+func (b *batchBackend) SendBatch(ctx context.Context, batch *SpanBatch) error {
+	b.batches = append(b.batches, batch)
+	return nil
+}
+
+func TestSyncFileBatchesSpans(t *testing.T) {
+	projectDir := filepath.Join(t.TempDir(), "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	path := filepath.Join(projectDir, "main-123.jsonl")
+	content := `{"type":"user","uuid":"u1","sessionId":"main-123","timestamp":"2024-01-01T10:00:00Z","message":{"role":"user","content":"hi"}}
+{"type":"assistant","uuid":"a1","sessionId":"main-123","timestamp":"2024-01-01T10:00:05Z","message":{"role":"assistant","content":[{"type":"text","text":"hello"}]}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	be := &batchBackend{PreviewBackend: NewPreviewBackend()}
+	syncer := &Syncer{backend: be, projectsDir: filepath.Dir(projectDir)}
+
+	sent, err := syncer.SyncFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("SyncFile failed: %v", err)
+	}
+	if be.spans != 0 {
+		t.Errorf("SendSpan called %d times, want 0 for a batching backend", be.spans)
+	}
+	if len(be.batches) != 1 {
+		t.Fatalf("SendBatch called %d times, want 1", len(be.batches))
+	}
+	batch := be.batches[0]
+	if len(batch.Spans) != sent || sent < 3 {
+		t.Errorf("batch has %d spans, SyncFile reported %d; want trace + 2 messages", len(batch.Spans), sent)
+	}
+	if batch.TraceID != "main-123" || batch.SessionID != "main-123" || batch.Project != "/test/project" {
+		t.Errorf("batch = {TraceID: %q, SessionID: %q, Project: %q}", batch.TraceID, batch.SessionID, batch.Project)
+	}
+}
+
+// traceSpans returns the root trace spans in the batches.
+func traceSpans(batches []*SpanBatch) []*Span {
+	var traces []*Span
+	for _, batch := range batches {
+		for _, span := range batch.Spans {
+			if span.Kind == SpanKindTrace {
+				traces = append(traces, span)
+			}
+		}
+	}
+	return traces
+}
+
+func TestSyncFileUpdatesTraceSpan(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	path := filepath.Join(projectDir, "main-123.jsonl")
+	first := `{"type":"user","uuid":"u1","sessionId":"main-123","timestamp":"2024-01-01T10:00:00Z","message":{"role":"user","content":"hi"}}` + "\n"
+	if err := os.WriteFile(path, []byte(first), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	db, err := syncdb.Open(filepath.Join(tmpDir, "sync.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	be := &batchBackend{PreviewBackend: NewPreviewBackend()}
+	syncer := &Syncer{db: db, backend: be, projectsDir: tmpDir}
+	if _, err := syncer.SyncFile(context.Background(), path); err != nil {
+		t.Fatalf("SyncFile failed: %v", err)
+	}
+
+	appendLines(t, path, `{"type":"user","uuid":"u2","sessionId":"main-123","timestamp":"2024-01-01T11:00:00Z","message":{"role":"user","content":"again"}}`)
+
+	be.batches = nil
+	if _, err := syncer.SyncFile(context.Background(), path); err != nil {
+		t.Fatalf("incremental SyncFile failed: %v", err)
+	}
+	traces := traceSpans(be.batches)
+	if len(traces) != 1 {
+		t.Fatalf("incremental sync sent %d trace spans, want 1", len(traces))
+	}
+	if want := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC); !traces[0].EndTime.Equal(want) {
+		t.Errorf("trace EndTime = %v, want %v", traces[0].EndTime, want)
+	}
+	if !traces[0].StartTime.Equal(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("trace StartTime = %v, want the first message", traces[0].StartTime)
+	}
+}
+
+// appendLines appends lines to the file at path and moves its mtime forward
+// so the next sync sees the change.
+func appendLines(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	for _, line := range lines {
+		file.WriteString(line + "\n")
+	}
+	file.Close()
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+}
+
+// spansNamed returns the spans with the given name in the batches.
+func spansNamed(batches []*SpanBatch, name string) []*Span {
+	var spans []*Span
+	for _, batch := range batches {
+		for _, span := range batch.Spans {
+			if span.Name == name {
+				spans = append(spans, span)
+			}
+		}
+	}
+	return spans
+}
+
+func TestSyncFileIncrementalToolResult(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	path := filepath.Join(projectDir, "main-123.jsonl")
+	call := `{"type":"assistant","uuid":"a1","sessionId":"main-123","timestamp":"2024-01-01T10:00:00Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"Read","input":{}}]}}` + "\n"
+	if err := os.WriteFile(path, []byte(call), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	db, err := syncdb.Open(filepath.Join(tmpDir, "sync.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	be := &batchBackend{PreviewBackend: NewPreviewBackend()}
+	syncer := &Syncer{db: db, backend: be, projectsDir: tmpDir}
+	if _, err := syncer.SyncFile(context.Background(), path); err != nil {
+		t.Fatalf("SyncFile failed: %v", err)
+	}
+
+	// The result arrives after the call has been synced
+	appendLines(t, path, `{"type":"user","uuid":"u1","sessionId":"main-123","timestamp":"2024-01-01T10:00:01Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"file contents"}]}}`)
+
+	be.batches = nil
+	if _, err := syncer.SyncFile(context.Background(), path); err != nil {
+		t.Fatalf("incremental SyncFile failed: %v", err)
+	}
+	results := spansNamed(be.batches, ToolResultSpanName)
+	if len(results) != 1 {
+		t.Fatalf("incremental sync sent %d tool results, want 1", len(results))
+	}
+	if results[0].ToolName != "Read" {
+		t.Errorf("ToolName = %q, want Read from the call synced earlier", results[0].ToolName)
+	}
+}
+
+func TestSyncFileIncrementalSummary(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	path := filepath.Join(projectDir, "main-123.jsonl")
+	user := func(uuid string) string {
+		return `{"type":"user","uuid":"` + uuid + `","sessionId":"main-123","timestamp":"2024-01-01T10:00:00Z","message":{"role":"user","content":"hi"}}`
+	}
+	summary := func(ts string) string {
+		return `{"type":"summary","sessionId":"main-123","timestamp":"` + ts + `","summary":"compacted"}`
+	}
+	if err := os.WriteFile(path, []byte(user("u1")+"\n"+summary("2024-01-01T10:01:00Z")+"\n"+user("u2")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	db, err := syncdb.Open(filepath.Join(tmpDir, "sync.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	be := &batchBackend{PreviewBackend: NewPreviewBackend()}
+	syncer := &Syncer{db: db, backend: be, projectsDir: tmpDir}
+	if _, err := syncer.SyncFile(context.Background(), path); err != nil {
+		t.Fatalf("SyncFile failed: %v", err)
+	}
+
+	// The second summary compacts lines 3-4, since the first on line 2,
+	// though line 3 was synced before the resume offset
+	appendLines(t, path, user("u3"), summary("2024-01-01T10:02:00Z"))
+
+	be.batches = nil
+	if _, err := syncer.SyncFile(context.Background(), path); err != nil {
+		t.Fatalf("incremental SyncFile failed: %v", err)
+	}
+	summaries := spansNamed(be.batches, "context-summary")
+	if len(summaries) != 1 {
+		t.Fatalf("incremental sync sent %d summaries, want 1", len(summaries))
+	}
+	md := summaries[0].Metadata
+	if md["compacted_from_line"] != 3 || md["compacted_to_line"] != 4 {
+		t.Errorf("compacted lines = %v-%v, want 3-4", md["compacted_from_line"], md["compacted_to_line"])
+	}
+}
+
+func TestSyncFileAgentSendsNoTraceSpan(t *testing.T) {
+	projectDir := filepath.Join(t.TempDir(), "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	// No parent conversation, so the agent can't be linked to a spawning call
+	path := filepath.Join(projectDir, "agent-abc123.jsonl")
+	content := `{"type":"user","uuid":"u1","sessionId":"main-123","isSidechain":true,"timestamp":"2024-01-01T10:00:00Z","message":{"role":"user","content":"explore"}}` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	be := &batchBackend{PreviewBackend: NewPreviewBackend()}
+	syncer := &Syncer{backend: be, projectsDir: filepath.Dir(projectDir)}
+	sent, err := syncer.SyncFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("SyncFile failed: %v", err)
+	}
+	if sent == 0 {
+		t.Fatal("SyncFile sent no spans")
+	}
+	if traces := traceSpans(be.batches); len(traces) != 0 {
+		t.Errorf("agent sent %d trace spans with ID %q, want none", len(traces), traces[0].ID)
+	}
+}
@@ -0,0 +1,46 @@
+package syncdb
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Bookmark is the last message read in a conversation.
+type Bookmark struct {
+	ConversationID string
+	MessageUUID    string
+	MessageIndex   int // 1-based message index, for entries without a UUID
+	UpdatedAt      time.Time
+}
+
+// SetBookmark records the last message read in a conversation, replacing any
+// previous bookmark.
+func (d *DB) SetBookmark(conversationID, messageUUID string, messageIndex int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.db.Exec(`
+		INSERT OR REPLACE INTO conversation_bookmarks (conversation_id, message_uuid, message_index, updated_at)
+		VALUES (?, ?, ?, ?)
+	`, conversationID, messageUUID, messageIndex, time.Now().Unix())
+	return err
+}
+
+// GetBookmark returns a conversation's bookmark, or nil if it has none.
+func (d *DB) GetBookmark(conversationID string) (*Bookmark, error) {
+	var b Bookmark
+	var updatedAt int64
+	err := d.db.QueryRow(`
+		SELECT conversation_id, message_uuid, message_index, updated_at
+		FROM conversation_bookmarks
+		WHERE conversation_id = ?
+	`, conversationID).Scan(&b.ConversationID, &b.MessageUUID, &b.MessageIndex, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil // No bookmark yet
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.UpdatedAt = time.Unix(updatedAt, 0)
+	return &b, nil
+}
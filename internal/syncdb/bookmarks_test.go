@@ -0,0 +1,40 @@
+package syncdb
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBookmarks(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	bookmark, err := db.GetBookmark("conv-1")
+	if err != nil {
+		t.Fatalf("GetBookmark failed: %v", err)
+	}
+	if bookmark != nil {
+		t.Errorf("GetBookmark = %+v, want nil before any is set", bookmark)
+	}
+
+	if err := db.SetBookmark("conv-1", "uuid-3", 3); err != nil {
+		t.Fatalf("SetBookmark failed: %v", err)
+	}
+	if err := db.SetBookmark("conv-1", "uuid-7", 7); err != nil {
+		t.Fatalf("SetBookmark failed: %v", err)
+	}
+
+	bookmark, err = db.GetBookmark("conv-1")
+	if err != nil {
+		t.Fatalf("GetBookmark failed: %v", err)
+	}
+	if bookmark == nil || bookmark.MessageUUID != "uuid-7" || bookmark.MessageIndex != 7 {
+		t.Errorf("GetBookmark = %+v, want uuid-7 at 7", bookmark)
+	}
+	if bookmark != nil && bookmark.UpdatedAt.IsZero() {
+		t.Error("expected UpdatedAt to be set")
+	}
+}
@@ -67,7 +67,8 @@ func createTables(db *sql.DB) error {
 		trace_id TEXT,
 		message_count INTEGER DEFAULT 0,
 		last_sync_at INTEGER NOT NULL,
-		backend TEXT NOT NULL
+		backend TEXT NOT NULL,
+		content_hash TEXT
 	);
 
 	CREATE TABLE IF NOT EXISTS synced_messages (
@@ -87,15 +88,90 @@ func createTables(db *sql.DB) error {
 		error_message TEXT NOT NULL,
 		occurred_at INTEGER NOT NULL
 	);
+
+	CREATE TABLE IF NOT EXISTS conversation_tags (
+		conversation_id TEXT NOT NULL,
+		tag TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY (conversation_id, tag)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_conversation_tags_tag
+		ON conversation_tags(tag);
+
+	CREATE TABLE IF NOT EXISTS conversation_bookmarks (
+		conversation_id TEXT PRIMARY KEY,
+		message_uuid TEXT NOT NULL,
+		message_index INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS sync_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		started_at INTEGER NOT NULL,
+		finished_at INTEGER NOT NULL,
+		files_scanned INTEGER NOT NULL,
+		files_updated INTEGER NOT NULL,
+		spans_synced INTEGER NOT NULL,
+		error_count INTEGER NOT NULL,
+		errors TEXT,
+		backend TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS search_index_files (
+		id INTEGER PRIMARY KEY,
+		file_path TEXT NOT NULL UNIQUE,
+		size INTEGER NOT NULL,
+		mtime INTEGER NOT NULL,
+		message_count INTEGER NOT NULL,
+		indexed_at INTEGER NOT NULL
+	);
+
+	CREATE VIRTUAL TABLE IF NOT EXISTS search_index
+		USING fts5(content, tokenize='trigram');
 	`
 
 	_, err := db.Exec(schema)
 	if err != nil {
 		return fmt.Errorf("creating tables: %w", err)
 	}
+	return migrate(db)
+}
+
+// migrate adds columns introduced after a table was first created.
+func migrate(db *sql.DB) error {
+	has, err := hasColumn(db, "sync_state", "content_hash")
+	if err != nil {
+		return fmt.Errorf("migrating database: %w", err)
+	}
+	if !has {
+		if _, err := db.Exec("ALTER TABLE sync_state ADD COLUMN content_hash TEXT"); err != nil {
+			return fmt.Errorf("migrating database: %w", err)
+		}
+	}
 	return nil
 }
 
+// hasColumn reports whether table has the named column.
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
 // Stats holds database statistics.
 type Stats struct {
 	TrackedFiles   int
@@ -1,6 +1,7 @@
 package syncdb
 
 import (
+	"database/sql"
 	"os"
 	"path/filepath"
 	"testing"
@@ -83,6 +84,17 @@ func TestSyncState(t *testing.T) {
 	if state.LastOffset != 2000 {
 		t.Errorf("LastOffset = %d, want 2000", state.LastOffset)
 	}
+	if state.ContentHash != "" {
+		t.Errorf("ContentHash = %q, want empty", state.ContentHash)
+	}
+
+	newState.ContentHash = "abc123"
+	if err := db.SaveState(newState); err != nil {
+		t.Fatalf("SaveState (hash) failed: %v", err)
+	}
+	if state, _ = db.GetState("/path/to/file.jsonl"); state.ContentHash != "abc123" {
+		t.Errorf("ContentHash = %q, want abc123", state.ContentHash)
+	}
 
 	// Delete state
 	if err := db.DeleteState("/path/to/file.jsonl"); err != nil {
@@ -98,6 +110,41 @@ func TestSyncState(t *testing.T) {
 	}
 }
 
+func TestOpenMigratesSyncState(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	// A sync_state table from before content hashes were stored
+	old, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	if _, err := old.Exec(`CREATE TABLE sync_state (
+		file_path TEXT PRIMARY KEY, last_offset INTEGER NOT NULL, last_size INTEGER NOT NULL,
+		last_mtime INTEGER NOT NULL, trace_id TEXT, message_count INTEGER DEFAULT 0,
+		last_sync_at INTEGER NOT NULL, backend TEXT NOT NULL
+	)`); err != nil {
+		t.Fatalf("creating old table failed: %v", err)
+	}
+	if _, err := old.Exec(`INSERT INTO sync_state VALUES ('/a.jsonl', 10, 10, 1, 't', 1, 1, 'console')`); err != nil {
+		t.Fatalf("inserting old row failed: %v", err)
+	}
+	old.Close()
+
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	state, err := db.GetState("/a.jsonl")
+	if err != nil {
+		t.Fatalf("GetState failed: %v", err)
+	}
+	if state == nil || state.LastOffset != 10 || state.ContentHash != "" {
+		t.Errorf("migrated state = %+v, want offset 10 and no hash", state)
+	}
+}
+
 func TestSyncedMessages(t *testing.T) {
 	tmpDir := t.TempDir()
 	db, err := Open(filepath.Join(tmpDir, "test.db"))
@@ -241,3 +288,92 @@ func TestConcurrentWrites(t *testing.T) {
 		t.Errorf("TrackedFiles = %d, want 10", stats.TrackedFiles)
 	}
 }
+
+func TestSyncRuns(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	run, err := db.LastRun()
+	if err != nil {
+		t.Fatalf("LastRun failed: %v", err)
+	}
+	if run != nil {
+		t.Error("Expected nil run before any sync")
+	}
+
+	db.RecordRun(&RunSummary{StartedAt: 100, FinishedAt: 110, FilesScanned: 5, FilesUpdated: 1, SpansSynced: 3, Backend: "console"})
+	db.RecordRun(&RunSummary{StartedAt: 200, FinishedAt: 210, FilesScanned: 5, FilesUpdated: 2, SpansSynced: 7, Errors: []string{"a: bad", "b: worse"}, Backend: "console"})
+
+	run, err = db.LastRun()
+	if err != nil {
+		t.Fatalf("LastRun failed: %v", err)
+	}
+	if run == nil {
+		t.Fatal("Expected non-nil run")
+	}
+	if run.StartedAt != 200 || run.FilesUpdated != 2 || run.SpansSynced != 7 {
+		t.Errorf("LastRun = %+v, want the second run", run)
+	}
+	if len(run.Errors) != 2 || run.Errors[1] != "b: worse" {
+		t.Errorf("Errors = %v, want 2 errors", run.Errors)
+	}
+}
+
+func TestSyncErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	errs, err := db.GetErrors(0)
+	if err != nil {
+		t.Fatalf("GetErrors failed: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("Expected no errors, got %d", len(errs))
+	}
+
+	db.RecordError("/a.jsonl", "parse failed")
+	db.RecordError("/b.jsonl", "backend unavailable")
+	db.RecordError("/c.jsonl", "permission denied")
+
+	errs, err = db.GetErrors(0)
+	if err != nil {
+		t.Fatalf("GetErrors failed: %v", err)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 errors, got %d", len(errs))
+	}
+	if errs[0].FilePath != "/c.jsonl" || errs[0].Message != "permission denied" {
+		t.Errorf("errs[0] = %+v, want the newest error first", errs[0])
+	}
+	if errs[2].OccurredAt == 0 {
+		t.Error("OccurredAt should be set")
+	}
+
+	errs, err = db.GetErrors(2)
+	if err != nil {
+		t.Fatalf("GetErrors failed: %v", err)
+	}
+	if len(errs) != 2 || errs[1].FilePath != "/b.jsonl" {
+		t.Errorf("GetErrors(2) = %d errors, want the 2 newest", len(errs))
+	}
+
+	n, err := db.ClearErrors()
+	if err != nil {
+		t.Fatalf("ClearErrors failed: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("ClearErrors removed %d, want 3", n)
+	}
+	errs, _ = db.GetErrors(0)
+	if len(errs) != 0 {
+		t.Errorf("Expected no errors after clear, got %d", len(errs))
+	}
+}
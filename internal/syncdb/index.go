@@ -0,0 +1,154 @@
+package syncdb
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// IndexedFile is the search index state of a single conversation file.
+// Size and Mtime are the file's values when it was indexed, used to detect
+// changes the same way sync does.
+type IndexedFile struct {
+	FilePath     string
+	Size         int64
+	Mtime        int64
+	MessageCount int
+	IndexedAt    int64
+}
+
+// IndexStats holds search index statistics.
+type IndexStats struct {
+	Files         int
+	Messages      int
+	LastIndexedAt int64 // Unix time of the most recently indexed file (0 = empty index)
+}
+
+// IndexFile replaces the indexed text of a file. All message text of the file
+// is stored as one document, so a match identifies the file, not the message.
+func (d *DB) IndexFile(file *IndexedFile, text string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRow("SELECT id FROM search_index_files WHERE file_path = ?", file.FilePath).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		res, err := tx.Exec(`
+			INSERT INTO search_index_files (file_path, size, mtime, message_count, indexed_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, file.FilePath, file.Size, file.Mtime, file.MessageCount, file.IndexedAt)
+		if err != nil {
+			return err
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		if _, err := tx.Exec(`
+			UPDATE search_index_files
+			SET size = ?, mtime = ?, message_count = ?, indexed_at = ?
+			WHERE id = ?
+		`, file.Size, file.Mtime, file.MessageCount, file.IndexedAt, id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM search_index WHERE rowid = ?", id); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("INSERT INTO search_index (rowid, content) VALUES (?, ?)", id, text); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemoveIndexedFile drops a file from the search index.
+func (d *DB) RemoveIndexedFile(filePath string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		DELETE FROM search_index
+		WHERE rowid = (SELECT id FROM search_index_files WHERE file_path = ?)
+	`, filePath); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM search_index_files WHERE file_path = ?", filePath); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// IndexedFiles returns the index state of every indexed file, keyed by path.
+func (d *DB) IndexedFiles() (map[string]*IndexedFile, error) {
+	rows, err := d.db.Query(`
+		SELECT file_path, size, mtime, message_count, indexed_at
+		FROM search_index_files
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := make(map[string]*IndexedFile)
+	for rows.Next() {
+		var f IndexedFile
+		if err := rows.Scan(&f.FilePath, &f.Size, &f.Mtime, &f.MessageCount, &f.IndexedAt); err != nil {
+			return nil, err
+		}
+		files[f.FilePath] = &f
+	}
+	return files, rows.Err()
+}
+
+// MatchIndex returns the indexed files whose text contains query, ignoring
+// case. The trigram index only matches queries of at least three characters;
+// shorter queries match nothing.
+func (d *DB) MatchIndex(query string) (map[string]bool, error) {
+	rows, err := d.db.Query(`
+		SELECT f.file_path FROM search_index s
+		JOIN search_index_files f ON f.id = s.rowid
+		WHERE search_index MATCH ?
+	`, `"`+strings.ReplaceAll(query, `"`, `""`)+`"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make(map[string]bool)
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		matches[path] = true
+	}
+	return matches, rows.Err()
+}
+
+// IndexStats returns search index statistics.
+func (d *DB) IndexStats() (*IndexStats, error) {
+	var stats IndexStats
+	row := d.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(message_count), 0), COALESCE(MAX(indexed_at), 0)
+		FROM search_index_files
+	`)
+	if err := row.Scan(&stats.Files, &stats.Messages, &stats.LastIndexedAt); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
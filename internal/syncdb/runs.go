@@ -0,0 +1,73 @@
+package syncdb
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// RunSummary records the outcome of a single full sync run.
+type RunSummary struct {
+	StartedAt    int64
+	FinishedAt   int64
+	FilesScanned int
+	FilesUpdated int
+	SpansSynced  int
+	Errors       []string
+	Backend      string
+}
+
+// RecordRun saves the summary of a completed sync run.
+func (d *DB) RecordRun(run *RunSummary) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.db.Exec(`
+		INSERT INTO sync_runs
+		(started_at, finished_at, files_scanned, files_updated, spans_synced,
+		 error_count, errors, backend)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		run.StartedAt,
+		run.FinishedAt,
+		run.FilesScanned,
+		run.FilesUpdated,
+		run.SpansSynced,
+		len(run.Errors),
+		strings.Join(run.Errors, "\n"),
+		run.Backend,
+	)
+	return err
+}
+
+// LastRun returns the most recent sync run, or nil if none has been recorded.
+func (d *DB) LastRun() (*RunSummary, error) {
+	row := d.db.QueryRow(`
+		SELECT started_at, finished_at, files_scanned, files_updated,
+			   spans_synced, errors, backend
+		FROM sync_runs
+		ORDER BY id DESC
+		LIMIT 1
+	`)
+
+	var run RunSummary
+	var errors sql.NullString
+	err := row.Scan(
+		&run.StartedAt,
+		&run.FinishedAt,
+		&run.FilesScanned,
+		&run.FilesUpdated,
+		&run.SpansSynced,
+		&errors,
+		&run.Backend,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil // No runs yet
+	}
+	if err != nil {
+		return nil, err
+	}
+	if errors.Valid && errors.String != "" {
+		run.Errors = strings.Split(errors.String, "\n")
+	}
+	return &run, nil
+}
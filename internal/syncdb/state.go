@@ -15,19 +15,20 @@ type SyncState struct {
 	MessageCount int
 	LastSyncAt   int64
 	Backend      string
+	ContentHash  string // Hash of the file content, when checksum change detection is used
 }
 
 // GetState retrieves the sync state for a file.
 func (d *DB) GetState(filePath string) (*SyncState, error) {
 	row := d.db.QueryRow(`
 		SELECT file_path, last_offset, last_size, last_mtime,
-			   trace_id, message_count, last_sync_at, backend
+			   trace_id, message_count, last_sync_at, backend, content_hash
 		FROM sync_state
 		WHERE file_path = ?
 	`, filePath)
 
 	var state SyncState
-	var traceID sql.NullString
+	var traceID, contentHash sql.NullString
 	err := row.Scan(
 		&state.FilePath,
 		&state.LastOffset,
@@ -37,6 +38,7 @@ func (d *DB) GetState(filePath string) (*SyncState, error) {
 		&state.MessageCount,
 		&state.LastSyncAt,
 		&state.Backend,
+		&contentHash,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil // No state yet
@@ -47,6 +49,7 @@ func (d *DB) GetState(filePath string) (*SyncState, error) {
 	if traceID.Valid {
 		state.TraceID = traceID.String
 	}
+	state.ContentHash = contentHash.String
 	return &state, nil
 }
 
@@ -58,8 +61,8 @@ func (d *DB) SaveState(state *SyncState) error {
 	_, err := d.db.Exec(`
 		INSERT OR REPLACE INTO sync_state
 		(file_path, last_offset, last_size, last_mtime, trace_id,
-		 message_count, last_sync_at, backend)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		 message_count, last_sync_at, backend, content_hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		state.FilePath,
 		state.LastOffset,
@@ -69,6 +72,7 @@ func (d *DB) SaveState(state *SyncState) error {
 		state.MessageCount,
 		state.LastSyncAt,
 		state.Backend,
+		state.ContentHash,
 	)
 	return err
 }
@@ -126,11 +130,62 @@ func (d *DB) RecordError(filePath, errorMsg string) error {
 	return err
 }
 
+// SyncError is a recorded failure to sync a single file.
+type SyncError struct {
+	ID         int64
+	FilePath   string
+	Message    string
+	OccurredAt int64
+}
+
+// GetErrors returns recorded sync errors, newest first. A limit of 0 returns
+// all of them.
+func (d *DB) GetErrors(limit int) ([]*SyncError, error) {
+	query := `
+		SELECT id, file_path, error_message, occurred_at
+		FROM sync_errors
+		ORDER BY occurred_at DESC, id DESC
+	`
+	var args []any
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var errs []*SyncError
+	for rows.Next() {
+		var e SyncError
+		if err := rows.Scan(&e.ID, &e.FilePath, &e.Message, &e.OccurredAt); err != nil {
+			return nil, err
+		}
+		errs = append(errs, &e)
+	}
+	return errs, rows.Err()
+}
+
+// ClearErrors deletes all recorded sync errors and returns how many were removed.
+func (d *DB) ClearErrors() (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	res, err := d.db.Exec("DELETE FROM sync_errors")
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 // GetAllStates returns all sync states.
 func (d *DB) GetAllStates() ([]*SyncState, error) {
 	rows, err := d.db.Query(`
 		SELECT file_path, last_offset, last_size, last_mtime,
-			   trace_id, message_count, last_sync_at, backend
+			   trace_id, message_count, last_sync_at, backend, content_hash
 		FROM sync_state
 	`)
 	if err != nil {
@@ -141,7 +196,7 @@ func (d *DB) GetAllStates() ([]*SyncState, error) {
 	var states []*SyncState
 	for rows.Next() {
 		var state SyncState
-		var traceID sql.NullString
+		var traceID, contentHash sql.NullString
 		err := rows.Scan(
 			&state.FilePath,
 			&state.LastOffset,
@@ -151,6 +206,7 @@ func (d *DB) GetAllStates() ([]*SyncState, error) {
 			&state.MessageCount,
 			&state.LastSyncAt,
 			&state.Backend,
+			&contentHash,
 		)
 		if err != nil {
 			return nil, err
@@ -158,6 +214,7 @@ func (d *DB) GetAllStates() ([]*SyncState, error) {
 		if traceID.Valid {
 			state.TraceID = traceID.String
 		}
+		state.ContentHash = contentHash.String
 		states = append(states, &state)
 	}
 	return states, rows.Err()
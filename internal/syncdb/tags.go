@@ -0,0 +1,103 @@
+package syncdb
+
+import (
+	"time"
+)
+
+// TagCount is a tag with the number of conversations carrying it.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// AddTag labels a conversation with a tag. Adding an existing tag is a no-op.
+func (d *DB) AddTag(conversationID, tag string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.db.Exec(`
+		INSERT OR IGNORE INTO conversation_tags (conversation_id, tag, created_at)
+		VALUES (?, ?, ?)
+	`, conversationID, tag, time.Now().Unix())
+	return err
+}
+
+// RemoveTag removes a tag from a conversation.
+func (d *DB) RemoveTag(conversationID, tag string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.db.Exec(`
+		DELETE FROM conversation_tags
+		WHERE conversation_id = ? AND tag = ?
+	`, conversationID, tag)
+	return err
+}
+
+// GetTags returns the tags for a conversation, sorted by name.
+func (d *DB) GetTags(conversationID string) ([]string, error) {
+	rows, err := d.db.Query(`
+		SELECT tag FROM conversation_tags
+		WHERE conversation_id = ?
+		ORDER BY tag
+	`, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// AllTags returns the tags of every tagged conversation, keyed by conversation ID.
+func (d *DB) AllTags() (map[string][]string, error) {
+	rows, err := d.db.Query(`
+		SELECT conversation_id, tag FROM conversation_tags
+		ORDER BY conversation_id, tag
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[string][]string)
+	for rows.Next() {
+		var id, tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	return tags, rows.Err()
+}
+
+// TagCounts returns all tags with their conversation counts, sorted by name.
+func (d *DB) TagCounts() ([]TagCount, error) {
+	rows, err := d.db.Query(`
+		SELECT tag, COUNT(*) FROM conversation_tags
+		GROUP BY tag
+		ORDER BY tag
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []TagCount
+	for rows.Next() {
+		var tc TagCount
+		if err := rows.Scan(&tc.Tag, &tc.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, tc)
+	}
+	return counts, rows.Err()
+}
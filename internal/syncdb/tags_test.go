@@ -0,0 +1,70 @@
+package syncdb
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	// Add tags (duplicates are ignored)
+	for _, tag := range []string{"spike", "bug", "bug"} {
+		if err := db.AddTag("conv-1", tag); err != nil {
+			t.Fatalf("AddTag failed: %v", err)
+		}
+	}
+	if err := db.AddTag("conv-2", "bug"); err != nil {
+		t.Fatalf("AddTag failed: %v", err)
+	}
+
+	tags, err := db.GetTags("conv-1")
+	if err != nil {
+		t.Fatalf("GetTags failed: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != "bug" || tags[1] != "spike" {
+		t.Errorf("GetTags = %v, want [bug spike]", tags)
+	}
+
+	all, err := db.AllTags()
+	if err != nil {
+		t.Fatalf("AllTags failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("AllTags returned %d conversations, want 2", len(all))
+	}
+
+	counts, err := db.TagCounts()
+	if err != nil {
+		t.Fatalf("TagCounts failed: %v", err)
+	}
+	if len(counts) != 2 || counts[0].Tag != "bug" || counts[0].Count != 2 {
+		t.Errorf("TagCounts = %v, want bug=2 first", counts)
+	}
+
+	// Remove tag
+	if err := db.RemoveTag("conv-1", "spike"); err != nil {
+		t.Fatalf("RemoveTag failed: %v", err)
+	}
+	tags, err = db.GetTags("conv-1")
+	if err != nil {
+		t.Fatalf("GetTags failed: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "bug" {
+		t.Errorf("GetTags after remove = %v, want [bug]", tags)
+	}
+
+	// Untagged conversation
+	tags, err = db.GetTags("conv-3")
+	if err != nil {
+		t.Fatalf("GetTags failed: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("GetTags for untagged conversation = %v, want empty", tags)
+	}
+}
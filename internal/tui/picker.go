@@ -0,0 +1,174 @@
+// Package tui provides interactive terminal helpers for browsing conversations.
+// It uses a minimal line-based loop (no raw terminal mode) so it works in any
+// interactive shell without extra dependencies.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dmora/ch/internal/display"
+	"github.com/dmora/ch/internal/history"
+	"github.com/mattn/go-isatty"
+)
+
+// DefaultPageSize is the number of matches shown per prompt.
+const DefaultPageSize = 20
+
+// ErrAborted is returned when the user quits without selecting anything.
+var ErrAborted = errors.New("selection aborted")
+
+// IsInteractive reports whether both stdin and stdout are terminals.
+func IsInteractive() bool {
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// Picker lets the user narrow a list of conversations with a fuzzy filter
+// and select one by number.
+type Picker struct {
+	in       *bufio.Reader
+	out      io.Writer
+	items    []*history.ConversationMeta
+	PageSize int
+}
+
+// NewPicker creates a picker over items, reading input from in and drawing to out.
+func NewPicker(in io.Reader, out io.Writer, items []*history.ConversationMeta) *Picker {
+	return &Picker{
+		in:       bufio.NewReader(in),
+		out:      out,
+		items:    items,
+		PageSize: DefaultPageSize,
+	}
+}
+
+// Run shows the picker until a conversation is selected.
+// Returns ErrAborted if the user quits or input ends.
+func (p *Picker) Run() (*history.ConversationMeta, error) {
+	query := ""
+	for {
+		matches := Filter(p.items, query)
+		p.render(matches, query)
+
+		line, err := p.in.ReadString('\n')
+		if err != nil {
+			if err != io.EOF {
+				return nil, fmt.Errorf("reading input: %w", err)
+			}
+			if line == "" {
+				return nil, ErrAborted
+			}
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "q":
+			return nil, ErrAborted
+		case line == "":
+			query = ""
+		default:
+			if n, err := strconv.Atoi(line); err == nil {
+				if n >= 1 && n <= p.visible(matches) {
+					return matches[n-1], nil
+				}
+				fmt.Fprintln(p.out, display.Warning(fmt.Sprintf("No match numbered %d", n)))
+				continue
+			}
+			query = line
+		}
+	}
+}
+
+// visible returns how many matches are shown on screen.
+func (p *Picker) visible(matches []*history.ConversationMeta) int {
+	if p.PageSize > 0 && len(matches) > p.PageSize {
+		return p.PageSize
+	}
+	return len(matches)
+}
+
+// render draws the current matches and the prompt.
+func (p *Picker) render(matches []*history.ConversationMeta, query string) {
+	fmt.Fprintln(p.out)
+	if query != "" {
+		fmt.Fprintf(p.out, "%s %s\n", display.Dim("Filter:"), display.Match(query))
+	}
+
+	if len(matches) == 0 {
+		fmt.Fprintln(p.out, display.Dim("No conversations match"))
+	}
+	for i, m := range matches[:p.visible(matches)] {
+		id := history.ShortID(m.ID)
+		if m.IsAgent {
+			id = "agent-" + id
+		}
+		fmt.Fprintf(p.out, "%s %s  %s  %s\n",
+			display.Number(fmt.Sprintf("%3d", i+1)),
+			display.ID(id),
+			display.Dim(display.FormatTime(m.Timestamp, display.TimeFormatRelative)),
+			truncate(m.Preview, 60),
+		)
+	}
+	if hidden := len(matches) - p.visible(matches); hidden > 0 {
+		fmt.Fprintln(p.out, display.Dim(fmt.Sprintf("... %d more; refine the filter", hidden)))
+	}
+
+	fmt.Fprintf(p.out, "\n%s ", display.Dim("Type to filter, a number to select, empty to clear, q to quit >"))
+}
+
+// Filter returns the items that fuzzy-match query, preserving order.
+// An empty query matches everything.
+func Filter(items []*history.ConversationMeta, query string) []*history.ConversationMeta {
+	if query == "" {
+		return items
+	}
+	var matches []*history.ConversationMeta
+	for _, m := range items {
+		if Match(query, searchText(m)) {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// Match reports whether the characters of query appear in text in order
+// (case-insensitive), ignoring spaces in the query.
+func Match(query, text string) bool {
+	text = strings.ToLower(text)
+	pos := 0
+	for _, r := range strings.ToLower(query) {
+		if r == ' ' {
+			continue
+		}
+		i := strings.IndexRune(text[pos:], r)
+		if i < 0 {
+			return false
+		}
+		pos += i + len(string(r))
+	}
+	return true
+}
+
+// searchText is the text a conversation is matched against.
+func searchText(m *history.ConversationMeta) string {
+	return strings.Join([]string{m.ID, m.ProjectPath, m.Model, m.Preview}, " ")
+}
+
+// truncate shortens s to maxLen runes on a single line.
+func truncate(s string, maxLen int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dmora/ch/internal/history"
+)
+
+func testItems() []*history.ConversationMeta {
+	return []*history.ConversationMeta{
+		{ID: "aaaa1111-0000-0000-0000-000000000000", ProjectPath: "/home/me/api", Preview: "Fix the docker build"},
+		{ID: "bbbb2222-0000-0000-0000-000000000000", ProjectPath: "/home/me/web", Preview: "Add dark mode toggle"},
+		{ID: "cccc3333-0000-0000-0000-000000000000", ProjectPath: "/home/me/api", Preview: "Write migration for users"},
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query, text string
+		want        bool
+	}{
+		{"dkr", "Fix the docker build", true},
+		{"DOCKER", "Fix the docker build", true},
+		{"fix docker", "Fix the docker build", true},
+		{"rekcod", "Fix the docker build", false},
+		{"", "anything", true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.query, tt.text); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.query, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	items := testItems()
+
+	if got := Filter(items, ""); len(got) != 3 {
+		t.Errorf("Filter(\"\") returned %d items, want 3", len(got))
+	}
+
+	got := Filter(items, "api")
+	if len(got) != 2 || got[0] != items[0] || got[1] != items[2] {
+		t.Errorf("Filter(api) = %v, want items 0 and 2 in order", got)
+	}
+}
+
+func TestPickerRun(t *testing.T) {
+	t.Run("filter then select", func(t *testing.T) {
+		var out bytes.Buffer
+		p := NewPicker(strings.NewReader("dark\n1\n"), &out, testItems())
+
+		selected, err := p.Run()
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if selected.ID != "bbbb2222-0000-0000-0000-000000000000" {
+			t.Errorf("selected = %s, want bbbb2222...", selected.ID)
+		}
+	})
+
+	t.Run("out of range number", func(t *testing.T) {
+		var out bytes.Buffer
+		p := NewPicker(strings.NewReader("9\n3\n"), &out, testItems())
+
+		selected, err := p.Run()
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if selected.ID != "cccc3333-0000-0000-0000-000000000000" {
+			t.Errorf("selected = %s, want cccc3333...", selected.ID)
+		}
+		if !strings.Contains(out.String(), "No match numbered 9") {
+			t.Errorf("expected out-of-range warning, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("quit", func(t *testing.T) {
+		p := NewPicker(strings.NewReader("q\n"), &bytes.Buffer{}, testItems())
+		if _, err := p.Run(); !errors.Is(err, ErrAborted) {
+			t.Errorf("Run() error = %v, want ErrAborted", err)
+		}
+	})
+
+	t.Run("eof", func(t *testing.T) {
+		p := NewPicker(strings.NewReader(""), &bytes.Buffer{}, testItems())
+		if _, err := p.Run(); !errors.Is(err, ErrAborted) {
+			t.Errorf("Run() error = %v, want ErrAborted", err)
+		}
+	})
+}